@@ -0,0 +1,222 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// TypeClusterResolved indicates whether the Kafka cluster referenced by a
+// KafkaBench could be resolved.
+const TypeClusterResolved xpv1.ConditionType = "ClusterResolved"
+
+// Reasons a Kafka cluster is or is not resolved.
+const (
+	ReasonClusterResolved   xpv1.ConditionReason = "Resolved"
+	ReasonResolutionFailure xpv1.ConditionReason = "ResolutionFailure"
+)
+
+// ClusterResolved returns a condition that indicates the referenced Kafka
+// cluster was resolved.
+func ClusterResolved() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeClusterResolved,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonClusterResolved,
+	}
+}
+
+// ClusterResolutionFailed returns a condition that indicates the referenced
+// Kafka cluster could not be resolved.
+func ClusterResolutionFailed(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeClusterResolved,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonResolutionFailure,
+		Message:            msg,
+	}
+}
+
+// ReasonQueued indicates a KafkaBench is waiting for the agent pool to have
+// capacity for it.
+const ReasonQueued xpv1.ConditionReason = "Queued"
+
+// Queued returns a condition that indicates the KafkaBench is waiting in the
+// queue of the agent pool at the supplied position.
+func Queued(position int32) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               xpv1.TypeReady,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonQueued,
+		Message:            fmt.Sprintf("waiting for the agent pool at position %d", position),
+	}
+}
+
+// ReasonGuardrailsViolated indicates a KafkaBench exceeds the guardrails of
+// its ProviderConfig or KafkaTarget.
+const ReasonGuardrailsViolated xpv1.ConditionReason = "GuardrailsViolated"
+
+// GuardrailsViolated returns a condition that indicates the KafkaBench was not
+// dispatched because it exceeds its guardrails.
+func GuardrailsViolated(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               xpv1.TypeReady,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonGuardrailsViolated,
+		Message:            msg,
+	}
+}
+
+// ReasonTimedOut indicates the task of a KafkaBench was stopped by its
+// watchdog.
+const ReasonTimedOut xpv1.ConditionReason = "TimedOut"
+
+// TimedOut returns a condition that indicates the task of the KafkaBench was
+// stopped because it did not finish in time or stopped making progress.
+func TimedOut(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               xpv1.TypeReady,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonTimedOut,
+		Message:            msg,
+	}
+}
+
+// ReasonWorkerFailed indicates the worker of the task of a KafkaBench reported
+// an error.
+const ReasonWorkerFailed xpv1.ConditionReason = "WorkerFailed"
+
+// WorkerFailed returns a condition that indicates the worker of the task of
+// the KafkaBench reported an error.
+func WorkerFailed(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               xpv1.TypeReady,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonWorkerFailed,
+		Message:            msg,
+	}
+}
+
+// ReasonGroupTooLarge indicates a KafkaBench belongs to a group with more
+// members than the agent pool may run at once.
+const ReasonGroupTooLarge xpv1.ConditionReason = "GroupTooLarge"
+
+// GroupTooLarge returns a condition that indicates the KafkaBench will not be
+// dispatched because the supplied group has more members than the agent pool
+// may run at once.
+func GroupTooLarge(group string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               xpv1.TypeReady,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonGroupTooLarge,
+		Message:            fmt.Sprintf("Group %s has more benches than the concurrency limits of the agent pool", group),
+	}
+}
+
+// ReasonWaitingForGroup indicates a KafkaBench was validated and placed in
+// the agent pool, and waits for the other members of its group.
+const ReasonWaitingForGroup xpv1.ConditionReason = "WaitingForGroup"
+
+// WaitingForGroup returns a condition that indicates the KafkaBench waits for
+// its group to agree on the start of its members.
+func WaitingForGroup(group string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               xpv1.TypeReady,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonWaitingForGroup,
+		Message:            fmt.Sprintf("waiting for the other benches of group %s", group),
+	}
+}
+
+// ReasonScheduled indicates a KafkaBench waits for its startAt or its start
+// window.
+const ReasonScheduled xpv1.ConditionReason = "Scheduled"
+
+// Scheduled returns a condition that indicates the KafkaBench starts at the
+// supplied time.
+func Scheduled(start time.Time) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               xpv1.TypeReady,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonScheduled,
+		Message:            fmt.Sprintf("scheduled to start at %s", start.UTC().Format(time.RFC3339)),
+	}
+}
+
+// ReasonStartWindowMissed indicates a KafkaBench was not dispatched before its
+// start window closed.
+const ReasonStartWindowMissed xpv1.ConditionReason = "StartWindowMissed"
+
+// StartWindowMissed returns a condition that indicates the KafkaBench will not
+// start because its start window closed at the supplied time.
+func StartWindowMissed(closed time.Time) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               xpv1.TypeReady,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonStartWindowMissed,
+		Message:            fmt.Sprintf("not started before its start window closed at %s", closed.UTC().Format(time.RFC3339)),
+	}
+}
+
+// TypePassed indicates whether the assertions of a KafkaBench held once it
+// was done.
+const TypePassed xpv1.ConditionType = "Passed"
+
+// Reasons the assertions of a KafkaBench did or did not hold.
+const (
+	ReasonAssertionsPassed xpv1.ConditionReason = "AssertionsPassed"
+	ReasonAssertionsFailed xpv1.ConditionReason = "AssertionsFailed"
+)
+
+// AssertionsPassed returns a condition that indicates every assertion of the
+// KafkaBench held.
+func AssertionsPassed() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypePassed,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonAssertionsPassed,
+	}
+}
+
+// AssertionsFailed returns a condition that indicates an assertion of the
+// KafkaBench did not hold, or could not be evaluated.
+func AssertionsFailed(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypePassed,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonAssertionsFailed,
+		Message:            msg,
+	}
+}
@@ -19,12 +19,24 @@ package v1alpha1
 import (
 	"reflect"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 )
 
+// Trogdor task classes with dedicated KafkaBench fields. Any other class can
+// be run through a rawSpec.
+const (
+	ProduceBenchClass      = "org.apache.kafka.trogdor.workload.ProduceBenchSpec"
+	ConsumeBenchClass      = "org.apache.kafka.trogdor.workload.ConsumeBenchSpec"
+	RoundTripWorkloadClass = "org.apache.kafka.trogdor.workload.RoundTripWorkloadSpec"
+	ConnectionStressClass  = "org.apache.kafka.trogdor.workload.ConnectionStressSpec"
+	ExternalCommandClass   = "org.apache.kafka.trogdor.workload.ExternalCommandSpec"
+)
+
 // KafkaBenchObservation are the observable fields of a KafkaBench.
 type KafkaBenchObservation struct {
 	TaskStatus     string                              `json:"taskStatus,omitempty"`
@@ -33,17 +45,433 @@ type KafkaBenchObservation struct {
 	ProducerStats  ProducerBenchResultStats            `json:"producerStats,omitempty"`
 	ConsumerStats  map[string]ConsumerBenchResultStats `json:"consumerStats,omitempty"`
 	RoundTripStats RoundTripBenchResultStats           `json:"roundTripStats,omitempty"`
+	// ConsumerAssignment reports whether a consumer bench subscribed to its
+	// topics through a consumer group or had its partitions assigned manually.
+	ConsumerAssignment string `json:"consumerAssignment,omitempty"`
+	// RawStatus is the worker status reported by Trogdor for benches
+	// defined through a rawSpec. It is schemaless since Trogdor also reports
+	// progress as plain strings.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	RawStatus *runtime.RawExtension `json:"rawStatus,omitempty"`
+	// CommandStatus is the outcome of an ExternalCommandSpec workload.
+	CommandStatus *ExternalCommandStatus `json:"commandStatus,omitempty"`
+	// EffectiveSpec is the spec that was sent to Trogdor, once the bench
+	// defaults of its ProviderConfig have been applied.
+	EffectiveSpec *KafkaBenchParameters `json:"effectiveSpec,omitempty"`
+	// QueuePosition is the position of a QUEUED bench in the queue of the
+	// agent pool, starting at 1.
+	QueuePosition int32 `json:"queuePosition,omitempty"`
+	// ScheduledTime is when a SCHEDULED bench starts.
+	ScheduledTime *metav1.Time `json:"scheduledTime,omitempty"`
+	// StartTime is when the task of the bench was created.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// LastProgressTime is when the status reported by Trogdor last changed.
+	LastProgressTime *metav1.Time `json:"lastProgressTime,omitempty"`
+	// CompletionTime is when Trogdor reported the task of the bench done.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Error is the error the worker of the bench reported, if any. The task
+	// status of such a bench is FAILED.
+	Error string `json:"error,omitempty"`
+	// Segments are the results of the segments of a bench with a load
+	// profile. The producerStats of such a bench are those of its current
+	// segment until every segment is done, then add up those of all of them.
+	Segments []LoadSegment `json:"segments,omitempty"`
+	// CurrentSegment is the index of the segment that runs.
+	CurrentSegment int32 `json:"currentSegment,omitempty"`
+	// Runs are the results of the runs of a bench with repetitions. The
+	// stats of such a bench are those of its current run.
+	Runs []BenchRun `json:"runs,omitempty"`
+	// CurrentRun is the index of the run that runs.
+	CurrentRun int32 `json:"currentRun,omitempty"`
+	// Summary sums up the runs of a bench with repetitions that are done,
+	// once its last run is.
+	Summary *RunSummary `json:"summary,omitempty"`
+	// Warmup is the result of the warm-up of the bench. The stats, start
+	// and completion of a bench with a warm-up are those of its steady
+	// state, once the warm-up is done.
+	Warmup *WarmupStatus `json:"warmup,omitempty"`
+	// Assertions are the outcomes of the assertions of the bench.
+	Assertions []AssertionResult `json:"assertions,omitempty"`
+}
+
+// An AssertionResult is the outcome of an assertion of a bench.
+type AssertionResult struct {
+	Expression string `json:"expression"`
+	Passed     bool   `json:"passed"`
+	// Message gives the values of the metrics of the assertion, or why it
+	// could not be evaluated.
+	Message string `json:"message,omitempty"`
+}
+
+// A WarmupStatus is the result of the warm-up of a bench.
+type WarmupStatus struct {
+	WorkerID       int64                    `json:"workerId,omitempty"`
+	TaskStatus     string                   `json:"taskStatus,omitempty"`
+	StartTime      *metav1.Time             `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time             `json:"completionTime,omitempty"`
+	MessagesPerSec int64                    `json:"messagesPerSec,omitempty"`
+	ProducerStats  ProducerBenchResultStats `json:"producerStats,omitempty"`
+}
+
+// ExternalCommandStatus is the outcome of the command run by an
+// ExternalCommandSpec workload.
+type ExternalCommandStatus struct {
+	// LastStatus is the last status reported by the command on its standard
+	// output.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	LastStatus *runtime.RawExtension `json:"lastStatus,omitempty"`
+	// ExitCode of the command, once it has exited.
+	ExitCode *int32 `json:"exitCode,omitempty"`
 }
 
+// Consumer assignment modes of a ConsumeBenchSpec workload.
+const (
+	ConsumerGroupSubscription = "GroupSubscription"
+	ConsumerManualAssignment  = "ManualAssignment"
+)
+
 // KafkaTopics are part of the desired state fields
 type KafkaTopics struct {
 	NumPartitions     int16 `json:"numPartitions,omitempty"`
 	ReplicationFactor int8  `json:"replicationFactor,omitempty"`
 }
 
+// A ConsumerTopic is a topic expression consumed by a ConsumeBenchSpec. Topic
+// names accept Trogdor ranges such as "test[1-5]", and may be followed by a
+// partition or partition range such as "test[1-5]:[0-3]". Naming partitions
+// makes the consumers assign them manually instead of subscribing through the
+// consumer group.
+// +kubebuilder:validation:Pattern=`^[^:\s]+(:(\d+|\[\d+-\d+\]))?$`
+type ConsumerTopic string
+
 // A KafkaBenchSpec defines the desired state of a KafkaBench.
 type KafkaBenchSpec struct {
-	xpv1.ResourceSpec       `json:",inline"`
+	xpv1.ResourceSpec    `json:",inline"`
+	KafkaBenchParameters `json:",inline"`
+	// SecretRef references Kafka credentials for this bench, in the same
+	// format as the credentials of a ProviderConfig. They are merged into the
+	// commonClientConf sent to Trogdor, over those of the ProviderConfig, and
+	// are never written to the spec or status of the bench.
+	// +optional
+	SecretRef *xpv1.SecretKeySelector `json:"secretRef,omitempty"`
+	// TLS configures the Kafka clients of this bench with the certificates
+	// of a Secret. They are sent to Trogdor as inline PEM configurations,
+	// and are read again whenever a task is created.
+	// +optional
+	TLS *KafkaBenchTLS `json:"tls,omitempty"`
+	// KafkaClusterRef resolves the bootstrap servers, CA and user
+	// credentials of the bench from a Kafka cluster managed by Strimzi or
+	// Confluent for Kubernetes. The bootstrap servers of the bench, its
+	// secretRef and its tls take precedence over the resolved ones.
+	// +optional
+	KafkaClusterRef *KafkaClusterReference `json:"kafkaClusterRef,omitempty"`
+	// TargetRef references a KafkaTarget holding the bootstrap servers,
+	// client configurations and credentials of the bench. Fields set by the
+	// bench take precedence, and client configurations are merged key by
+	// key.
+	// +optional
+	TargetRef *xpv1.Reference `json:"targetRef,omitempty"`
+	// Watchdog stops the task of the bench when it does not finish in time
+	// or stops making progress.
+	// +optional
+	Watchdog *KafkaBenchWatchdog `json:"watchdog,omitempty"`
+	// Priority of the bench in the queue of the agent pool. When the pool
+	// is at its limit of concurrent benches, queued benches start by
+	// decreasing priority, then in creation order.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+	// LoadProfile varies the targetMessagesPerSec of a produce bench over
+	// its durationMs. The bench is run as a sequence of segments, each a
+	// Trogdor task producing at a constant rate.
+	// +optional
+	LoadProfile *LoadProfile `json:"loadProfile,omitempty"`
+	// StartAt is the earliest time the bench starts at. The bench is
+	// scheduled until then, and its task is dispatched with this start.
+	// +optional
+	StartAt *metav1.Time `json:"startAt,omitempty"`
+	// StartWindow is the daily window the bench may start in. A bench
+	// that is not dispatched before the window it is due in closes, such
+	// as one queued for the agent pool, does not start.
+	// +optional
+	StartWindow *StartWindow `json:"startWindow,omitempty"`
+	// Repetitions is how many times the task of the bench runs, one run
+	// after the other. The status of a bench run more than once sums up
+	// the results of its runs.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Repetitions int32 `json:"repetitions,omitempty"`
+	// CooldownMs is how long a bench with repetitions waits between the
+	// end of a run and the start of the next one.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	CooldownMs int64 `json:"cooldownMs,omitempty"`
+	// Warmup runs a throwaway task before a produce bench, so that topic
+	// creation, metadata fetches and JIT warm-up do not skew its results.
+	// The results of the warm-up are reported apart from those of the
+	// bench.
+	// +optional
+	Warmup *Warmup `json:"warmup,omitempty"`
+	// Assertions are evaluated over the results of the bench once it is
+	// done, such as "producer.p99LatencyMs < 50". Each compares a metric
+	// or a number to another with one of <, <=, >, >=, == and !=. The
+	// Passed condition of the bench tells whether all of them held.
+	// +optional
+	Assertions []string `json:"assertions,omitempty"`
+}
+
+// A Warmup lasts for a duration or a number of messages. Exactly one of them
+// is set. The warm-up produces at the targetMessagesPerSec of the bench, or
+// that of the first segment of its load profile.
+type Warmup struct {
+	// DurationMs of the warm-up.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	DurationMs int64 `json:"durationMs,omitempty"`
+	// Messages produced by the warm-up.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Messages int64 `json:"messages,omitempty"`
+}
+
+// A StartWindow is a daily window of UTC times of day. Windows whose end is
+// before their start span midnight.
+type StartWindow struct {
+	// Start of the window, such as 02:00.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+	// End of the window, such as 04:00.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+}
+
+// Operators managing the Kafka clusters a KafkaBench can reference.
+const (
+	StrimziOperator = "Strimzi"
+	CFKOperator     = "ConfluentForKubernetes"
+)
+
+// A KafkaClusterReference references a Kafka cluster managed by an operator.
+type KafkaClusterReference struct {
+	// Operator managing the cluster. Strimzi clusters are kafka.strimzi.io
+	// Kafka objects, while Confluent for Kubernetes ones are
+	// platform.confluent.io Kafka objects.
+	// +kubebuilder:validation:Enum=Strimzi;ConfluentForKubernetes
+	Operator string `json:"operator"`
+	// Namespace of the Kafka object.
+	Namespace string `json:"namespace"`
+	// Name of the Kafka object.
+	Name string `json:"name"`
+	// Listener the bench connects to.
+	Listener string `json:"listener"`
+	// User the bench authenticates as. For Strimzi this is a KafkaUser
+	// whose Secret holds its credentials, and for Confluent for Kubernetes a
+	// user of the PLAIN users of the listener.
+	// +optional
+	User string `json:"user,omitempty"`
+}
+
+// KafkaBenchTLS references the TLS material of the Kafka clients of a bench.
+type KafkaBenchTLS struct {
+	// SecretRef references the Secret holding the CA bundle and, for mutual
+	// TLS, the client certificate and key.
+	SecretRef xpv1.SecretReference `json:"secretRef"`
+	// CAKey is the key of the CA bundle in the Secret.
+	// +kubebuilder:default=ca.crt
+	// +optional
+	CAKey string `json:"caKey,omitempty"`
+	// CertKey is the key of the client certificate in the Secret. It is
+	// ignored when the Secret holds no such key.
+	// +kubebuilder:default=tls.crt
+	// +optional
+	CertKey string `json:"certKey,omitempty"`
+	// KeyKey is the key of the client private key in the Secret.
+	// +kubebuilder:default=tls.key
+	// +optional
+	KeyKey string `json:"keyKey,omitempty"`
+}
+
+// KafkaBenchWatchdog configures when the task of a bench that never finishes
+// is stopped. A stopped bench keeps its last stats and its task status is
+// TIMED_OUT.
+type KafkaBenchWatchdog struct {
+	// GracePeriodMs is how long past its durationMs a bench may take to be
+	// done.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=300000
+	// +optional
+	GracePeriodMs int64 `json:"gracePeriodMs,omitempty"`
+	// StallTimeoutMs is how long a bench may report the same status before
+	// it is stopped. 0 disables the stall timeout.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	StallTimeoutMs int64 `json:"stallTimeoutMs,omitempty"`
+}
+
+// A LoadProfile describes how the rate of a produce bench varies over its
+// durationMs. Exactly one of ramp, steps, sine and replay must be set.
+type LoadProfile struct {
+	// Ramp varies the rate linearly.
+	// +optional
+	Ramp *RampProfile `json:"ramp,omitempty"`
+	// Steps set the rate from their offset until the next step.
+	// +optional
+	Steps []LoadPoint `json:"steps,omitempty"`
+	// Sine varies the rate along a sine wave.
+	// +optional
+	Sine *SineProfile `json:"sine,omitempty"`
+	// Replay sets the rate from points recorded in a ConfigMap.
+	// +optional
+	Replay *ReplayProfile `json:"replay,omitempty"`
+	// SegmentDurationMs is the duration of the segments a ramp or a sine
+	// wave is run as.
+	// +kubebuilder:validation:Minimum=1000
+	// +kubebuilder:default=60000
+	// +optional
+	SegmentDurationMs int64 `json:"segmentDurationMs,omitempty"`
+}
+
+// A RampProfile varies the rate of a bench linearly over its durationMs.
+type RampProfile struct {
+	// +kubebuilder:validation:Minimum=1
+	FromMessagesPerSec int32 `json:"fromMessagesPerSec"`
+	// +kubebuilder:validation:Minimum=1
+	ToMessagesPerSec int32 `json:"toMessagesPerSec"`
+}
+
+// A LoadPoint sets the rate of a bench from an offset of its durationMs.
+type LoadPoint struct {
+	// +kubebuilder:validation:Minimum=0
+	OffsetMs int64 `json:"offsetMs"`
+	// +kubebuilder:validation:Minimum=1
+	MessagesPerSec int32 `json:"messagesPerSec"`
+}
+
+// A SineProfile varies the rate of a bench along a sine wave.
+type SineProfile struct {
+	// +kubebuilder:validation:Minimum=1
+	MeanMessagesPerSec int32 `json:"meanMessagesPerSec"`
+	// +kubebuilder:validation:Minimum=0
+	AmplitudeMessagesPerSec int32 `json:"amplitudeMessagesPerSec"`
+	// +kubebuilder:validation:Minimum=1
+	PeriodMs int64 `json:"periodMs"`
+}
+
+// A ReplayProfile replays the rates recorded in a ConfigMap key, as CSV lines
+// of an offset in milliseconds and a rate in messages per second. Offsets are
+// relative to the first line, and lines starting with # are ignored.
+type ReplayProfile struct {
+	ConfigMapRef ConfigMapKeySelector `json:"configMapRef"`
+}
+
+// A ConfigMapKeySelector references a key of a ConfigMap.
+type ConfigMapKeySelector struct {
+	Name string `json:"name"`
+	// Namespace of the ConfigMap. The ConfigMap of a NamespacedKafkaBench
+	// is always read from the namespace of the bench.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	Key       string `json:"key"`
+}
+
+// A LoadSegment is the part of a bench with a load profile that runs at a
+// constant rate.
+type LoadSegment struct {
+	OffsetMs             int64                    `json:"offsetMs"`
+	DurationMs           int64                    `json:"durationMs"`
+	TargetMessagesPerSec int32                    `json:"targetMessagesPerSec"`
+	WorkerID             int64                    `json:"workerId,omitempty"`
+	TaskStatus           string                   `json:"taskStatus,omitempty"`
+	StartTime            *metav1.Time             `json:"startTime,omitempty"`
+	CompletionTime       *metav1.Time             `json:"completionTime,omitempty"`
+	MessagesPerSec       int64                    `json:"messagesPerSec,omitempty"`
+	ProducerStats        ProducerBenchResultStats `json:"producerStats,omitempty"`
+}
+
+// A BenchRun is the result of one of the runs of a bench with repetitions.
+// Latencies are those reported by the producer or, for a consume bench, the
+// worst of its consumers.
+type BenchRun struct {
+	WorkerID         int64        `json:"workerId,omitempty"`
+	TaskStatus       string       `json:"taskStatus,omitempty"`
+	StartTime        *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime   *metav1.Time `json:"completionTime,omitempty"`
+	MessagesPerSec   int64        `json:"messagesPerSec,omitempty"`
+	AverageLatencyMs float64      `json:"averageLatencyMs,omitempty"`
+	P50LatencyMs     int64        `json:"p50LatencyMs,omitempty"`
+	P95LatencyMs     int64        `json:"p95LatencyMs,omitempty"`
+	P99LatencyMs     int64        `json:"p99LatencyMs,omitempty"`
+}
+
+// A RunSummary sums up the runs of a bench with repetitions that are done.
+// Latencies are only summed up for workloads that report them.
+type RunSummary struct {
+	// Runs is how many runs are summed up.
+	Runs             int32      `json:"runs"`
+	MessagesPerSec   Statistic  `json:"messagesPerSec"`
+	AverageLatencyMs *Statistic `json:"averageLatencyMs,omitempty"`
+	P50LatencyMs     *Statistic `json:"p50LatencyMs,omitempty"`
+	P95LatencyMs     *Statistic `json:"p95LatencyMs,omitempty"`
+	P99LatencyMs     *Statistic `json:"p99LatencyMs,omitempty"`
+}
+
+// A Statistic describes the values a result took over the runs of a bench.
+type Statistic struct {
+	Mean float64 `json:"mean"`
+	// StdDev is the sample standard deviation of the values.
+	StdDev float64 `json:"stdDev"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	// ConfidenceLow and ConfidenceHigh bound the 95% confidence interval
+	// of the mean, after Student's t-distribution.
+	ConfidenceLow  float64 `json:"confidenceLow"`
+	ConfidenceHigh float64 `json:"confidenceHigh"`
+}
+
+// AnnotationKeyGuardrailsApproved lets a bench exceed the guardrails of its
+// ProviderConfig and KafkaTarget when set to "true". Only users allowed to
+// approve benches may set it.
+const AnnotationKeyGuardrailsApproved = "tarasque.crossplane.io/guardrails-approved"
+
+// AnnotationKeyGroup is set to the name of the KafkaWorkloadGroup a bench
+// belongs to. A bench of a group is validated and placed in the agent pool,
+// then waits for its group to agree on the start of its members.
+const AnnotationKeyGroup = "tarasque.crossplane.io/group"
+
+// AnnotationKeyGroupSize is set to the number of members of the
+// KafkaWorkloadGroup a bench belongs to. The members of a group are placed in
+// the agent pool together, once all of them were created.
+const AnnotationKeyGroupSize = "tarasque.crossplane.io/group-size"
+
+// AnnotationKeyStartMs is set by the group of a bench to the time, in
+// milliseconds since the epoch, its members start at.
+const AnnotationKeyStartMs = "tarasque.crossplane.io/start-ms"
+
+// Guardrails bound the load benches may put on a Kafka cluster. Unset
+// guardrails do not bound anything.
+type Guardrails struct {
+	// MaxMessagesPerSec bounds the targetMessagesPerSec of a bench.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxMessagesPerSec int32 `json:"maxMessagesPerSec,omitempty"`
+	// MaxDurationMs bounds the durationMs of a bench.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxDurationMs int64 `json:"maxDurationMs,omitempty"`
+	// MaxBytes bounds the bytes a bench produces, estimated from its
+	// maxMessages, targetMessagesPerSec and durationMs and the size of the
+	// keys and values Trogdor generates.
+	// +optional
+	MaxBytes *resource.Quantity `json:"maxBytes,omitempty"`
+	// AllowedBootstrapServers lists the bootstrap servers benches may
+	// connect to, as host:port shell patterns such as *.kafka.svc:9092.
+	// +optional
+	AllowedBootstrapServers []string `json:"allowedBootstrapServers,omitempty"`
+}
+
+// KafkaBenchParameters are the Trogdor task parameters of a KafkaBench.
+type KafkaBenchParameters struct {
 	Class                   string                 `json:"class,omitempty"`
 	DurationMs              int64                  `json:"durationMs,omitempty"`
 	ProducerNode            string                 `json:"producerNode,omitempty"`
@@ -56,6 +484,7 @@ type KafkaBenchSpec struct {
 	MaxMessages             int64                  `json:"maxMessages,omitempty"`
 	ActiveTopics            map[string]KafkaTopics `json:"activeTopics,omitempty"`
 	InactiveTopics          map[string]KafkaTopics `json:"inactiveTopics,omitempty"`
+	ConsumerTopics          []ConsumerTopic        `json:"consumerTopics,omitempty"`
 	ProducerConf            map[string]string      `json:"producerConf,omitempty"`
 	ConsumerConf            map[string]string      `json:"consumerConf,omitempty"`
 	CommonClientConf        map[string]string      `json:"commonClientConf,omitempty"`
@@ -63,6 +492,22 @@ type KafkaBenchSpec struct {
 	TargetConnectionsPerSec int32                  `json:"targetConnectionsPerSec,omitempty"`
 	NumThreads              int32                  `json:"numThreads,omitempty"`
 	Action                  string                 `json:"action,omitempty"`
+	// CommandNode, Command, ShutdownGracePeriodMs and Workload configure an
+	// ExternalCommandSpec workload. The workload is written to the command's
+	// standard input, and the command reports its status as JSON lines on
+	// its standard output.
+	CommandNode string `json:"commandNode,omitempty"`
+	// +kubebuilder:validation:MinItems=1
+	Command []string `json:"command,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	ShutdownGracePeriodMs int64 `json:"shutdownGracePeriodMs,omitempty"`
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Workload *runtime.RawExtension `json:"workload,omitempty"`
+	// RawSpec is sent verbatim as the Trogdor worker spec, so that any task
+	// class can be run without dedicated fields. It must set the task class,
+	// while Tarasque takes care of startMs and the task and worker IDs.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	RawSpec *runtime.RawExtension `json:"rawSpec,omitempty"`
 }
 
 // A ProducerBenchResultStats represents the benchmarking results obtained by the agent
@@ -117,6 +562,21 @@ type KafkaBench struct {
 	Status KafkaBenchStatus `json:"status,omitempty"`
 }
 
+// GetBenchSpec of this KafkaBench.
+func (mg *KafkaBench) GetBenchSpec() *KafkaBenchSpec {
+	return &mg.Spec
+}
+
+// GetBenchStatus of this KafkaBench.
+func (mg *KafkaBench) GetBenchStatus() *KafkaBenchStatus {
+	return &mg.Status
+}
+
+// GetTargetReference of this KafkaBench.
+func (mg *KafkaBench) GetTargetReference() *xpv1.Reference {
+	return mg.Spec.TargetRef
+}
+
 // +kubebuilder:object:root=true
 
 // KafkaBenchList contains a list of KafkaBench
@@ -0,0 +1,154 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// Phases of the step of a KafkaBenchScenario, besides BenchSucceeded and
+// BenchFailed once its bench finished.
+const (
+	// StepPending steps wait for their dependencies and their startAfterMs.
+	StepPending = "Pending"
+	// StepRunning steps have a bench that has not finished yet.
+	StepRunning = "Running"
+	// StepSkipped steps never run because one of their dependencies failed.
+	StepSkipped = "Skipped"
+)
+
+// A ScenarioStep is a bench of a scenario and the steps it runs after.
+type ScenarioStep struct {
+	// Name of the step, unique within the scenario.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=40
+	Name string `json:"name"`
+	// DependsOn are the names of the steps that must succeed before this one
+	// starts. Steps without dependencies start with the scenario.
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// StartAfterMs delays the start of the step past the end of its last
+	// dependency, or past the start of the scenario for steps without
+	// dependencies.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	StartAfterMs int64 `json:"startAfterMs,omitempty"`
+	// BenchTemplate describes the bench of the step.
+	BenchTemplate KafkaBenchTemplate `json:"benchTemplate"`
+}
+
+// A KafkaBenchScenarioSpec defines the steps of a scenario.
+type KafkaBenchScenarioSpec struct {
+	// Steps of the scenario. They run as soon as their dependencies
+	// succeeded, side by side when they do not depend on one another.
+	// +kubebuilder:validation:MinItems=1
+	Steps []ScenarioStep `json:"steps"`
+}
+
+// A ScenarioStepStatus reflects the observed state of a step of a scenario.
+type ScenarioStepStatus struct {
+	// Name of the step.
+	Name string `json:"name"`
+	// Bench is the name of the bench of the step, once it started.
+	// +optional
+	Bench string `json:"bench,omitempty"`
+	// Phase is Pending, Running, Succeeded, Failed or Skipped.
+	Phase string `json:"phase"`
+	// CompletionTime is when the bench of the step finished.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// MessagesPerSec is the throughput of the bench, once it finished.
+	// +optional
+	MessagesPerSec int64 `json:"messagesPerSec,omitempty"`
+	// P99LatencyMs is the 99th percentile latency of the bench, once it
+	// finished.
+	// +optional
+	P99LatencyMs int64 `json:"p99LatencyMs,omitempty"`
+}
+
+// A KafkaBenchScenarioStatus reflects the observed state of a
+// KafkaBenchScenario.
+type KafkaBenchScenarioStatus struct {
+	xpv1.ConditionedStatus `json:",inline"`
+	// StartTime is when the scenario started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// Completed is the number of steps that finished or were skipped.
+	// +optional
+	Completed int32 `json:"completed,omitempty"`
+	// Result is Succeeded once every step succeeded, or Failed once every
+	// step finished or was skipped and one of them failed.
+	// +optional
+	Result string `json:"result,omitempty"`
+	// Steps reflect the steps of the scenario, in the order of its spec.
+	// +optional
+	Steps []ScenarioStepStatus `json:"steps,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A KafkaBenchScenario runs the KafkaBenches of its steps in the order of
+// their dependencies, and summarizes their throughput and latency.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="COMPLETED",type="integer",JSONPath=".status.completed"
+// +kubebuilder:printcolumn:name="RESULT",type="string",JSONPath=".status.result"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,template}
+type KafkaBenchScenario struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaBenchScenarioSpec   `json:"spec"`
+	Status KafkaBenchScenarioStatus `json:"status,omitempty"`
+}
+
+// GetCondition of this KafkaBenchScenario.
+func (o *KafkaBenchScenario) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return o.Status.GetCondition(ct)
+}
+
+// SetConditions of this KafkaBenchScenario.
+func (o *KafkaBenchScenario) SetConditions(c ...xpv1.Condition) {
+	o.Status.SetConditions(c...)
+}
+
+// +kubebuilder:object:root=true
+
+// KafkaBenchScenarioList contains a list of KafkaBenchScenario.
+type KafkaBenchScenarioList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaBenchScenario `json:"items"`
+}
+
+// KafkaBenchScenario type metadata.
+var (
+	KafkaBenchScenarioKind             = reflect.TypeOf(KafkaBenchScenario{}).Name()
+	KafkaBenchScenarioGroupKind        = schema.GroupKind{Group: Group, Kind: KafkaBenchScenarioKind}.String()
+	KafkaBenchScenarioKindAPIVersion   = KafkaBenchScenarioKind + "." + SchemeGroupVersion.String()
+	KafkaBenchScenarioGroupVersionKind = SchemeGroupVersion.WithKind(KafkaBenchScenarioKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&KafkaBenchScenario{}, &KafkaBenchScenarioList{})
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// A ConcurrencyPolicy tells what to do when a schedule ticks while the bench
+// of a previous tick still runs.
+// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+type ConcurrencyPolicy string
+
+// Concurrency policies of a KafkaBenchSchedule.
+const (
+	// AllowConcurrent runs the benches of successive ticks side by side.
+	AllowConcurrent ConcurrencyPolicy = "Allow"
+	// ForbidConcurrent skips ticks while the bench of a previous tick runs.
+	ForbidConcurrent ConcurrencyPolicy = "Forbid"
+	// ReplaceConcurrent deletes the running bench of a previous tick.
+	ReplaceConcurrent ConcurrencyPolicy = "Replace"
+)
+
+// Results of a finished KafkaBench.
+const (
+	BenchSucceeded = "Succeeded"
+	BenchFailed    = "Failed"
+)
+
+// KafkaBenchTemplateMeta holds the labels and annotations of the benches
+// created from a template.
+type KafkaBenchTemplateMeta struct {
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// A KafkaBenchTemplate describes the KafkaBenches created by another
+// resource.
+type KafkaBenchTemplate struct {
+	// +optional
+	Metadata KafkaBenchTemplateMeta `json:"metadata,omitempty"`
+	Spec     KafkaBenchSpec         `json:"spec"`
+}
+
+// A KafkaBenchScheduleSpec defines when KafkaBenches are created from a
+// template.
+type KafkaBenchScheduleSpec struct {
+	// Schedule is a cron expression such as "0 2 * * *", or a descriptor
+	// such as "@daily", interpreted in the time zone of the provider.
+	Schedule string `json:"schedule"`
+	// StartingDeadlineSeconds is how late a bench may start after a missed
+	// tick. Ticks missed for longer are skipped.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+	// ConcurrencyPolicy tells what to do when the schedule ticks while the
+	// bench of a previous tick still runs.
+	// +kubebuilder:default=Allow
+	// +optional
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+	// Suspend stops creating benches, without affecting those that run.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+	// BenchTemplate describes the bench created at each tick.
+	BenchTemplate KafkaBenchTemplate `json:"benchTemplate"`
+	// SuccessfulBenchesHistoryLimit is how many succeeded benches are kept.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=3
+	// +optional
+	SuccessfulBenchesHistoryLimit *int32 `json:"successfulBenchesHistoryLimit,omitempty"`
+	// FailedBenchesHistoryLimit is how many failed benches are kept.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1
+	// +optional
+	FailedBenchesHistoryLimit *int32 `json:"failedBenchesHistoryLimit,omitempty"`
+}
+
+// A KafkaBenchScheduleStatus reflects the observed state of a
+// KafkaBenchSchedule.
+type KafkaBenchScheduleStatus struct {
+	xpv1.ConditionedStatus `json:",inline"`
+	// Active lists the benches of the schedule that have not finished.
+	// +optional
+	Active []string `json:"active,omitempty"`
+	// LastScheduleTime is the last tick a bench was created for.
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// LastSkippedTime is the last tick skipped because a bench of the
+	// schedule was still running.
+	// +optional
+	LastSkippedTime *metav1.Time `json:"lastSkippedTime,omitempty"`
+	// LastSuccessfulTime is when the last succeeded bench was created.
+	// +optional
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+	// LastBench is the name of the last bench of the schedule that finished.
+	// +optional
+	LastBench string `json:"lastBench,omitempty"`
+	// LastResult is whether the last bench that finished Succeeded or
+	// Failed.
+	// +optional
+	LastResult string `json:"lastResult,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A KafkaBenchSchedule creates a KafkaBench from its template at every tick
+// of a cron schedule, much like a CronJob creates Jobs.
+// +kubebuilder:printcolumn:name="SCHEDULE",type="string",JSONPath=".spec.schedule"
+// +kubebuilder:printcolumn:name="SUSPEND",type="boolean",JSONPath=".spec.suspend"
+// +kubebuilder:printcolumn:name="LAST-SCHEDULE",type="date",JSONPath=".status.lastScheduleTime"
+// +kubebuilder:printcolumn:name="LAST-RESULT",type="string",JSONPath=".status.lastResult"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,template}
+type KafkaBenchSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaBenchScheduleSpec   `json:"spec"`
+	Status KafkaBenchScheduleStatus `json:"status,omitempty"`
+}
+
+// GetCondition of this KafkaBenchSchedule.
+func (o *KafkaBenchSchedule) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return o.Status.GetCondition(ct)
+}
+
+// SetConditions of this KafkaBenchSchedule.
+func (o *KafkaBenchSchedule) SetConditions(c ...xpv1.Condition) {
+	o.Status.SetConditions(c...)
+}
+
+// +kubebuilder:object:root=true
+
+// KafkaBenchScheduleList contains a list of KafkaBenchSchedule.
+type KafkaBenchScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaBenchSchedule `json:"items"`
+}
+
+// KafkaBenchSchedule type metadata.
+var (
+	KafkaBenchScheduleKind             = reflect.TypeOf(KafkaBenchSchedule{}).Name()
+	KafkaBenchScheduleGroupKind        = schema.GroupKind{Group: Group, Kind: KafkaBenchScheduleKind}.String()
+	KafkaBenchScheduleKindAPIVersion   = KafkaBenchScheduleKind + "." + SchemeGroupVersion.String()
+	KafkaBenchScheduleGroupVersionKind = SchemeGroupVersion.WithKind(KafkaBenchScheduleKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&KafkaBenchSchedule{}, &KafkaBenchScheduleList{})
+}
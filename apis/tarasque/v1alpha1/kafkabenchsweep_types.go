@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// A SweepAxis is a bench spec field, or a client configuration key, and the
+// values a sweep tries for it.
+type SweepAxis struct {
+	// Field is the name of a bench spec field, such as targetMessagesPerSec,
+	// or of a client configuration such as producerConf when Key is set.
+	Field string `json:"field"`
+	// Key is the client configuration key set in Field, such as batch.size.
+	// +optional
+	Key string `json:"key,omitempty"`
+	// Values are tried in order. Values of spec fields are read as JSON,
+	// falling back to plain strings.
+	// +kubebuilder:validation:MinItems=1
+	Values []string `json:"values"`
+}
+
+// A KafkaBenchSweepSpec defines the benches of a parameter sweep.
+type KafkaBenchSweepSpec struct {
+	// BenchTemplate describes the bench that each combination of values
+	// is applied to.
+	BenchTemplate KafkaBenchTemplate `json:"benchTemplate"`
+	// Axes are the parameters of the sweep. A bench is run for every
+	// combination of their values.
+	// +kubebuilder:validation:MinItems=1
+	Axes []SweepAxis `json:"axes"`
+	// Parallelism is how many benches of the sweep may run at once. Benches
+	// run one after the other by default.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	// +optional
+	Parallelism int32 `json:"parallelism,omitempty"`
+}
+
+// A SweepResult summarizes the bench of one combination of a sweep.
+type SweepResult struct {
+	// Bench is the name of the bench of the combination.
+	Bench string `json:"bench"`
+	// Parameters are the values of the combination, by axis.
+	Parameters map[string]string `json:"parameters"`
+	// Result is whether the bench Succeeded or Failed, once it finished.
+	// +optional
+	Result string `json:"result,omitempty"`
+	// MessagesPerSec is the throughput of the bench, once it finished.
+	// +optional
+	MessagesPerSec int64 `json:"messagesPerSec,omitempty"`
+	// P99LatencyMs is the 99th percentile latency of the bench, once it
+	// finished.
+	// +optional
+	P99LatencyMs int64 `json:"p99LatencyMs,omitempty"`
+}
+
+// A KafkaBenchSweepStatus reflects the observed state of a KafkaBenchSweep.
+type KafkaBenchSweepStatus struct {
+	xpv1.ConditionedStatus `json:",inline"`
+	// Combinations is the number of combinations of the sweep.
+	// +optional
+	Combinations int32 `json:"combinations,omitempty"`
+	// Completed is the number of combinations whose bench finished.
+	// +optional
+	Completed int32 `json:"completed,omitempty"`
+	// Results summarizes the benches of the sweep, in the order they run.
+	// +optional
+	Results []SweepResult `json:"results,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A KafkaBenchSweep runs a KafkaBench for every combination of values of its
+// axes, and summarizes their throughput and latency.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="COMBINATIONS",type="integer",JSONPath=".status.combinations"
+// +kubebuilder:printcolumn:name="COMPLETED",type="integer",JSONPath=".status.completed"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,template}
+type KafkaBenchSweep struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaBenchSweepSpec   `json:"spec"`
+	Status KafkaBenchSweepStatus `json:"status,omitempty"`
+}
+
+// GetCondition of this KafkaBenchSweep.
+func (o *KafkaBenchSweep) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return o.Status.GetCondition(ct)
+}
+
+// SetConditions of this KafkaBenchSweep.
+func (o *KafkaBenchSweep) SetConditions(c ...xpv1.Condition) {
+	o.Status.SetConditions(c...)
+}
+
+// +kubebuilder:object:root=true
+
+// KafkaBenchSweepList contains a list of KafkaBenchSweep.
+type KafkaBenchSweepList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaBenchSweep `json:"items"`
+}
+
+// KafkaBenchSweep type metadata.
+var (
+	KafkaBenchSweepKind             = reflect.TypeOf(KafkaBenchSweep{}).Name()
+	KafkaBenchSweepGroupKind        = schema.GroupKind{Group: Group, Kind: KafkaBenchSweepKind}.String()
+	KafkaBenchSweepKindAPIVersion   = KafkaBenchSweepKind + "." + SchemeGroupVersion.String()
+	KafkaBenchSweepGroupVersionKind = SchemeGroupVersion.WithKind(KafkaBenchSweepKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&KafkaBenchSweep{}, &KafkaBenchSweepList{})
+}
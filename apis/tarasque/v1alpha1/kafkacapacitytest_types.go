@@ -0,0 +1,160 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// A KafkaCapacityTestSpec defines how the maximum sustainable throughput of
+// a cluster is searched for.
+type KafkaCapacityTestSpec struct {
+	// BenchTemplate describes the produce bench run by every probe. Its
+	// targetMessagesPerSec and maxMessages are set by each probe.
+	BenchTemplate KafkaBenchTemplate `json:"benchTemplate"`
+	// P99LatencyMs is the 99th percentile latency a sustainable throughput
+	// must not exceed.
+	// +kubebuilder:validation:Minimum=1
+	P99LatencyMs int64 `json:"p99LatencyMs"`
+	// StartMessagesPerSec is the target of the first probe, doubled by each
+	// further probe until the cluster falls short.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1000
+	// +optional
+	StartMessagesPerSec int32 `json:"startMessagesPerSec,omitempty"`
+	// MaxMessagesPerSec is the highest target probed.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1000000
+	// +optional
+	MaxMessagesPerSec int32 `json:"maxMessagesPerSec,omitempty"`
+	// MinAchievedPercent is the share of its target a probe must achieve
+	// for its throughput to be sustainable.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default=95
+	// +optional
+	MinAchievedPercent int32 `json:"minAchievedPercent,omitempty"`
+	// ResolutionPercent ends the search once the highest sustainable and the
+	// lowest unsustainable targets are within this share of each other.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default=5
+	// +optional
+	ResolutionPercent int32 `json:"resolutionPercent,omitempty"`
+	// MaxProbes ends the search after this many probes.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=20
+	// +optional
+	MaxProbes int32 `json:"maxProbes,omitempty"`
+}
+
+// A CapacityProbe is one bench of a capacity test.
+type CapacityProbe struct {
+	// Bench is the name of the bench of the probe.
+	Bench string `json:"bench"`
+	// TargetMessagesPerSec is the throughput the probe produced at.
+	TargetMessagesPerSec int32 `json:"targetMessagesPerSec"`
+	// Result is whether the bench Succeeded or Failed, once it finished.
+	// +optional
+	Result string `json:"result,omitempty"`
+	// MessagesPerSec is the throughput the probe achieved.
+	// +optional
+	MessagesPerSec int64 `json:"messagesPerSec,omitempty"`
+	// P99LatencyMs is the 99th percentile latency of the probe.
+	// +optional
+	P99LatencyMs int64 `json:"p99LatencyMs,omitempty"`
+	// Sustained is whether the probe achieved its target within the latency
+	// objective.
+	// +optional
+	Sustained bool `json:"sustained,omitempty"`
+}
+
+// A LatencyPoint is the latency measured at a throughput.
+type LatencyPoint struct {
+	MessagesPerSec int64 `json:"messagesPerSec"`
+	P99LatencyMs   int64 `json:"p99LatencyMs"`
+}
+
+// A KafkaCapacityTestStatus reflects the observed state of a
+// KafkaCapacityTest.
+type KafkaCapacityTestStatus struct {
+	xpv1.ConditionedStatus `json:",inline"`
+	// SaturationMessagesPerSec is the highest target sustained so far, and
+	// the maximum sustainable throughput once the test is Available.
+	// +optional
+	SaturationMessagesPerSec int32 `json:"saturationMessagesPerSec,omitempty"`
+	// Probes are the benches of the test, in the order they ran.
+	// +optional
+	Probes []CapacityProbe `json:"probes,omitempty"`
+	// Curve is the latency of the finished probes by achieved throughput.
+	// +optional
+	Curve []LatencyPoint `json:"curve,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A KafkaCapacityTest searches for the highest throughput a cluster sustains
+// within a latency objective, by running produce benches at increasing
+// targets.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SATURATION",type="integer",JSONPath=".status.saturationMessagesPerSec"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,template}
+type KafkaCapacityTest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaCapacityTestSpec   `json:"spec"`
+	Status KafkaCapacityTestStatus `json:"status,omitempty"`
+}
+
+// GetCondition of this KafkaCapacityTest.
+func (o *KafkaCapacityTest) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return o.Status.GetCondition(ct)
+}
+
+// SetConditions of this KafkaCapacityTest.
+func (o *KafkaCapacityTest) SetConditions(c ...xpv1.Condition) {
+	o.Status.SetConditions(c...)
+}
+
+// +kubebuilder:object:root=true
+
+// KafkaCapacityTestList contains a list of KafkaCapacityTest.
+type KafkaCapacityTestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaCapacityTest `json:"items"`
+}
+
+// KafkaCapacityTest type metadata.
+var (
+	KafkaCapacityTestKind             = reflect.TypeOf(KafkaCapacityTest{}).Name()
+	KafkaCapacityTestGroupKind        = schema.GroupKind{Group: Group, Kind: KafkaCapacityTestKind}.String()
+	KafkaCapacityTestKindAPIVersion   = KafkaCapacityTestKind + "." + SchemeGroupVersion.String()
+	KafkaCapacityTestGroupVersionKind = SchemeGroupVersion.WithKind(KafkaCapacityTestKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&KafkaCapacityTest{}, &KafkaCapacityTestList{})
+}
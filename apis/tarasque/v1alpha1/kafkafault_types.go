@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// KafkaFaultObservation are the observable fields of a KafkaFault.
+type KafkaFaultObservation struct {
+	FaultState string `json:"faultState,omitempty"`
+	TaskID     string `json:"taskId,omitempty"`
+	WorkerID   int64  `json:"workerId,omitempty"`
+	// Agents lists the addresses of the Trogdor agents running the fault.
+	Agents    []string `json:"agents,omitempty"`
+	StartedMs int64    `json:"startedMs,omitempty"`
+	DoneMs    int64    `json:"doneMs,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// DegradedNetworkNodeSpec describes how the network of a node is degraded.
+type DegradedNetworkNodeSpec struct {
+	NetworkDevice string `json:"networkDevice,omitempty"`
+	LatencyMs     int32  `json:"latencyMs,omitempty"`
+	RateLimitKbit int32  `json:"rateLimitKbit,omitempty"`
+}
+
+// KafkaFaultParameters are the fields of a KafkaFault sent to the Trogdor
+// agents. Node names are matched against the agent pod names and the names of
+// the Kubernetes nodes they run on.
+type KafkaFaultParameters struct {
+	// +kubebuilder:validation:Enum=org.apache.kafka.trogdor.fault.NetworkPartitionFaultSpec;org.apache.kafka.trogdor.fault.ProcessStopFaultSpec;org.apache.kafka.trogdor.fault.DegradedNetworkFaultSpec;org.apache.kafka.trogdor.fault.FilesUnreadableFaultSpec
+	Class string `json:"class"`
+	// +kubebuilder:validation:Minimum=1
+	DurationMs int64 `json:"durationMs"`
+
+	// Partitions is used by NetworkPartitionFaultSpec.
+	Partitions [][]string `json:"partitions,omitempty"`
+
+	// NodeNames is used by ProcessStopFaultSpec and FilesUnreadableFaultSpec.
+	NodeNames       []string `json:"nodeNames,omitempty"`
+	JavaProcessName string   `json:"javaProcessName,omitempty"`
+
+	// NodeSpecs is used by DegradedNetworkFaultSpec.
+	NodeSpecs map[string]DegradedNetworkNodeSpec `json:"nodeSpecs,omitempty"`
+
+	// MountPath, Prefix and ErrorCode are used by the Kibosh based
+	// FilesUnreadableFaultSpec.
+	MountPath string `json:"mountPath,omitempty"`
+	Prefix    string `json:"prefix,omitempty"`
+	ErrorCode int32  `json:"errorCode,omitempty"`
+}
+
+// A KafkaFaultSpec defines the desired state of a KafkaFault.
+type KafkaFaultSpec struct {
+	xpv1.ResourceSpec    `json:",inline"`
+	KafkaFaultParameters `json:",inline"`
+	// StartAt is when the fault starts, such as the startAt of the bench
+	// it runs alongside. Faults without one start once created.
+	// +optional
+	StartAt *metav1.Time `json:"startAt,omitempty"`
+	// StartDelayMs delays the start of the fault after its startAt, or
+	// after it was created.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	StartDelayMs int64 `json:"startDelayMs,omitempty"`
+}
+
+// A KafkaFaultStatus represents the observed state of a KafkaFault.
+type KafkaFaultStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          KafkaFaultObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A KafkaFault injects a Trogdor fault on the agents of the given nodes.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="STATE",type="string",JSONPath=".status.atProvider.faultState"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,template}
+type KafkaFault struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaFaultSpec   `json:"spec"`
+	Status KafkaFaultStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KafkaFaultList contains a list of KafkaFault
+type KafkaFaultList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaFault `json:"items"`
+}
+
+// KafkaFault type metadata.
+var (
+	KafkaFaultKind             = reflect.TypeOf(KafkaFault{}).Name()
+	KafkaFaultGroupKind        = schema.GroupKind{Group: Group, Kind: KafkaFaultKind}.String()
+	KafkaFaultKindAPIVersion   = KafkaFaultKind + "." + SchemeGroupVersion.String()
+	KafkaFaultGroupVersionKind = SchemeGroupVersion.WithKind(KafkaFaultKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&KafkaFault{}, &KafkaFaultList{})
+}
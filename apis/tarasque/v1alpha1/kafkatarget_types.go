@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// A KafkaTargetSpec holds the connection settings of a Kafka cluster shared
+// by the benches that target it.
+type KafkaTargetSpec struct {
+	BootstrapServers string `json:"bootstrapServers"`
+	// +optional
+	CommonClientConf map[string]string `json:"commonClientConf,omitempty"`
+	// +optional
+	AdminClientConf map[string]string `json:"adminClientConf,omitempty"`
+	// SecretRef references the credentials of the cluster, in the same
+	// format as those of a ProviderConfig.
+	// +optional
+	SecretRef *xpv1.SecretKeySelector `json:"secretRef,omitempty"`
+	// Guardrails bound the load benches may put on the cluster.
+	// +optional
+	Guardrails *Guardrails `json:"guardrails,omitempty"`
+}
+
+// A KafkaTargetStatus reflects the observed state of a KafkaTarget.
+type KafkaTargetStatus struct {
+	xpv1.ProviderConfigStatus `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+
+// A KafkaTarget holds the connection settings of a Kafka cluster once for
+// the benches that reference it through their targetRef. KafkaTargets cannot
+// be deleted while benches use them.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="BOOTSTRAP-SERVERS",type="string",JSONPath=".spec.bootstrapServers"
+// +kubebuilder:printcolumn:name="USERS",type="integer",JSONPath=".status.users"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="SECRET-NAME",type="string",JSONPath=".spec.secretRef.name",priority=1
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,provider,template}
+type KafkaTarget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaTargetSpec   `json:"spec"`
+	Status KafkaTargetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KafkaTargetList contains a list of KafkaTarget.
+type KafkaTargetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaTarget `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+
+// A KafkaTargetUsage indicates that a bench is using a KafkaTarget. Its
+// providerConfigRef names the KafkaTarget.
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="TARGET-NAME",type="string",JSONPath=".providerConfigRef.name"
+// +kubebuilder:printcolumn:name="RESOURCE-KIND",type="string",JSONPath=".resourceRef.kind"
+// +kubebuilder:printcolumn:name="RESOURCE-NAME",type="string",JSONPath=".resourceRef.name"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,provider,template}
+type KafkaTargetUsage struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	xpv1.ProviderConfigUsage `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+
+// KafkaTargetUsageList contains a list of KafkaTargetUsage.
+type KafkaTargetUsageList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaTargetUsage `json:"items"`
+}
+
+// KafkaTarget type metadata.
+var (
+	KafkaTargetKind             = reflect.TypeOf(KafkaTarget{}).Name()
+	KafkaTargetGroupKind        = schema.GroupKind{Group: Group, Kind: KafkaTargetKind}.String()
+	KafkaTargetKindAPIVersion   = KafkaTargetKind + "." + SchemeGroupVersion.String()
+	KafkaTargetGroupVersionKind = SchemeGroupVersion.WithKind(KafkaTargetKind)
+)
+
+// KafkaTargetUsage type metadata.
+var (
+	KafkaTargetUsageKind             = reflect.TypeOf(KafkaTargetUsage{}).Name()
+	KafkaTargetUsageGroupKind        = schema.GroupKind{Group: Group, Kind: KafkaTargetUsageKind}.String()
+	KafkaTargetUsageKindAPIVersion   = KafkaTargetUsageKind + "." + SchemeGroupVersion.String()
+	KafkaTargetUsageGroupVersionKind = SchemeGroupVersion.WithKind(KafkaTargetUsageKind)
+
+	KafkaTargetUsageListKind             = reflect.TypeOf(KafkaTargetUsageList{}).Name()
+	KafkaTargetUsageListGroupKind        = schema.GroupKind{Group: Group, Kind: KafkaTargetUsageListKind}.String()
+	KafkaTargetUsageListKindAPIVersion   = KafkaTargetUsageListKind + "." + SchemeGroupVersion.String()
+	KafkaTargetUsageListGroupVersionKind = SchemeGroupVersion.WithKind(KafkaTargetUsageListKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&KafkaTarget{}, &KafkaTargetList{}, &KafkaTargetUsage{}, &KafkaTargetUsageList{})
+}
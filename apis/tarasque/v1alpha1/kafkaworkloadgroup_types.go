@@ -0,0 +1,163 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// Phases of the member of a KafkaWorkloadGroup, besides StepPending,
+// StepRunning, BenchSucceeded and BenchFailed.
+const (
+	// MemberWaiting members were validated and placed in the agent pool,
+	// and wait for the other members of their group.
+	MemberWaiting = "Waiting"
+	// MemberRefused members do not fit within the concurrency limits of the
+	// agent pool, so their group never starts.
+	MemberRefused = "Refused"
+)
+
+// A GroupMember is a bench of a group.
+type GroupMember struct {
+	// Name of the member, unique within the group.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=40
+	Name string `json:"name"`
+	// BenchTemplate describes the bench of the member.
+	BenchTemplate KafkaBenchTemplate `json:"benchTemplate"`
+}
+
+// A KafkaWorkloadGroupSpec defines the benches of a group.
+type KafkaWorkloadGroupSpec struct {
+	// Members of the group. Their benches start together.
+	// +kubebuilder:validation:MinItems=1
+	Members []GroupMember `json:"members"`
+	// StartDelayMs is how long after every member was validated and placed
+	// in the agent pool they start, so that each of them is dispatched in
+	// time.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=10000
+	// +optional
+	StartDelayMs int64 `json:"startDelayMs,omitempty"`
+}
+
+// A GroupMemberStatus reflects the observed state of a member of a group.
+type GroupMemberStatus struct {
+	// Name of the member.
+	Name string `json:"name"`
+	// Bench is the name of the bench of the member, once it was created.
+	// +optional
+	Bench string `json:"bench,omitempty"`
+	// Phase is Pending, Waiting, Refused, Running, Succeeded or Failed.
+	Phase string `json:"phase"`
+	// MessagesPerSec is the throughput of the bench, once it finished.
+	// +optional
+	MessagesPerSec int64 `json:"messagesPerSec,omitempty"`
+	// P99LatencyMs is the 99th percentile latency of the bench, once it
+	// finished.
+	// +optional
+	P99LatencyMs int64 `json:"p99LatencyMs,omitempty"`
+}
+
+// GroupResults combine the results of the members of a group.
+type GroupResults struct {
+	// ProducedMessagesPerSec is the throughput of the produce benches of the
+	// group together.
+	// +optional
+	ProducedMessagesPerSec int64 `json:"producedMessagesPerSec,omitempty"`
+	// ConsumedMessagesPerSec is the throughput of the consume benches of the
+	// group together.
+	// +optional
+	ConsumedMessagesPerSec int64 `json:"consumedMessagesPerSec,omitempty"`
+	// P99LatencyMs is the worst 99th percentile latency of the members.
+	// +optional
+	P99LatencyMs int64 `json:"p99LatencyMs,omitempty"`
+}
+
+// A KafkaWorkloadGroupStatus reflects the observed state of a
+// KafkaWorkloadGroup.
+type KafkaWorkloadGroupStatus struct {
+	xpv1.ConditionedStatus `json:",inline"`
+	// StartTime is the start the members of the group agreed on.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// Result is Succeeded once every member succeeded, or Failed once every
+	// member finished and one of them failed, or as soon as one of them was
+	// refused.
+	// +optional
+	Result string `json:"result,omitempty"`
+	// Members reflect the members of the group, in the order of its spec.
+	// +optional
+	Members []GroupMemberStatus `json:"members,omitempty"`
+	// Combined results of the members, once every one of them finished.
+	// +optional
+	Combined *GroupResults `json:"combined,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A KafkaWorkloadGroup starts the KafkaBenches of its members together, such
+// as a producer and a consumer of the same topics, and combines their results.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="START",type="date",JSONPath=".status.startTime"
+// +kubebuilder:printcolumn:name="RESULT",type="string",JSONPath=".status.result"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,template}
+type KafkaWorkloadGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaWorkloadGroupSpec   `json:"spec"`
+	Status KafkaWorkloadGroupStatus `json:"status,omitempty"`
+}
+
+// GetCondition of this KafkaWorkloadGroup.
+func (o *KafkaWorkloadGroup) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return o.Status.GetCondition(ct)
+}
+
+// SetConditions of this KafkaWorkloadGroup.
+func (o *KafkaWorkloadGroup) SetConditions(c ...xpv1.Condition) {
+	o.Status.SetConditions(c...)
+}
+
+// +kubebuilder:object:root=true
+
+// KafkaWorkloadGroupList contains a list of KafkaWorkloadGroup.
+type KafkaWorkloadGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaWorkloadGroup `json:"items"`
+}
+
+// KafkaWorkloadGroup type metadata.
+var (
+	KafkaWorkloadGroupKind             = reflect.TypeOf(KafkaWorkloadGroup{}).Name()
+	KafkaWorkloadGroupGroupKind        = schema.GroupKind{Group: Group, Kind: KafkaWorkloadGroupKind}.String()
+	KafkaWorkloadGroupKindAPIVersion   = KafkaWorkloadGroupKind + "." + SchemeGroupVersion.String()
+	KafkaWorkloadGroupGroupVersionKind = SchemeGroupVersion.WithKind(KafkaWorkloadGroupKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&KafkaWorkloadGroup{}, &KafkaWorkloadGroupList{})
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// +kubebuilder:object:root=true
+
+// A NamespacedKafkaBench is a KafkaBench owned by a namespace, so that teams
+// can be granted access to their own benches only. The Secrets, Kafka
+// clusters and connection secret it references must live in its namespace.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,categories={crossplane,managed,template}
+type NamespacedKafkaBench struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KafkaBenchSpec   `json:"spec"`
+	Status KafkaBenchStatus `json:"status,omitempty"`
+}
+
+// GetBenchSpec of this NamespacedKafkaBench.
+func (mg *NamespacedKafkaBench) GetBenchSpec() *KafkaBenchSpec {
+	return &mg.Spec
+}
+
+// GetBenchStatus of this NamespacedKafkaBench.
+func (mg *NamespacedKafkaBench) GetBenchStatus() *KafkaBenchStatus {
+	return &mg.Status
+}
+
+// GetTargetReference of this NamespacedKafkaBench.
+func (mg *NamespacedKafkaBench) GetTargetReference() *xpv1.Reference {
+	return mg.Spec.TargetRef
+}
+
+// +kubebuilder:object:root=true
+
+// NamespacedKafkaBenchList contains a list of NamespacedKafkaBench
+type NamespacedKafkaBenchList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NamespacedKafkaBench `json:"items"`
+}
+
+// NamespacedKafkaBench type metadata.
+var (
+	NamespacedKafkaBenchKind             = reflect.TypeOf(NamespacedKafkaBench{}).Name()
+	NamespacedKafkaBenchGroupKind        = schema.GroupKind{Group: Group, Kind: NamespacedKafkaBenchKind}.String()
+	NamespacedKafkaBenchKindAPIVersion   = NamespacedKafkaBenchKind + "." + SchemeGroupVersion.String()
+	NamespacedKafkaBenchGroupVersionKind = SchemeGroupVersion.WithKind(NamespacedKafkaBenchKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&NamespacedKafkaBench{}, &NamespacedKafkaBenchList{})
+}
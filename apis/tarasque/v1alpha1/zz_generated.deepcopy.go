@@ -22,9 +22,78 @@ limitations under the License.
 package v1alpha1
 
 import (
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AssertionResult) DeepCopyInto(out *AssertionResult) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AssertionResult.
+func (in *AssertionResult) DeepCopy() *AssertionResult {
+	if in == nil {
+		return nil
+	}
+	out := new(AssertionResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BenchRun) DeepCopyInto(out *BenchRun) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BenchRun.
+func (in *BenchRun) DeepCopy() *BenchRun {
+	if in == nil {
+		return nil
+	}
+	out := new(BenchRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityProbe) DeepCopyInto(out *CapacityProbe) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityProbe.
+func (in *CapacityProbe) DeepCopy() *CapacityProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapKeySelector) DeepCopyInto(out *ConfigMapKeySelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapKeySelector.
+func (in *ConfigMapKeySelector) DeepCopy() *ConfigMapKeySelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapKeySelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConsumerBenchResultStats) DeepCopyInto(out *ConsumerBenchResultStats) {
 	*out = *in
@@ -52,6 +121,117 @@ func (in *ConsumerBenchResultStats) DeepCopy() *ConsumerBenchResultStats {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DegradedNetworkNodeSpec) DeepCopyInto(out *DegradedNetworkNodeSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DegradedNetworkNodeSpec.
+func (in *DegradedNetworkNodeSpec) DeepCopy() *DegradedNetworkNodeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DegradedNetworkNodeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalCommandStatus) DeepCopyInto(out *ExternalCommandStatus) {
+	*out = *in
+	if in.LastStatus != nil {
+		in, out := &in.LastStatus, &out.LastStatus
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalCommandStatus.
+func (in *ExternalCommandStatus) DeepCopy() *ExternalCommandStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalCommandStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GroupMember) DeepCopyInto(out *GroupMember) {
+	*out = *in
+	in.BenchTemplate.DeepCopyInto(&out.BenchTemplate)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GroupMember.
+func (in *GroupMember) DeepCopy() *GroupMember {
+	if in == nil {
+		return nil
+	}
+	out := new(GroupMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GroupMemberStatus) DeepCopyInto(out *GroupMemberStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GroupMemberStatus.
+func (in *GroupMemberStatus) DeepCopy() *GroupMemberStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GroupMemberStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GroupResults) DeepCopyInto(out *GroupResults) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GroupResults.
+func (in *GroupResults) DeepCopy() *GroupResults {
+	if in == nil {
+		return nil
+	}
+	out := new(GroupResults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Guardrails) DeepCopyInto(out *Guardrails) {
+	*out = *in
+	if in.MaxBytes != nil {
+		in, out := &in.MaxBytes, &out.MaxBytes
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.AllowedBootstrapServers != nil {
+		in, out := &in.AllowedBootstrapServers, &out.AllowedBootstrapServers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Guardrails.
+func (in *Guardrails) DeepCopy() *Guardrails {
+	if in == nil {
+		return nil
+	}
+	out := new(Guardrails)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KafkaBench) DeepCopyInto(out *KafkaBench) {
 	*out = *in
@@ -123,6 +303,66 @@ func (in *KafkaBenchObservation) DeepCopyInto(out *KafkaBenchObservation) {
 		}
 	}
 	out.RoundTripStats = in.RoundTripStats
+	if in.RawStatus != nil {
+		in, out := &in.RawStatus, &out.RawStatus
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CommandStatus != nil {
+		in, out := &in.CommandStatus, &out.CommandStatus
+		*out = new(ExternalCommandStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EffectiveSpec != nil {
+		in, out := &in.EffectiveSpec, &out.EffectiveSpec
+		*out = new(KafkaBenchParameters)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScheduledTime != nil {
+		in, out := &in.ScheduledTime, &out.ScheduledTime
+		*out = (*in).DeepCopy()
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastProgressTime != nil {
+		in, out := &in.LastProgressTime, &out.LastProgressTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Segments != nil {
+		in, out := &in.Segments, &out.Segments
+		*out = make([]LoadSegment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Runs != nil {
+		in, out := &in.Runs, &out.Runs
+		*out = make([]BenchRun, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Summary != nil {
+		in, out := &in.Summary, &out.Summary
+		*out = new(RunSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Warmup != nil {
+		in, out := &in.Warmup, &out.Warmup
+		*out = new(WarmupStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Assertions != nil {
+		in, out := &in.Assertions, &out.Assertions
+		*out = make([]AssertionResult, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBenchObservation.
@@ -136,9 +376,8 @@ func (in *KafkaBenchObservation) DeepCopy() *KafkaBenchObservation {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KafkaBenchSpec) DeepCopyInto(out *KafkaBenchSpec) {
+func (in *KafkaBenchParameters) DeepCopyInto(out *KafkaBenchParameters) {
 	*out = *in
-	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
 	if in.ActiveTopics != nil {
 		in, out := &in.ActiveTopics, &out.ActiveTopics
 		*out = make(map[string]KafkaTopics, len(*in))
@@ -153,6 +392,11 @@ func (in *KafkaBenchSpec) DeepCopyInto(out *KafkaBenchSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.ConsumerTopics != nil {
+		in, out := &in.ConsumerTopics, &out.ConsumerTopics
+		*out = make([]ConsumerTopic, len(*in))
+		copy(*out, *in)
+	}
 	if in.ProducerConf != nil {
 		in, out := &in.ProducerConf, &out.ProducerConf
 		*out = make(map[string]string, len(*in))
@@ -181,76 +425,1505 @@ func (in *KafkaBenchSpec) DeepCopyInto(out *KafkaBenchSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Workload != nil {
+		in, out := &in.Workload, &out.Workload
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RawSpec != nil {
+		in, out := &in.RawSpec, &out.RawSpec
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBenchSpec.
-func (in *KafkaBenchSpec) DeepCopy() *KafkaBenchSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBenchParameters.
+func (in *KafkaBenchParameters) DeepCopy() *KafkaBenchParameters {
 	if in == nil {
 		return nil
 	}
-	out := new(KafkaBenchSpec)
+	out := new(KafkaBenchParameters)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KafkaBenchStatus) DeepCopyInto(out *KafkaBenchStatus) {
+func (in *KafkaBenchScenario) DeepCopyInto(out *KafkaBenchScenario) {
 	*out = *in
-	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
-	in.AtProvider.DeepCopyInto(&out.AtProvider)
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBenchStatus.
-func (in *KafkaBenchStatus) DeepCopy() *KafkaBenchStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBenchScenario.
+func (in *KafkaBenchScenario) DeepCopy() *KafkaBenchScenario {
 	if in == nil {
 		return nil
 	}
-	out := new(KafkaBenchStatus)
+	out := new(KafkaBenchScenario)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaBenchScenario) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KafkaTopics) DeepCopyInto(out *KafkaTopics) {
+func (in *KafkaBenchScenarioList) DeepCopyInto(out *KafkaBenchScenarioList) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaBenchScenario, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTopics.
-func (in *KafkaTopics) DeepCopy() *KafkaTopics {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBenchScenarioList.
+func (in *KafkaBenchScenarioList) DeepCopy() *KafkaBenchScenarioList {
 	if in == nil {
 		return nil
 	}
-	out := new(KafkaTopics)
+	out := new(KafkaBenchScenarioList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaBenchScenarioList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ProducerBenchResultStats) DeepCopyInto(out *ProducerBenchResultStats) {
+func (in *KafkaBenchScenarioSpec) DeepCopyInto(out *KafkaBenchScenarioSpec) {
 	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]ScenarioStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProducerBenchResultStats.
-func (in *ProducerBenchResultStats) DeepCopy() *ProducerBenchResultStats {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBenchScenarioSpec.
+func (in *KafkaBenchScenarioSpec) DeepCopy() *KafkaBenchScenarioSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ProducerBenchResultStats)
+	out := new(KafkaBenchScenarioSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RoundTripBenchResultStats) DeepCopyInto(out *RoundTripBenchResultStats) {
+func (in *KafkaBenchScenarioStatus) DeepCopyInto(out *KafkaBenchScenarioStatus) {
 	*out = *in
+	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]ScenarioStepStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoundTripBenchResultStats.
-func (in *RoundTripBenchResultStats) DeepCopy() *RoundTripBenchResultStats {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBenchScenarioStatus.
+func (in *KafkaBenchScenarioStatus) DeepCopy() *KafkaBenchScenarioStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(RoundTripBenchResultStats)
+	out := new(KafkaBenchScenarioStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBenchSchedule) DeepCopyInto(out *KafkaBenchSchedule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBenchSchedule.
+func (in *KafkaBenchSchedule) DeepCopy() *KafkaBenchSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBenchSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaBenchSchedule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBenchScheduleList) DeepCopyInto(out *KafkaBenchScheduleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaBenchSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBenchScheduleList.
+func (in *KafkaBenchScheduleList) DeepCopy() *KafkaBenchScheduleList {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBenchScheduleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaBenchScheduleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBenchScheduleSpec) DeepCopyInto(out *KafkaBenchScheduleSpec) {
+	*out = *in
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	in.BenchTemplate.DeepCopyInto(&out.BenchTemplate)
+	if in.SuccessfulBenchesHistoryLimit != nil {
+		in, out := &in.SuccessfulBenchesHistoryLimit, &out.SuccessfulBenchesHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailedBenchesHistoryLimit != nil {
+		in, out := &in.FailedBenchesHistoryLimit, &out.FailedBenchesHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBenchScheduleSpec.
+func (in *KafkaBenchScheduleSpec) DeepCopy() *KafkaBenchScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBenchScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBenchScheduleStatus) DeepCopyInto(out *KafkaBenchScheduleStatus) {
+	*out = *in
+	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
+	if in.Active != nil {
+		in, out := &in.Active, &out.Active
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSkippedTime != nil {
+		in, out := &in.LastSkippedTime, &out.LastSkippedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulTime != nil {
+		in, out := &in.LastSuccessfulTime, &out.LastSuccessfulTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBenchScheduleStatus.
+func (in *KafkaBenchScheduleStatus) DeepCopy() *KafkaBenchScheduleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBenchScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBenchSpec) DeepCopyInto(out *KafkaBenchSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.KafkaBenchParameters.DeepCopyInto(&out.KafkaBenchParameters)
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.SecretKeySelector)
+		**out = **in
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(KafkaBenchTLS)
+		**out = **in
+	}
+	if in.KafkaClusterRef != nil {
+		in, out := &in.KafkaClusterRef, &out.KafkaClusterRef
+		*out = new(KafkaClusterReference)
+		**out = **in
+	}
+	if in.TargetRef != nil {
+		in, out := &in.TargetRef, &out.TargetRef
+		*out = new(v1.Reference)
+		**out = **in
+	}
+	if in.Watchdog != nil {
+		in, out := &in.Watchdog, &out.Watchdog
+		*out = new(KafkaBenchWatchdog)
+		**out = **in
+	}
+	if in.LoadProfile != nil {
+		in, out := &in.LoadProfile, &out.LoadProfile
+		*out = new(LoadProfile)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartAt != nil {
+		in, out := &in.StartAt, &out.StartAt
+		*out = (*in).DeepCopy()
+	}
+	if in.StartWindow != nil {
+		in, out := &in.StartWindow, &out.StartWindow
+		*out = new(StartWindow)
+		**out = **in
+	}
+	if in.Warmup != nil {
+		in, out := &in.Warmup, &out.Warmup
+		*out = new(Warmup)
+		**out = **in
+	}
+	if in.Assertions != nil {
+		in, out := &in.Assertions, &out.Assertions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBenchSpec.
+func (in *KafkaBenchSpec) DeepCopy() *KafkaBenchSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBenchSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBenchStatus) DeepCopyInto(out *KafkaBenchStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBenchStatus.
+func (in *KafkaBenchStatus) DeepCopy() *KafkaBenchStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBenchStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBenchSweep) DeepCopyInto(out *KafkaBenchSweep) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBenchSweep.
+func (in *KafkaBenchSweep) DeepCopy() *KafkaBenchSweep {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBenchSweep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaBenchSweep) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBenchSweepList) DeepCopyInto(out *KafkaBenchSweepList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaBenchSweep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBenchSweepList.
+func (in *KafkaBenchSweepList) DeepCopy() *KafkaBenchSweepList {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBenchSweepList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaBenchSweepList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBenchSweepSpec) DeepCopyInto(out *KafkaBenchSweepSpec) {
+	*out = *in
+	in.BenchTemplate.DeepCopyInto(&out.BenchTemplate)
+	if in.Axes != nil {
+		in, out := &in.Axes, &out.Axes
+		*out = make([]SweepAxis, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBenchSweepSpec.
+func (in *KafkaBenchSweepSpec) DeepCopy() *KafkaBenchSweepSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBenchSweepSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBenchSweepStatus) DeepCopyInto(out *KafkaBenchSweepStatus) {
+	*out = *in
+	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]SweepResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBenchSweepStatus.
+func (in *KafkaBenchSweepStatus) DeepCopy() *KafkaBenchSweepStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBenchSweepStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBenchTLS) DeepCopyInto(out *KafkaBenchTLS) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBenchTLS.
+func (in *KafkaBenchTLS) DeepCopy() *KafkaBenchTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBenchTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBenchTemplate) DeepCopyInto(out *KafkaBenchTemplate) {
+	*out = *in
+	in.Metadata.DeepCopyInto(&out.Metadata)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBenchTemplate.
+func (in *KafkaBenchTemplate) DeepCopy() *KafkaBenchTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBenchTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBenchTemplateMeta) DeepCopyInto(out *KafkaBenchTemplateMeta) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBenchTemplateMeta.
+func (in *KafkaBenchTemplateMeta) DeepCopy() *KafkaBenchTemplateMeta {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBenchTemplateMeta)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaBenchWatchdog) DeepCopyInto(out *KafkaBenchWatchdog) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaBenchWatchdog.
+func (in *KafkaBenchWatchdog) DeepCopy() *KafkaBenchWatchdog {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaBenchWatchdog)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaCapacityTest) DeepCopyInto(out *KafkaCapacityTest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaCapacityTest.
+func (in *KafkaCapacityTest) DeepCopy() *KafkaCapacityTest {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaCapacityTest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaCapacityTest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaCapacityTestList) DeepCopyInto(out *KafkaCapacityTestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaCapacityTest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaCapacityTestList.
+func (in *KafkaCapacityTestList) DeepCopy() *KafkaCapacityTestList {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaCapacityTestList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaCapacityTestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaCapacityTestSpec) DeepCopyInto(out *KafkaCapacityTestSpec) {
+	*out = *in
+	in.BenchTemplate.DeepCopyInto(&out.BenchTemplate)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaCapacityTestSpec.
+func (in *KafkaCapacityTestSpec) DeepCopy() *KafkaCapacityTestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaCapacityTestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaCapacityTestStatus) DeepCopyInto(out *KafkaCapacityTestStatus) {
+	*out = *in
+	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = make([]CapacityProbe, len(*in))
+		copy(*out, *in)
+	}
+	if in.Curve != nil {
+		in, out := &in.Curve, &out.Curve
+		*out = make([]LatencyPoint, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaCapacityTestStatus.
+func (in *KafkaCapacityTestStatus) DeepCopy() *KafkaCapacityTestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaCapacityTestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaClusterReference) DeepCopyInto(out *KafkaClusterReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaClusterReference.
+func (in *KafkaClusterReference) DeepCopy() *KafkaClusterReference {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaClusterReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaFault) DeepCopyInto(out *KafkaFault) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaFault.
+func (in *KafkaFault) DeepCopy() *KafkaFault {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaFault)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaFault) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaFaultList) DeepCopyInto(out *KafkaFaultList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaFault, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaFaultList.
+func (in *KafkaFaultList) DeepCopy() *KafkaFaultList {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaFaultList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaFaultList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaFaultObservation) DeepCopyInto(out *KafkaFaultObservation) {
+	*out = *in
+	if in.Agents != nil {
+		in, out := &in.Agents, &out.Agents
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaFaultObservation.
+func (in *KafkaFaultObservation) DeepCopy() *KafkaFaultObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaFaultObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaFaultParameters) DeepCopyInto(out *KafkaFaultParameters) {
+	*out = *in
+	if in.Partitions != nil {
+		in, out := &in.Partitions, &out.Partitions
+		*out = make([][]string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+		}
+	}
+	if in.NodeNames != nil {
+		in, out := &in.NodeNames, &out.NodeNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeSpecs != nil {
+		in, out := &in.NodeSpecs, &out.NodeSpecs
+		*out = make(map[string]DegradedNetworkNodeSpec, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaFaultParameters.
+func (in *KafkaFaultParameters) DeepCopy() *KafkaFaultParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaFaultParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaFaultSpec) DeepCopyInto(out *KafkaFaultSpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.KafkaFaultParameters.DeepCopyInto(&out.KafkaFaultParameters)
+	if in.StartAt != nil {
+		in, out := &in.StartAt, &out.StartAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaFaultSpec.
+func (in *KafkaFaultSpec) DeepCopy() *KafkaFaultSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaFaultSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaFaultStatus) DeepCopyInto(out *KafkaFaultStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaFaultStatus.
+func (in *KafkaFaultStatus) DeepCopy() *KafkaFaultStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaFaultStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaTarget) DeepCopyInto(out *KafkaTarget) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTarget.
+func (in *KafkaTarget) DeepCopy() *KafkaTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaTarget) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaTargetList) DeepCopyInto(out *KafkaTargetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTargetList.
+func (in *KafkaTargetList) DeepCopy() *KafkaTargetList {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaTargetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaTargetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaTargetSpec) DeepCopyInto(out *KafkaTargetSpec) {
+	*out = *in
+	if in.CommonClientConf != nil {
+		in, out := &in.CommonClientConf, &out.CommonClientConf
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AdminClientConf != nil {
+		in, out := &in.AdminClientConf, &out.AdminClientConf
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.SecretKeySelector)
+		**out = **in
+	}
+	if in.Guardrails != nil {
+		in, out := &in.Guardrails, &out.Guardrails
+		*out = new(Guardrails)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTargetSpec.
+func (in *KafkaTargetSpec) DeepCopy() *KafkaTargetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaTargetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaTargetStatus) DeepCopyInto(out *KafkaTargetStatus) {
+	*out = *in
+	in.ProviderConfigStatus.DeepCopyInto(&out.ProviderConfigStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTargetStatus.
+func (in *KafkaTargetStatus) DeepCopy() *KafkaTargetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaTargetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaTargetUsage) DeepCopyInto(out *KafkaTargetUsage) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.ProviderConfigUsage = in.ProviderConfigUsage
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTargetUsage.
+func (in *KafkaTargetUsage) DeepCopy() *KafkaTargetUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaTargetUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaTargetUsage) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaTargetUsageList) DeepCopyInto(out *KafkaTargetUsageList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaTargetUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTargetUsageList.
+func (in *KafkaTargetUsageList) DeepCopy() *KafkaTargetUsageList {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaTargetUsageList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaTargetUsageList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaTopics) DeepCopyInto(out *KafkaTopics) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaTopics.
+func (in *KafkaTopics) DeepCopy() *KafkaTopics {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaTopics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaWorkloadGroup) DeepCopyInto(out *KafkaWorkloadGroup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaWorkloadGroup.
+func (in *KafkaWorkloadGroup) DeepCopy() *KafkaWorkloadGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaWorkloadGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaWorkloadGroup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaWorkloadGroupList) DeepCopyInto(out *KafkaWorkloadGroupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KafkaWorkloadGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaWorkloadGroupList.
+func (in *KafkaWorkloadGroupList) DeepCopy() *KafkaWorkloadGroupList {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaWorkloadGroupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KafkaWorkloadGroupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaWorkloadGroupSpec) DeepCopyInto(out *KafkaWorkloadGroupSpec) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]GroupMember, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaWorkloadGroupSpec.
+func (in *KafkaWorkloadGroupSpec) DeepCopy() *KafkaWorkloadGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaWorkloadGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaWorkloadGroupStatus) DeepCopyInto(out *KafkaWorkloadGroupStatus) {
+	*out = *in
+	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]GroupMemberStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Combined != nil {
+		in, out := &in.Combined, &out.Combined
+		*out = new(GroupResults)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaWorkloadGroupStatus.
+func (in *KafkaWorkloadGroupStatus) DeepCopy() *KafkaWorkloadGroupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaWorkloadGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LatencyPoint) DeepCopyInto(out *LatencyPoint) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LatencyPoint.
+func (in *LatencyPoint) DeepCopy() *LatencyPoint {
+	if in == nil {
+		return nil
+	}
+	out := new(LatencyPoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadPoint) DeepCopyInto(out *LoadPoint) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadPoint.
+func (in *LoadPoint) DeepCopy() *LoadPoint {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadPoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadProfile) DeepCopyInto(out *LoadProfile) {
+	*out = *in
+	if in.Ramp != nil {
+		in, out := &in.Ramp, &out.Ramp
+		*out = new(RampProfile)
+		**out = **in
+	}
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]LoadPoint, len(*in))
+		copy(*out, *in)
+	}
+	if in.Sine != nil {
+		in, out := &in.Sine, &out.Sine
+		*out = new(SineProfile)
+		**out = **in
+	}
+	if in.Replay != nil {
+		in, out := &in.Replay, &out.Replay
+		*out = new(ReplayProfile)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadProfile.
+func (in *LoadProfile) DeepCopy() *LoadProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadSegment) DeepCopyInto(out *LoadSegment) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	out.ProducerStats = in.ProducerStats
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadSegment.
+func (in *LoadSegment) DeepCopy() *LoadSegment {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadSegment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedKafkaBench) DeepCopyInto(out *NamespacedKafkaBench) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedKafkaBench.
+func (in *NamespacedKafkaBench) DeepCopy() *NamespacedKafkaBench {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedKafkaBench)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespacedKafkaBench) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedKafkaBenchList) DeepCopyInto(out *NamespacedKafkaBenchList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NamespacedKafkaBench, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedKafkaBenchList.
+func (in *NamespacedKafkaBenchList) DeepCopy() *NamespacedKafkaBenchList {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedKafkaBenchList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NamespacedKafkaBenchList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProducerBenchResultStats) DeepCopyInto(out *ProducerBenchResultStats) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProducerBenchResultStats.
+func (in *ProducerBenchResultStats) DeepCopy() *ProducerBenchResultStats {
+	if in == nil {
+		return nil
+	}
+	out := new(ProducerBenchResultStats)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RampProfile) DeepCopyInto(out *RampProfile) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RampProfile.
+func (in *RampProfile) DeepCopy() *RampProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(RampProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplayProfile) DeepCopyInto(out *ReplayProfile) {
+	*out = *in
+	out.ConfigMapRef = in.ConfigMapRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplayProfile.
+func (in *ReplayProfile) DeepCopy() *ReplayProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplayProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoundTripBenchResultStats) DeepCopyInto(out *RoundTripBenchResultStats) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoundTripBenchResultStats.
+func (in *RoundTripBenchResultStats) DeepCopy() *RoundTripBenchResultStats {
+	if in == nil {
+		return nil
+	}
+	out := new(RoundTripBenchResultStats)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RunSummary) DeepCopyInto(out *RunSummary) {
+	*out = *in
+	out.MessagesPerSec = in.MessagesPerSec
+	if in.AverageLatencyMs != nil {
+		in, out := &in.AverageLatencyMs, &out.AverageLatencyMs
+		*out = new(Statistic)
+		**out = **in
+	}
+	if in.P50LatencyMs != nil {
+		in, out := &in.P50LatencyMs, &out.P50LatencyMs
+		*out = new(Statistic)
+		**out = **in
+	}
+	if in.P95LatencyMs != nil {
+		in, out := &in.P95LatencyMs, &out.P95LatencyMs
+		*out = new(Statistic)
+		**out = **in
+	}
+	if in.P99LatencyMs != nil {
+		in, out := &in.P99LatencyMs, &out.P99LatencyMs
+		*out = new(Statistic)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RunSummary.
+func (in *RunSummary) DeepCopy() *RunSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(RunSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScenarioStep) DeepCopyInto(out *ScenarioStep) {
+	*out = *in
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.BenchTemplate.DeepCopyInto(&out.BenchTemplate)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScenarioStep.
+func (in *ScenarioStep) DeepCopy() *ScenarioStep {
+	if in == nil {
+		return nil
+	}
+	out := new(ScenarioStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScenarioStepStatus) DeepCopyInto(out *ScenarioStepStatus) {
+	*out = *in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScenarioStepStatus.
+func (in *ScenarioStepStatus) DeepCopy() *ScenarioStepStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScenarioStepStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SineProfile) DeepCopyInto(out *SineProfile) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SineProfile.
+func (in *SineProfile) DeepCopy() *SineProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(SineProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StartWindow) DeepCopyInto(out *StartWindow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StartWindow.
+func (in *StartWindow) DeepCopy() *StartWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(StartWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Statistic) DeepCopyInto(out *Statistic) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Statistic.
+func (in *Statistic) DeepCopy() *Statistic {
+	if in == nil {
+		return nil
+	}
+	out := new(Statistic)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SweepAxis) DeepCopyInto(out *SweepAxis) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SweepAxis.
+func (in *SweepAxis) DeepCopy() *SweepAxis {
+	if in == nil {
+		return nil
+	}
+	out := new(SweepAxis)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SweepResult) DeepCopyInto(out *SweepResult) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SweepResult.
+func (in *SweepResult) DeepCopy() *SweepResult {
+	if in == nil {
+		return nil
+	}
+	out := new(SweepResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Warmup) DeepCopyInto(out *Warmup) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Warmup.
+func (in *Warmup) DeepCopy() *Warmup {
+	if in == nil {
+		return nil
+	}
+	out := new(Warmup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WarmupStatus) DeepCopyInto(out *WarmupStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	out.ProducerStats = in.ProducerStats
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WarmupStatus.
+func (in *WarmupStatus) DeepCopy() *WarmupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WarmupStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -74,3 +74,115 @@ func (mg *KafkaBench) SetProviderReference(r *xpv1.Reference) {
 func (mg *KafkaBench) SetWriteConnectionSecretToReference(r *xpv1.SecretReference) {
 	mg.Spec.WriteConnectionSecretToReference = r
 }
+
+// GetCondition of this KafkaFault.
+func (mg *KafkaFault) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return mg.Status.GetCondition(ct)
+}
+
+// GetDeletionPolicy of this KafkaFault.
+func (mg *KafkaFault) GetDeletionPolicy() xpv1.DeletionPolicy {
+	return mg.Spec.DeletionPolicy
+}
+
+// GetProviderConfigReference of this KafkaFault.
+func (mg *KafkaFault) GetProviderConfigReference() *xpv1.Reference {
+	return mg.Spec.ProviderConfigReference
+}
+
+/*
+GetProviderReference of this KafkaFault.
+Deprecated: Use GetProviderConfigReference.
+*/
+func (mg *KafkaFault) GetProviderReference() *xpv1.Reference {
+	return mg.Spec.ProviderReference
+}
+
+// GetWriteConnectionSecretToReference of this KafkaFault.
+func (mg *KafkaFault) GetWriteConnectionSecretToReference() *xpv1.SecretReference {
+	return mg.Spec.WriteConnectionSecretToReference
+}
+
+// SetConditions of this KafkaFault.
+func (mg *KafkaFault) SetConditions(c ...xpv1.Condition) {
+	mg.Status.SetConditions(c...)
+}
+
+// SetDeletionPolicy of this KafkaFault.
+func (mg *KafkaFault) SetDeletionPolicy(r xpv1.DeletionPolicy) {
+	mg.Spec.DeletionPolicy = r
+}
+
+// SetProviderConfigReference of this KafkaFault.
+func (mg *KafkaFault) SetProviderConfigReference(r *xpv1.Reference) {
+	mg.Spec.ProviderConfigReference = r
+}
+
+/*
+SetProviderReference of this KafkaFault.
+Deprecated: Use SetProviderConfigReference.
+*/
+func (mg *KafkaFault) SetProviderReference(r *xpv1.Reference) {
+	mg.Spec.ProviderReference = r
+}
+
+// SetWriteConnectionSecretToReference of this KafkaFault.
+func (mg *KafkaFault) SetWriteConnectionSecretToReference(r *xpv1.SecretReference) {
+	mg.Spec.WriteConnectionSecretToReference = r
+}
+
+// GetCondition of this NamespacedKafkaBench.
+func (mg *NamespacedKafkaBench) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return mg.Status.GetCondition(ct)
+}
+
+// GetDeletionPolicy of this NamespacedKafkaBench.
+func (mg *NamespacedKafkaBench) GetDeletionPolicy() xpv1.DeletionPolicy {
+	return mg.Spec.DeletionPolicy
+}
+
+// GetProviderConfigReference of this NamespacedKafkaBench.
+func (mg *NamespacedKafkaBench) GetProviderConfigReference() *xpv1.Reference {
+	return mg.Spec.ProviderConfigReference
+}
+
+/*
+GetProviderReference of this NamespacedKafkaBench.
+Deprecated: Use GetProviderConfigReference.
+*/
+func (mg *NamespacedKafkaBench) GetProviderReference() *xpv1.Reference {
+	return mg.Spec.ProviderReference
+}
+
+// GetWriteConnectionSecretToReference of this NamespacedKafkaBench.
+func (mg *NamespacedKafkaBench) GetWriteConnectionSecretToReference() *xpv1.SecretReference {
+	return mg.Spec.WriteConnectionSecretToReference
+}
+
+// SetConditions of this NamespacedKafkaBench.
+func (mg *NamespacedKafkaBench) SetConditions(c ...xpv1.Condition) {
+	mg.Status.SetConditions(c...)
+}
+
+// SetDeletionPolicy of this NamespacedKafkaBench.
+func (mg *NamespacedKafkaBench) SetDeletionPolicy(r xpv1.DeletionPolicy) {
+	mg.Spec.DeletionPolicy = r
+}
+
+// SetProviderConfigReference of this NamespacedKafkaBench.
+func (mg *NamespacedKafkaBench) SetProviderConfigReference(r *xpv1.Reference) {
+	mg.Spec.ProviderConfigReference = r
+}
+
+/*
+SetProviderReference of this NamespacedKafkaBench.
+Deprecated: Use SetProviderConfigReference.
+*/
+func (mg *NamespacedKafkaBench) SetProviderReference(r *xpv1.Reference) {
+	mg.Spec.ProviderReference = r
+}
+
+// SetWriteConnectionSecretToReference of this NamespacedKafkaBench.
+func (mg *NamespacedKafkaBench) SetWriteConnectionSecretToReference(r *xpv1.SecretReference) {
+	mg.Spec.WriteConnectionSecretToReference = r
+}
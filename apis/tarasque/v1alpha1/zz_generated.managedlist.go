@@ -27,3 +27,21 @@ func (l *KafkaBenchList) GetItems() []resource.Managed {
 	}
 	return items
 }
+
+// GetItems of this KafkaFaultList.
+func (l *KafkaFaultList) GetItems() []resource.Managed {
+	items := make([]resource.Managed, len(l.Items))
+	for i := range l.Items {
+		items[i] = &l.Items[i]
+	}
+	return items
+}
+
+// GetItems of this NamespacedKafkaBenchList.
+func (l *NamespacedKafkaBenchList) GetItems() []resource.Managed {
+	items := make([]resource.Managed, len(l.Items))
+	for i := range l.Items {
+		items[i] = &l.Items[i]
+	}
+	return items
+}
@@ -23,12 +23,46 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	tarasquev1alpha1 "github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
 )
 
 // A ProviderConfigSpec defines the desired state of a ProviderConfig.
 type ProviderConfigSpec struct {
 	// Credentials required to authenticate to this provider.
 	Credentials ProviderCredentials `json:"credentials"`
+
+	// BenchDefaults are applied to every KafkaBench using this
+	// ProviderConfig. Fields set by a bench always win, while client
+	// configurations are merged key by key.
+	// +optional
+	BenchDefaults *tarasquev1alpha1.KafkaBenchParameters `json:"benchDefaults,omitempty"`
+
+	// Concurrency limits the benches using this ProviderConfig that run at
+	// once. Further benches wait in a queue.
+	// +optional
+	Concurrency *ConcurrencyLimits `json:"concurrency,omitempty"`
+
+	// Guardrails bound the load benches using this ProviderConfig may put
+	// on a Kafka cluster.
+	// +optional
+	Guardrails *tarasquev1alpha1.Guardrails `json:"guardrails,omitempty"`
+}
+
+// ConcurrencyLimits of the benches using a ProviderConfig. A limit of 0
+// means no limit.
+type ConcurrencyLimits struct {
+	// MaxBenches is the number of benches using this ProviderConfig that
+	// run at once.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxBenches int32 `json:"maxBenches,omitempty"`
+	// MaxBenchesPerNamespace is the number of benches of a namespace using
+	// this ProviderConfig that run at once. Cluster scoped benches count as
+	// a namespace of their own.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxBenchesPerNamespace int32 `json:"maxBenchesPerNamespace,omitempty"`
 }
 
 // ProviderCredentials required to authenticate.
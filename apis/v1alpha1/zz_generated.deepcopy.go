@@ -22,9 +22,25 @@ limitations under the License.
 package v1alpha1
 
 import (
+	tarasquev1alpha1 "github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConcurrencyLimits) DeepCopyInto(out *ConcurrencyLimits) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConcurrencyLimits.
+func (in *ConcurrencyLimits) DeepCopy() *ConcurrencyLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(ConcurrencyLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProviderConfig) DeepCopyInto(out *ProviderConfig) {
 	*out = *in
@@ -88,6 +104,21 @@ func (in *ProviderConfigList) DeepCopyObject() runtime.Object {
 func (in *ProviderConfigSpec) DeepCopyInto(out *ProviderConfigSpec) {
 	*out = *in
 	in.Credentials.DeepCopyInto(&out.Credentials)
+	if in.BenchDefaults != nil {
+		in, out := &in.BenchDefaults, &out.BenchDefaults
+		*out = new(tarasquev1alpha1.KafkaBenchParameters)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Concurrency != nil {
+		in, out := &in.Concurrency, &out.Concurrency
+		*out = new(ConcurrencyLimits)
+		**out = **in
+	}
+	if in.Guardrails != nil {
+		in, out := &in.Guardrails, &out.Guardrails
+		*out = new(tarasquev1alpha1.Guardrails)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderConfigSpec.
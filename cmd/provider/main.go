@@ -31,6 +31,9 @@ import (
 
 	"github.com/nachomdo/tarasque/apis"
 	"github.com/nachomdo/tarasque/internal/controller"
+	"github.com/nachomdo/tarasque/internal/controller/kafkabench"
+	"github.com/nachomdo/tarasque/internal/redact"
+	"github.com/nachomdo/tarasque/internal/webhook"
 )
 
 func main() {
@@ -39,8 +42,15 @@ func main() {
 		debug          = app.Flag("debug", "Run with debug logging.").Short('d').Bool()
 		syncPeriod     = app.Flag("sync", "Controller manager sync period such as 300ms, 1.5h, or 2h45m").Short('s').Default("1h").Duration()
 		leaderElection = app.Flag("leader-election", "Use leader election for the controller manager.").Short('l').Default("false").OverrideDefaultFromEnvar("LEADER_ELECTION").Bool()
+		redactPattern  = app.Flag("redact-pattern", "A regular expression matching further Kafka client configuration keys whose values are masked in logs, events and status.").Envar("REDACT_PATTERN").String()
+		webhookCertDir = app.Flag("webhook-tls-cert-dir", "The directory of the TLS certificate used to serve admission webhooks. Webhooks are disabled when unset.").Envar("WEBHOOK_TLS_CERT_DIR").String()
+		maxBenches     = app.Flag("max-concurrent-benches", "The maximum number of benches running at once on the agent pool. Further benches are queued. 0 means no limit.").Default("0").Envar("MAX_CONCURRENT_BENCHES").Int32()
 	)
 	kingpin.MustParse(app.Parse(os.Args[1:]))
+	kingpin.FatalIfError(redact.SetPattern(*redactPattern), "Cannot use redaction pattern")
+	kafkabench.SetMaxConcurrentBenches(*maxBenches)
+	// Guardrails approvals are only checked by the webhook.
+	kafkabench.SetGuardrailsApprovals(*webhookCertDir != "")
 
 	zl := zap.New(zap.UseDevMode(*debug))
 	log := logging.NewLogrLogger(zl.WithName("provider-tarasque"))
@@ -60,11 +70,15 @@ func main() {
 		LeaderElection:   *leaderElection,
 		LeaderElectionID: "crossplane-leader-election-provider-tarasque",
 		SyncPeriod:       syncPeriod,
+		CertDir:          *webhookCertDir,
 	})
 	kingpin.FatalIfError(err, "Cannot create controller manager")
 
 	rl := ratelimiter.NewDefaultProviderRateLimiter(ratelimiter.DefaultProviderRPS)
 	kingpin.FatalIfError(apis.AddToScheme(mgr.GetScheme()), "Cannot add Tarasque APIs to scheme")
 	kingpin.FatalIfError(controller.Setup(mgr, log, rl), "Cannot setup Tarasque controllers")
+	if *webhookCertDir != "" {
+		kingpin.FatalIfError(webhook.Setup(mgr), "Cannot setup Tarasque webhooks")
+	}
 	kingpin.FatalIfError(mgr.Start(ctrl.SetupSignalHandler()), "Cannot start controller manager")
 }
@@ -0,0 +1,212 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package assertion evaluates the assertions of a bench over its results.
+package assertion
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/benches"
+)
+
+// Metrics assertions may refer to.
+const (
+	ProducerTotalSent             = "producer.totalSent"
+	ProducerAverageLatencyMs      = "producer.averageLatencyMs"
+	ProducerP50LatencyMs          = "producer.p50LatencyMs"
+	ProducerP95LatencyMs          = "producer.p95LatencyMs"
+	ProducerP99LatencyMs          = "producer.p99LatencyMs"
+	ProducerTransactionsCommitted = "producer.transactionsCommitted"
+	ConsumerTotalMessagesReceived = "consumer.totalMessagesReceived"
+	ConsumerTotalBytesReceived    = "consumer.totalBytesReceived"
+	ConsumerAverageLatencyMs      = "consumer.averageLatencyMs"
+	ConsumerP50LatencyMs          = "consumer.p50LatencyMs"
+	ConsumerP95LatencyMs          = "consumer.p95LatencyMs"
+	ConsumerP99LatencyMs          = "consumer.p99LatencyMs"
+	RoundTripTotalUniqueSent      = "roundTrip.totalUniqueSent"
+	RoundTripTotalReceived        = "roundTrip.totalReceived"
+	ThroughputMsgsPerSec          = "throughputMsgsPerSec"
+)
+
+var known = map[string]bool{
+	ProducerTotalSent: true, ProducerAverageLatencyMs: true, ProducerP50LatencyMs: true, ProducerP95LatencyMs: true,
+	ProducerP99LatencyMs: true, ProducerTransactionsCommitted: true, ConsumerTotalMessagesReceived: true,
+	ConsumerTotalBytesReceived: true, ConsumerAverageLatencyMs: true, ConsumerP50LatencyMs: true,
+	ConsumerP95LatencyMs: true, ConsumerP99LatencyMs: true, RoundTripTotalUniqueSent: true,
+	RoundTripTotalReceived: true, ThroughputMsgsPerSec: true,
+}
+
+const (
+	errSyntax         = "expected a metric or a number compared to another with one of <, <=, >, >=, == and !="
+	errFmtUnknown     = "unknown metric %q"
+	errFmtNotReported = "%s is not reported by the bench"
+)
+
+var exprRegexp = regexp.MustCompile(`^\s*([A-Za-z0-9_.+-]+)\s*(<=|>=|==|!=|<|>)\s*([A-Za-z0-9_.+-]+)\s*$`)
+
+// An Assertion compares two operands, each a metric or a number.
+type Assertion struct {
+	Left  string
+	Op    string
+	Right string
+}
+
+// Parse returns the assertion of the supplied expression, such as
+// "producer.p99LatencyMs < 50".
+func Parse(expr string) (Assertion, error) {
+	m := exprRegexp.FindStringSubmatch(expr)
+	if m == nil {
+		return Assertion{}, errors.New(errSyntax)
+	}
+	a := Assertion{Left: m[1], Op: m[2], Right: m[3]}
+	for _, o := range []string{a.Left, a.Right} {
+		if _, err := strconv.ParseFloat(o, 64); err != nil && !known[o] {
+			return Assertion{}, errors.Errorf(errFmtUnknown, o)
+		}
+	}
+	return a, nil
+}
+
+// Evaluate returns whether the supplied assertion holds for the supplied
+// metrics, and the values of the metrics it refers to.
+func Evaluate(a Assertion, metrics map[string]float64) (bool, string, error) {
+	values := []string{}
+	operand := func(o string) (float64, error) {
+		if v, err := strconv.ParseFloat(o, 64); err == nil {
+			return v, nil
+		}
+		v, ok := metrics[o]
+		if !ok {
+			return 0, errors.Errorf(errFmtNotReported, o)
+		}
+		values = append(values, fmt.Sprintf("%s is %s", o, strconv.FormatFloat(v, 'f', -1, 64)))
+		return v, nil
+	}
+	l, err := operand(a.Left)
+	if err != nil {
+		return false, "", err
+	}
+	r, err := operand(a.Right)
+	if err != nil {
+		return false, "", err
+	}
+	msg := strings.Join(values, ", ")
+	switch a.Op {
+	case "<":
+		return l < r, msg, nil
+	case "<=":
+		return l <= r, msg, nil
+	case ">":
+		return l > r, msg, nil
+	case ">=":
+		return l >= r, msg, nil
+	case "==":
+		return l == r, msg, nil
+	default:
+		return l != r, msg, nil
+	}
+}
+
+// Results returns the outcomes of the supplied assertions for the supplied
+// metrics. Assertions that cannot be evaluated do not pass.
+func Results(exprs []string, metrics map[string]float64) []v1alpha1.AssertionResult {
+	results := make([]v1alpha1.AssertionResult, len(exprs))
+	for i, expr := range exprs {
+		results[i] = v1alpha1.AssertionResult{Expression: expr}
+		a, err := Parse(expr)
+		if err != nil {
+			results[i].Message = err.Error()
+			continue
+		}
+		passed, msg, err := Evaluate(a, metrics)
+		if err != nil {
+			results[i].Message = err.Error()
+			continue
+		}
+		results[i].Passed, results[i].Message = passed, msg
+	}
+	return results
+}
+
+// Failed returns the expressions of the supplied results that did not pass.
+func Failed(results []v1alpha1.AssertionResult) []string {
+	failed := []string{}
+	for _, r := range results {
+		if !r.Passed {
+			failed = append(failed, r.Expression)
+		}
+	}
+	return failed
+}
+
+// Metrics returns the metrics reported by the supplied observation of a
+// finished bench running a workload of the supplied class. The latency
+// percentiles of a consume bench are the worst of its consumers, and those of
+// a round trip bench count the messages it sent and received as those of a
+// producer and a consumer.
+func Metrics(class string, obs v1alpha1.KafkaBenchObservation) map[string]float64 {
+	msgsPerSec, _ := benches.ObservedThroughput(class, obs)
+	m := map[string]float64{ThroughputMsgsPerSec: float64(msgsPerSec)}
+	switch class {
+	case v1alpha1.ProduceBenchClass:
+		ps := obs.ProducerStats
+		m[ProducerTotalSent] = float64(ps.TotalSent)
+		m[ProducerAverageLatencyMs] = ps.AverageLatencyMs
+		m[ProducerP50LatencyMs] = float64(ps.P50LatencyMs)
+		m[ProducerP95LatencyMs] = float64(ps.P95LatencyMs)
+		m[ProducerP99LatencyMs] = float64(ps.P99LatencyMs)
+		m[ProducerTransactionsCommitted] = float64(ps.TransactionsCommitted)
+	case v1alpha1.ConsumeBenchClass:
+		var msgs, bytes, p50, p95, p99 int64
+		weighted := 0.0
+		for _, cs := range obs.ConsumerStats {
+			msgs += cs.TotalMessagesReceived
+			bytes += cs.TotalBytesReceived
+			weighted += cs.AverageLatencyMs * float64(cs.TotalMessagesReceived)
+			p50, p95, p99 = max(p50, cs.P50LatencyMs), max(p95, cs.P95LatencyMs), max(p99, cs.P99LatencyMs)
+		}
+		m[ConsumerTotalMessagesReceived] = float64(msgs)
+		m[ConsumerTotalBytesReceived] = float64(bytes)
+		m[ConsumerAverageLatencyMs] = 0
+		if msgs > 0 {
+			m[ConsumerAverageLatencyMs] = weighted / float64(msgs)
+		}
+		m[ConsumerP50LatencyMs] = float64(p50)
+		m[ConsumerP95LatencyMs] = float64(p95)
+		m[ConsumerP99LatencyMs] = float64(p99)
+	case v1alpha1.RoundTripWorkloadClass:
+		rs := obs.RoundTripStats
+		m[RoundTripTotalUniqueSent] = float64(rs.TotalUniqueSent)
+		m[RoundTripTotalReceived] = float64(rs.TotalReceived)
+		m[ProducerTotalSent] = float64(rs.TotalUniqueSent)
+		m[ConsumerTotalMessagesReceived] = float64(rs.TotalReceived)
+	}
+	return m
+}
+
+func max(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assertion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+func TestParse(t *testing.T) {
+	type want struct {
+		a   Assertion
+		err bool
+	}
+	cases := map[string]struct {
+		reason string
+		expr   string
+		want   want
+	}{
+		"Metric": {
+			reason: "A metric compared to a number should be parsed.",
+			expr:   "producer.p99LatencyMs < 50",
+			want:   want{a: Assertion{Left: "producer.p99LatencyMs", Op: "<", Right: "50"}},
+		},
+		"Metrics": {
+			reason: "Two metrics compared without spaces should be parsed.",
+			expr:   "consumer.totalMessagesReceived==producer.totalSent",
+			want:   want{a: Assertion{Left: "consumer.totalMessagesReceived", Op: "==", Right: "producer.totalSent"}},
+		},
+		"Malformed": {
+			reason: "Expressions that are not a comparison should be reported.",
+			expr:   "producer.p99LatencyMs < 50 && throughputMsgsPerSec > 9000",
+			want:   want{err: true},
+		},
+		"Unknown": {
+			reason: "Unknown metrics should be reported.",
+			expr:   "producer.p999LatencyMs < 50",
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			a, err := Parse(tc.expr)
+			if diff := cmp.Diff(tc.want.err, err != nil); diff != "" {
+				t.Fatalf("\n%s\nParse(...): -want error, +got error: %v\n", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.a, a); diff != "" {
+				t.Errorf("\n%s\nParse(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestResults(t *testing.T) {
+	metrics := map[string]float64{ProducerP99LatencyMs: 62, ProducerTotalSent: 100000, ThroughputMsgsPerSec: 9500}
+	exprs := []string{
+		"producer.p99LatencyMs < 50",
+		"throughputMsgsPerSec >= 9000",
+		"consumer.totalMessagesReceived == producer.totalSent",
+		"producer.p99LatencyMs <> 50",
+	}
+	want := []v1alpha1.AssertionResult{
+		{Expression: exprs[0], Message: "producer.p99LatencyMs is 62"},
+		{Expression: exprs[1], Passed: true, Message: "throughputMsgsPerSec is 9500"},
+		{Expression: exprs[2], Message: "consumer.totalMessagesReceived is not reported by the bench"},
+		{Expression: exprs[3], Message: errSyntax},
+	}
+	got := Results(exprs, metrics)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Results(...): -want, +got:\n%s\n", diff)
+	}
+	if diff := cmp.Diff([]string{exprs[0], exprs[2], exprs[3]}, Failed(got)); diff != "" {
+		t.Errorf("Failed(...): -want, +got:\n%s\n", diff)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	start := time.Date(2022, 6, 1, 2, 0, 0, 0, time.UTC)
+	timed := func(obs v1alpha1.KafkaBenchObservation) v1alpha1.KafkaBenchObservation {
+		obs.StartTime = &metav1.Time{Time: start}
+		obs.CompletionTime = &metav1.Time{Time: start.Add(10 * time.Second)}
+		return obs
+	}
+	cases := map[string]struct {
+		reason string
+		class  string
+		obs    v1alpha1.KafkaBenchObservation
+		want   map[string]float64
+	}{
+		"Consumer": {
+			reason: "The messages of consumers should add up, and their worst latencies be kept.",
+			class:  v1alpha1.ConsumeBenchClass,
+			obs: timed(v1alpha1.KafkaBenchObservation{ConsumerStats: map[string]v1alpha1.ConsumerBenchResultStats{
+				"consumer-0": {TotalMessagesReceived: 30000, TotalBytesReceived: 3000, AverageLatencyMs: 2, P50LatencyMs: 1, P95LatencyMs: 4, P99LatencyMs: 8},
+				"consumer-1": {TotalMessagesReceived: 10000, TotalBytesReceived: 1000, AverageLatencyMs: 6, P50LatencyMs: 3, P95LatencyMs: 9, P99LatencyMs: 30},
+			}}),
+			want: map[string]float64{
+				ThroughputMsgsPerSec:          4000,
+				ConsumerTotalMessagesReceived: 40000,
+				ConsumerTotalBytesReceived:    4000,
+				ConsumerAverageLatencyMs:      3,
+				ConsumerP50LatencyMs:          3,
+				ConsumerP95LatencyMs:          9,
+				ConsumerP99LatencyMs:          30,
+			},
+		},
+		"RoundTrip": {
+			reason: "The messages a round trip bench sent and received should count as those of a producer and a consumer.",
+			class:  v1alpha1.RoundTripWorkloadClass,
+			obs:    timed(v1alpha1.KafkaBenchObservation{RoundTripStats: v1alpha1.RoundTripBenchResultStats{TotalUniqueSent: 1000, TotalReceived: 990}}),
+			want: map[string]float64{
+				ThroughputMsgsPerSec:          99,
+				RoundTripTotalUniqueSent:      1000,
+				RoundTripTotalReceived:        990,
+				ProducerTotalSent:             1000,
+				ConsumerTotalMessagesReceived: 990,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Metrics(tc.class, tc.obs)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nMetrics(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package benches creates and follows the KafkaBenches of other resources,
+// such as the benches created by a KafkaBenchSchedule.
+package benches
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+const (
+	taskStatusDone     = "DONE"
+	taskStatusTimedOut = "TIMED_OUT"
+	taskStatusWaiting  = "WAITING"
+	taskStatusMissed   = "MISSED"
+	taskStatusFailed   = "FAILED"
+	taskStatusRefused  = "REFUSED"
+
+	errListBenches = "cannot list benches"
+)
+
+// New returns a KafkaBench created from the supplied template, controlled by
+// the supplied owner. The supplied labels are added to those of the template
+// so that the bench can be listed by Owned.
+func New(owner metav1.Object, of schema.GroupVersionKind, name string, t v1alpha1.KafkaBenchTemplate, labels map[string]string) *v1alpha1.KafkaBench {
+	kb := &v1alpha1.KafkaBench{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
+		},
+		Spec: *t.Spec.DeepCopy(),
+	}
+	for k, v := range t.Metadata.Labels {
+		kb.Labels[k] = v
+	}
+	for k, v := range labels {
+		kb.Labels[k] = v
+	}
+	for k, v := range t.Metadata.Annotations {
+		kb.Annotations[k] = v
+	}
+	meta.AddOwnerReference(kb, meta.AsController(meta.TypedReferenceTo(owner, of)))
+	return kb
+}
+
+// Owned returns the KafkaBenches with the supplied labels that are controlled
+// by the supplied owner, oldest first.
+func Owned(ctx context.Context, kube client.Reader, owner metav1.Object, labels map[string]string) ([]v1alpha1.KafkaBench, error) {
+	l := &v1alpha1.KafkaBenchList{}
+	if err := kube.List(ctx, l, client.MatchingLabels(labels)); err != nil {
+		return nil, errors.Wrap(err, errListBenches)
+	}
+	owned := []v1alpha1.KafkaBench{}
+	for _, kb := range l.Items {
+		if metav1.IsControlledBy(&kb, owner) {
+			owned = append(owned, kb)
+		}
+	}
+	sort.SliceStable(owned, func(i, j int) bool {
+		a, b := owned[i].GetCreationTimestamp(), owned[j].GetCreationTimestamp()
+		if !a.Equal(&b) {
+			return a.Before(&b)
+		}
+		return owned[i].GetName() < owned[j].GetName()
+	})
+	return owned, nil
+}
+
+// Result returns whether the supplied bench Succeeded or Failed, and false if
+// it has not finished yet. A bench fails when its watchdog stopped it, when its
+// worker reported an error, when it missed its start window, when its group is
+// too large for the agent pool, or when its assertions did not hold.
+func Result(kb *v1alpha1.KafkaBench) (string, bool) {
+	switch kb.Status.AtProvider.TaskStatus {
+	case taskStatusTimedOut, taskStatusMissed, taskStatusFailed, taskStatusRefused:
+		return v1alpha1.BenchFailed, true
+	case taskStatusDone:
+		if !Completed(kb.Status.AtProvider) {
+			return "", false
+		}
+		if c := kb.GetCondition(v1alpha1.TypePassed); c.Status == corev1.ConditionFalse {
+			return v1alpha1.BenchFailed, true
+		}
+		return v1alpha1.BenchSucceeded, true
+	}
+	return "", false
+}
+
+// Waiting returns whether the supplied bench of a group was validated and
+// placed in the agent pool, and waits for the other members of its group.
+func Waiting(kb *v1alpha1.KafkaBench) bool {
+	return kb.Status.AtProvider.TaskStatus == taskStatusWaiting
+}
+
+// Refused returns whether the supplied bench of a group was refused, as its
+// group does not fit within the concurrency limits of the agent pool.
+func Refused(kb *v1alpha1.KafkaBench) bool {
+	return kb.Status.AtProvider.TaskStatus == taskStatusRefused
+}
+
+// Finished returns whether a task in the supplied status will not make any
+// further progress.
+func Finished(status string) bool {
+	return status == taskStatusDone || status == taskStatusTimedOut || status == taskStatusFailed
+}
+
+// Completed returns whether the supplied bench is over: its task finished,
+// and neither its steady state, a segment of its load profile nor a run of its
+// repetitions has yet to start.
+func Completed(obs v1alpha1.KafkaBenchObservation) bool {
+	return Finished(obs.TaskStatus) && !WarmupLeft(obs) && !SegmentsLeft(obs) && !RunsLeft(obs)
+}
+
+// WarmingUp returns whether the task of a bench is that of its warm-up.
+func WarmingUp(obs v1alpha1.KafkaBenchObservation) bool {
+	return obs.Warmup != nil && obs.Warmup.WorkerID == obs.WorkerID
+}
+
+// WarmupLeft returns whether the warm-up of a bench is done while the bench
+// has yet to start.
+func WarmupLeft(obs v1alpha1.KafkaBenchObservation) bool {
+	return WarmingUp(obs) && obs.TaskStatus == taskStatusDone
+}
+
+// SegmentsLeft returns whether the current segment of a bench with a load
+// profile is done while further segments have yet to run.
+func SegmentsLeft(obs v1alpha1.KafkaBenchObservation) bool {
+	return obs.TaskStatus == taskStatusDone && int(obs.CurrentSegment) < len(obs.Segments)-1
+}
+
+// RunsLeft returns whether the current run of a bench with repetitions is
+// done while further runs have yet to start.
+func RunsLeft(obs v1alpha1.KafkaBenchObservation) bool {
+	return obs.TaskStatus == taskStatusDone && !SegmentsLeft(obs) && int(obs.CurrentRun) < len(obs.Runs)-1
+}
+
+// Throughput returns the messages per second and the 99th percentile latency
+// of the supplied finished bench, as reported by Trogdor. Messages per second
+// are zero unless both the start and completion of the bench were recorded.
+// Those of a bench with repetitions are the means of its runs.
+func Throughput(kb *v1alpha1.KafkaBench) (msgsPerSec int64, p99LatencyMs int64) {
+	class := kb.Spec.Class
+	if kb.Status.AtProvider.EffectiveSpec != nil {
+		class = kb.Status.AtProvider.EffectiveSpec.Class
+	}
+	return ObservedThroughput(class, kb.Status.AtProvider)
+}
+
+// ObservedThroughput returns the messages per second and the 99th percentile
+// latency reported by the supplied observation of a finished bench running a
+// workload of the supplied class, like Throughput.
+func ObservedThroughput(class string, obs v1alpha1.KafkaBenchObservation) (msgsPerSec int64, p99LatencyMs int64) {
+	if s := obs.Summary; s != nil {
+		if s.P99LatencyMs != nil {
+			p99LatencyMs = int64(math.Round(s.P99LatencyMs.Mean))
+		}
+		return int64(math.Round(s.MessagesPerSec.Mean)), p99LatencyMs
+	}
+	var msgs int64
+	switch class {
+	case v1alpha1.ProduceBenchClass:
+		msgs, p99LatencyMs = obs.ProducerStats.TotalSent, obs.ProducerStats.P99LatencyMs
+	case v1alpha1.ConsumeBenchClass:
+		for _, s := range obs.ConsumerStats {
+			msgs += s.TotalMessagesReceived
+			if s.P99LatencyMs > p99LatencyMs {
+				p99LatencyMs = s.P99LatencyMs
+			}
+		}
+	case v1alpha1.RoundTripWorkloadClass:
+		msgs = obs.RoundTripStats.TotalReceived
+	}
+	if obs.StartTime == nil || obs.CompletionTime == nil {
+		return 0, p99LatencyMs
+	}
+	d := obs.CompletionTime.Sub(obs.StartTime.Time)
+	if d <= 0 {
+		return 0, p99LatencyMs
+	}
+	return int64(float64(msgs) / d.Seconds()), p99LatencyMs
+}
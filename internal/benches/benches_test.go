@@ -0,0 +1,178 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benches
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+func TestThroughput(t *testing.T) {
+	start := time.Date(2022, 6, 1, 2, 0, 0, 0, time.UTC)
+	newBench := func(class string, done bool, obs v1alpha1.KafkaBenchObservation) *v1alpha1.KafkaBench {
+		kb := &v1alpha1.KafkaBench{}
+		kb.Spec.Class = class
+		kb.Status.AtProvider = obs
+		kb.Status.AtProvider.StartTime = &metav1.Time{Time: start}
+		if done {
+			kb.Status.AtProvider.CompletionTime = &metav1.Time{Time: start.Add(20 * time.Second)}
+		}
+		return kb
+	}
+	type want struct {
+		msgsPerSec int64
+		p99        int64
+	}
+	cases := map[string]struct {
+		reason string
+		kb     *v1alpha1.KafkaBench
+		want   want
+	}{
+		"Producer": {
+			reason: "The throughput of producers should be their messages sent per second.",
+			kb:     newBench(v1alpha1.ProduceBenchClass, true, v1alpha1.KafkaBenchObservation{ProducerStats: v1alpha1.ProducerBenchResultStats{TotalSent: 100000, P99LatencyMs: 12}}),
+			want:   want{msgsPerSec: 5000, p99: 12},
+		},
+		"Consumer": {
+			reason: "The throughput of consumers should add up, and their worst latency be kept.",
+			kb: newBench(v1alpha1.ConsumeBenchClass, true, v1alpha1.KafkaBenchObservation{ConsumerStats: map[string]v1alpha1.ConsumerBenchResultStats{
+				"consumer-0": {TotalMessagesReceived: 60000, P99LatencyMs: 8},
+				"consumer-1": {TotalMessagesReceived: 40000, P99LatencyMs: 30},
+			}}),
+			want: want{msgsPerSec: 5000, p99: 30},
+		},
+		"Repetitions": {
+			reason: "The throughput of a bench with repetitions should be the mean of its runs.",
+			kb: newBench(v1alpha1.ProduceBenchClass, true, v1alpha1.KafkaBenchObservation{
+				ProducerStats: v1alpha1.ProducerBenchResultStats{TotalSent: 100000, P99LatencyMs: 12},
+				Summary:       &v1alpha1.RunSummary{Runs: 3, MessagesPerSec: v1alpha1.Statistic{Mean: 4800.4}, P99LatencyMs: &v1alpha1.Statistic{Mean: 10.6}},
+			}),
+			want: want{msgsPerSec: 4800, p99: 11},
+		},
+		"NotDone": {
+			reason: "Benches without a completion time should have no throughput.",
+			kb:     newBench(v1alpha1.ProduceBenchClass, false, v1alpha1.KafkaBenchObservation{ProducerStats: v1alpha1.ProducerBenchResultStats{TotalSent: 100000, P99LatencyMs: 12}}),
+			want:   want{p99: 12},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got want
+			got.msgsPerSec, got.p99 = Throughput(tc.kb)
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nThroughput(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestResult(t *testing.T) {
+	newBench := func(status string, c ...xpv1.Condition) *v1alpha1.KafkaBench {
+		kb := &v1alpha1.KafkaBench{}
+		kb.Status.AtProvider.TaskStatus = status
+		kb.SetConditions(c...)
+		return kb
+	}
+	type want struct {
+		result   string
+		finished bool
+	}
+	cases := map[string]struct {
+		reason string
+		kb     *v1alpha1.KafkaBench
+		want   want
+	}{
+		"Running": {
+			reason: "Benches that are not done should not be finished.",
+			kb:     newBench("RUNNING"),
+			want:   want{},
+		},
+		"Succeeded": {
+			reason: "Benches that are done should succeed.",
+			kb:     newBench(taskStatusDone, v1alpha1.AssertionsPassed()),
+			want:   want{result: v1alpha1.BenchSucceeded, finished: true},
+		},
+		"WorkerFailed": {
+			reason: "Benches whose worker reported an error should fail.",
+			kb:     newBench(taskStatusFailed, v1alpha1.WorkerFailed("Topic creation failed")),
+			want:   want{result: v1alpha1.BenchFailed, finished: true},
+		},
+		"Refused": {
+			reason: "Benches of a group too large for the agent pool should fail.",
+			kb:     newBench(taskStatusRefused, v1alpha1.GroupTooLarge("mixed")),
+			want:   want{result: v1alpha1.BenchFailed, finished: true},
+		},
+		"AssertionsFailed": {
+			reason: "Benches whose assertions did not hold should fail.",
+			kb:     newBench(taskStatusDone, v1alpha1.AssertionsFailed("1 of 1 assertions failed")),
+			want:   want{result: v1alpha1.BenchFailed, finished: true},
+		},
+		"ReconcileError": {
+			reason: "Benches that are done should succeed despite a transient reconcile error.",
+			kb:     newBench(taskStatusDone, xpv1.ReconcileError(errors.New("boom")), v1alpha1.AssertionsPassed()),
+			want:   want{result: v1alpha1.BenchSucceeded, finished: true},
+		},
+		"WarmupLeft": {
+			reason: "Benches whose warm-up is done should not be finished before their steady state.",
+			kb: func() *v1alpha1.KafkaBench {
+				kb := newBench(taskStatusDone)
+				kb.Status.AtProvider.WorkerID = 42
+				kb.Status.AtProvider.Warmup = &v1alpha1.WarmupStatus{WorkerID: 42}
+				return kb
+			}(),
+			want: want{},
+		},
+		"SegmentsLeft": {
+			reason: "Benches with a load profile should not be finished before their last segment.",
+			kb: func() *v1alpha1.KafkaBench {
+				kb := newBench(taskStatusDone)
+				kb.Status.AtProvider.Segments = make([]v1alpha1.LoadSegment, 2)
+				return kb
+			}(),
+			want: want{},
+		},
+		"RunsLeft": {
+			reason: "Benches with repetitions should not be finished before their last run.",
+			kb: func() *v1alpha1.KafkaBench {
+				kb := newBench(taskStatusDone)
+				kb.Status.AtProvider.Runs = make([]v1alpha1.BenchRun, 3)
+				kb.Status.AtProvider.CurrentRun = 1
+				return kb
+			}(),
+			want: want{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got want
+			got.result, got.finished = Result(tc.kb)
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nResult(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
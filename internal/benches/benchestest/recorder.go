@@ -0,0 +1,40 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package benchestest contains test doubles shared by the controllers of
+// KafkaBenches and of the resources that create them.
+package benchestest
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+)
+
+// A Recorder records the reasons of the events it is sent.
+type Recorder struct {
+	Reasons []event.Reason
+}
+
+// Event records the reason of the supplied event.
+func (r *Recorder) Event(_ runtime.Object, e event.Event) {
+	r.Reasons = append(r.Reasons, e.Reason)
+}
+
+// WithAnnotations returns the Recorder itself.
+func (r *Recorder) WithAnnotations(_ ...string) event.Recorder {
+	return r
+}
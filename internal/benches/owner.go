@@ -0,0 +1,75 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benches
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+// An Owner creates KafkaBenches and reports how they are doing in the
+// conditions of its status.
+type Owner interface {
+	client.Object
+	resource.Conditioned
+}
+
+// SetupOwner adds a controller with the supplied name that reconciles owners
+// of the supplied kind. An owner is reconciled again whenever one of its
+// benches changes.
+func SetupOwner(mgr ctrl.Manager, name string, of Owner, r reconcile.Reconciler, rl workqueue.RateLimiter) error {
+	o := controller.Options{
+		RateLimiter: ratelimiter.NewDefaultManagedRateLimiter(rl),
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o).
+		For(of).
+		Owns(&v1alpha1.KafkaBench{}).
+		Complete(r)
+}
+
+// Get the owner with the supplied key. It returns false when the owner no
+// longer exists, in which case there's no need to requeue it, or when it is
+// being deleted, in which case its benches are garbage collected.
+func Get(ctx context.Context, kube client.Reader, key types.NamespacedName, o Owner) (bool, error) {
+	if err := kube.Get(ctx, key, o); err != nil {
+		return false, resource.IgnoreNotFound(err)
+	}
+	return !meta.WasDeleted(o), nil
+}
+
+// Failed records that the last reconcile of the supplied owner failed with the
+// supplied error, and updates its status.
+func Failed(ctx context.Context, kube client.StatusClient, o Owner, err error) error {
+	o.SetConditions(xpv1.ReconcileError(err))
+	return kube.Status().Update(ctx, o)
+}
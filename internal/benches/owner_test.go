@@ -0,0 +1,83 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benches
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+func TestGet(t *testing.T) {
+	errBoom := errors.New("boom")
+	now := metav1.Now()
+
+	type want struct {
+		ok  bool
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		get    test.MockGetFn
+		want   want
+	}{
+		"NotFound": {
+			reason: "Owners that no longer exist should not be requeued.",
+			get:    test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "nightly")),
+			want:   want{},
+		},
+		"GetError": {
+			reason: "Errors getting an owner should be returned.",
+			get:    test.NewMockGetFn(errBoom),
+			want:   want{err: errBoom},
+		},
+		"Deleted": {
+			reason: "Owners being deleted should be left to the garbage collector.",
+			get: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+				obj.SetDeletionTimestamp(&now)
+				return nil
+			},
+			want: want{},
+		},
+		"Exists": {
+			reason: "Owners that exist should be reconciled.",
+			get:    test.NewMockGetFn(nil),
+			want:   want{ok: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got want
+			got.ok, got.err = Get(context.TODO(), &test.MockClient{MockGet: tc.get}, types.NamespacedName{Name: "nightly"}, &v1alpha1.KafkaBenchSchedule{})
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{}), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
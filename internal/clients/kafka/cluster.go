@@ -0,0 +1,285 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+var (
+	strimziKafkaGVK = schema.GroupVersionKind{Group: "kafka.strimzi.io", Version: "v1beta2", Kind: "Kafka"}
+	cfkKafkaGVK     = schema.GroupVersionKind{Group: "platform.confluent.io", Version: "v1beta1", Kind: "Kafka"}
+)
+
+// Keys of the Secrets managed by the Kafka operators.
+const (
+	strimziCAKey       = "ca.crt"
+	strimziUserCertKey = "user.crt"
+	strimziUserKeyKey  = "user.key"
+	strimziPasswordKey = "password"
+	strimziCASuffix    = "-cluster-ca-cert"
+
+	cfkCAKey           = "cacerts.pem"
+	cfkAutoCASecret    = "ca-pair-sslcerts"
+	cfkPlainUsersKey   = "plain-users.json"
+	cfkAuthPlain       = "plain"
+	strimziAuthTLS     = "tls"
+	strimziAuthSCRAM   = "scram-sha-512"
+	scramSHA512        = "SCRAM-SHA-512"
+	protocolPlaintext  = "PLAINTEXT"
+	protocolSASLPlain  = "SASL_PLAINTEXT"
+	protocolSASLSSL    = "SASL_SSL"
+	endpointSchemeSep  = "://"
+	cfkCustomListeners = "custom"
+)
+
+const (
+	errGetKafka        = "cannot get Kafka object"
+	errGetSecret       = "cannot get Secret"
+	errFmtOperator     = "unsupported Kafka operator %q"
+	errFmtNoListener   = "listener %q not found in the status of the Kafka object"
+	errFmtNoBootstrap  = "listener %q has no bootstrap address yet"
+	errFmtUserAuth     = "listener %q does not authenticate users with TLS or SCRAM-SHA-512"
+	errFmtNoSecretKey  = "Secret %s/%s has no %s key"
+	errFmtUnknownUser  = "user %q not found in the PLAIN users of listener %q"
+	errParsePlainUsers = "cannot parse PLAIN users"
+)
+
+// A Cluster holds the connection details resolved from a Kafka cluster
+// managed by an operator.
+type Cluster struct {
+	BootstrapServers string
+	// Conf holds the security client configurations of the cluster,
+	// including any TLS material and user credentials.
+	Conf map[string]string
+}
+
+// ResolveCluster resolves the connection details of the supplied cluster
+// from the objects and Secrets of the operator that manages it.
+func ResolveCluster(ctx context.Context, kube client.Reader, ref v1alpha1.KafkaClusterReference) (*Cluster, error) {
+	switch ref.Operator {
+	case v1alpha1.StrimziOperator:
+		return resolveStrimzi(ctx, kube, ref)
+	case v1alpha1.CFKOperator:
+		return resolveCFK(ctx, kube, ref)
+	default:
+		return nil, errors.Errorf(errFmtOperator, ref.Operator)
+	}
+}
+
+func resolveStrimzi(ctx context.Context, kube client.Reader, ref v1alpha1.KafkaClusterReference) (*Cluster, error) {
+	k, err := getKafka(ctx, kube, strimziKafkaGVK, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	status := findListener(k.Object, ref.Listener, "status", "listeners")
+	if status == nil {
+		return nil, errors.Errorf(errFmtNoListener, ref.Listener)
+	}
+	bootstrap, _, _ := unstructured.NestedString(status, "bootstrapServers")
+	if bootstrap == "" {
+		return nil, errors.Errorf(errFmtNoBootstrap, ref.Listener)
+	}
+	spec := findListener(k.Object, ref.Listener, "spec", "kafka", "listeners")
+	tls, _, _ := unstructured.NestedBool(spec, "tls")
+	auth, _, _ := unstructured.NestedString(spec, "authentication", "type")
+
+	var ca, cert, key []byte
+	conf := map[string]string{}
+	if tls {
+		certs, _, _ := unstructured.NestedStringSlice(status, "certificates")
+		ca = []byte(strings.Join(certs, "\n"))
+		if len(certs) == 0 {
+			if ca, err = secretKey(ctx, kube, ref.Namespace, ref.Name+strimziCASuffix, strimziCAKey); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if ref.User != "" {
+		switch auth {
+		case strimziAuthTLS:
+			if cert, err = secretKey(ctx, kube, ref.Namespace, ref.User, strimziUserCertKey); err != nil {
+				return nil, err
+			}
+			if key, err = secretKey(ctx, kube, ref.Namespace, ref.User, strimziUserKeyKey); err != nil {
+				return nil, err
+			}
+		case strimziAuthSCRAM:
+			pw, err := secretKey(ctx, kube, ref.Namespace, ref.User, strimziPasswordKey)
+			if err != nil {
+				return nil, err
+			}
+			sasl, err := SASLConf(ref.User, string(pw), scramSHA512)
+			if err != nil {
+				return nil, err
+			}
+			conf = MergeConf(conf, sasl)
+		default:
+			return nil, errors.Errorf(errFmtUserAuth, ref.Listener)
+		}
+	}
+	if tls {
+		tlsConf, err := TLSConf(ca, cert, key)
+		if err != nil {
+			return nil, err
+		}
+		conf = MergeConf(conf, tlsConf)
+	}
+	conf[ConfSecurityProtocol] = protocol(tls, conf[ConfSASLJAASConfig] != "")
+	return &Cluster{BootstrapServers: bootstrap, Conf: conf}, nil
+}
+
+func resolveCFK(ctx context.Context, kube client.Reader, ref v1alpha1.KafkaClusterReference) (*Cluster, error) {
+	k, err := getKafka(ctx, kube, cfkKafkaGVK, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	status, _, _ := unstructured.NestedMap(k.Object, "status", "listeners", ref.Listener)
+	if status == nil {
+		return nil, errors.Errorf(errFmtNoListener, ref.Listener)
+	}
+	bootstrap, _, _ := unstructured.NestedString(status, "internalEndpoint")
+	if bootstrap == "" {
+		bootstrap, _, _ = unstructured.NestedString(status, "externalEndpoint")
+	}
+	if i := strings.Index(bootstrap, endpointSchemeSep); i >= 0 {
+		bootstrap = bootstrap[i+len(endpointSchemeSep):]
+	}
+	if bootstrap == "" {
+		return nil, errors.Errorf(errFmtNoBootstrap, ref.Listener)
+	}
+	spec, _, _ := unstructured.NestedMap(k.Object, "spec", "listeners", ref.Listener)
+	if spec == nil {
+		spec = findListener(k.Object, ref.Listener, "spec", "listeners", cfkCustomListeners)
+	}
+	tls, _, _ := unstructured.NestedBool(status, "tls")
+
+	conf := map[string]string{}
+	if ref.User != "" {
+		auth, _, _ := unstructured.NestedString(spec, "authentication", "type")
+		users, _, _ := unstructured.NestedString(spec, "authentication", "jaasConfig", "secretRef")
+		if auth != cfkAuthPlain || users == "" {
+			return nil, errors.Errorf(errFmtUserAuth, ref.Listener)
+		}
+		data, err := secretKey(ctx, kube, ref.Namespace, users, cfkPlainUsersKey)
+		if err != nil {
+			return nil, err
+		}
+		passwords := map[string]string{}
+		if err := json.Unmarshal(data, &passwords); err != nil {
+			return nil, errors.Wrap(err, errParsePlainUsers)
+		}
+		pw, ok := passwords[ref.User]
+		if !ok {
+			return nil, errors.Errorf(errFmtUnknownUser, ref.User, ref.Listener)
+		}
+		if conf, err = SASLConf(ref.User, pw, defaultSASLMechanism); err != nil {
+			return nil, err
+		}
+	}
+	if tls {
+		ca, err := cfkCA(ctx, kube, k.Object, spec, ref.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		tlsConf, err := TLSConf(ca, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		conf = MergeConf(conf, tlsConf)
+	}
+	conf[ConfSecurityProtocol] = protocol(tls, conf[ConfSASLJAASConfig] != "")
+	return &Cluster{BootstrapServers: bootstrap, Conf: conf}, nil
+}
+
+// cfkCA returns the CA bundle of a CFK listener, which comes from the TLS
+// Secret of the listener or of the cluster, or from the CA CFK generates
+// certificates with.
+func cfkCA(ctx context.Context, kube client.Reader, kafka, listener map[string]interface{}, namespace string) ([]byte, error) {
+	name, _, _ := unstructured.NestedString(listener, "tls", "secretRef")
+	if name == "" {
+		name, _, _ = unstructured.NestedString(kafka, "spec", "tls", "secretRef")
+	}
+	if name != "" {
+		return secretKey(ctx, kube, namespace, name, cfkCAKey)
+	}
+	return secretKey(ctx, kube, namespace, cfkAutoCASecret, corev1.TLSCertKey)
+}
+
+func getKafka(ctx context.Context, kube client.Reader, gvk schema.GroupVersionKind, ref v1alpha1.KafkaClusterReference) (*unstructured.Unstructured, error) {
+	k := &unstructured.Unstructured{}
+	k.SetGroupVersionKind(gvk)
+	if err := kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, k); err != nil {
+		return nil, errors.Wrap(err, errGetKafka)
+	}
+	return k, nil
+}
+
+// findListener returns the listener with the supplied name from the list of
+// listeners at the supplied path. Older Strimzi versions identify listeners
+// by their type rather than their name.
+func findListener(obj map[string]interface{}, name string, path ...string) map[string]interface{} {
+	listeners, _, _ := unstructured.NestedSlice(obj, path...)
+	for _, l := range listeners {
+		m, ok := l.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if m["name"] == name || (m["name"] == nil && m["type"] == name) {
+			return m
+		}
+	}
+	return nil
+}
+
+func secretKey(ctx context.Context, kube client.Reader, namespace, name, key string) ([]byte, error) {
+	s := &corev1.Secret{}
+	if err := kube.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, s); err != nil {
+		return nil, errors.Wrap(err, errGetSecret)
+	}
+	v, ok := s.Data[key]
+	if !ok {
+		return nil, errors.Errorf(errFmtNoSecretKey, namespace, name, key)
+	}
+	return v, nil
+}
+
+func protocol(tls, sasl bool) string {
+	switch {
+	case tls && sasl:
+		return protocolSASLSSL
+	case tls:
+		return protocolSSL
+	case sasl:
+		return protocolSASLPlain
+	default:
+		return protocolPlaintext
+	}
+}
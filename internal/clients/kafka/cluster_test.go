@@ -0,0 +1,209 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafka
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+// objects returns a MockGetFn serving Kafka objects and Secrets by name.
+func objects(kafka map[string]interface{}, secrets map[string]map[string][]byte) test.MockGetFn {
+	return func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+		switch o := obj.(type) {
+		case *unstructured.Unstructured:
+			if kafka == nil {
+				return errors.New("boom")
+			}
+			o.Object = kafka
+		case *corev1.Secret:
+			data, ok := secrets[key.Name]
+			if !ok {
+				return errors.New("boom")
+			}
+			o.Data = data
+		}
+		return nil
+	}
+}
+
+func TestResolveCluster(t *testing.T) {
+	ca := selfSigned(t)
+	cert := selfSigned(t)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := pem.EncodeToMemory(&pem.Block{Type: pemPKCS8Key, Bytes: der})
+
+	strimzi := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"kafka": map[string]interface{}{
+				"listeners": []interface{}{
+					map[string]interface{}{"name": "plain", "tls": false},
+					map[string]interface{}{"name": "scram", "tls": true, "authentication": map[string]interface{}{"type": "scram-sha-512"}},
+					map[string]interface{}{"name": "mtls", "tls": true, "authentication": map[string]interface{}{"type": "tls"}},
+				},
+			},
+		},
+		"status": map[string]interface{}{
+			"listeners": []interface{}{
+				map[string]interface{}{"name": "plain", "bootstrapServers": "my-cluster-kafka-bootstrap.kafka.svc:9092"},
+				map[string]interface{}{"name": "scram", "bootstrapServers": "my-cluster-kafka-bootstrap.kafka.svc:9094", "certificates": []interface{}{string(ca)}},
+				map[string]interface{}{"name": "mtls", "bootstrapServers": "my-cluster-kafka-bootstrap.kafka.svc:9093"},
+			},
+		},
+	}
+	cfk := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"tls": map[string]interface{}{"secretRef": "kafka-tls"},
+			"listeners": map[string]interface{}{
+				"internal": map[string]interface{}{
+					"authentication": map[string]interface{}{
+						"type":       "plain",
+						"jaasConfig": map[string]interface{}{"secretRef": "credential"},
+					},
+				},
+			},
+		},
+		"status": map[string]interface{}{
+			"listeners": map[string]interface{}{
+				"internal": map[string]interface{}{"internalEndpoint": "SASL_SSL://kafka.confluent.svc.cluster.local:9071", "tls": true},
+			},
+		},
+	}
+	secrets := map[string]map[string][]byte{
+		"my-cluster-cluster-ca-cert": {"ca.crt": ca},
+		"alice":                      {"password": []byte("s3cr3t")},
+		"bob":                        {"user.crt": cert, "user.key": key},
+		"credential":                 {"plain-users.json": []byte(`{"kafka":"kafka-secret"}`)},
+		"kafka-tls":                  {"cacerts.pem": ca},
+	}
+
+	scram, _ := SASLConf("alice", "s3cr3t", "SCRAM-SHA-512")
+	plain, _ := SASLConf("kafka", "kafka-secret", "PLAIN")
+	caOnly, _ := TLSConf(ca, nil, nil)
+	mtls, _ := TLSConf(ca, cert, key)
+
+	type want struct {
+		cluster *Cluster
+		err     error
+	}
+	cases := map[string]struct {
+		reason string
+		kafka  map[string]interface{}
+		ref    v1alpha1.KafkaClusterReference
+		want   want
+	}{
+		"StrimziPlain": {
+			reason: "A Strimzi listener without TLS or authentication should use PLAINTEXT.",
+			kafka:  strimzi,
+			ref:    v1alpha1.KafkaClusterReference{Operator: v1alpha1.StrimziOperator, Namespace: "kafka", Name: "my-cluster", Listener: "plain"},
+			want: want{cluster: &Cluster{
+				BootstrapServers: "my-cluster-kafka-bootstrap.kafka.svc:9092",
+				Conf:             map[string]string{ConfSecurityProtocol: "PLAINTEXT"},
+			}},
+		},
+		"StrimziSCRAM": {
+			reason: "A Strimzi SCRAM user should be resolved along with the certificates of the listener.",
+			kafka:  strimzi,
+			ref:    v1alpha1.KafkaClusterReference{Operator: v1alpha1.StrimziOperator, Namespace: "kafka", Name: "my-cluster", Listener: "scram", User: "alice"},
+			want: want{cluster: &Cluster{
+				BootstrapServers: "my-cluster-kafka-bootstrap.kafka.svc:9094",
+				Conf:             MergeConf(scram, caOnly, map[string]string{ConfSecurityProtocol: "SASL_SSL"}),
+			}},
+		},
+		"StrimziTLSUser": {
+			reason: "A Strimzi TLS user should be resolved along with the CA of the cluster.",
+			kafka:  strimzi,
+			ref:    v1alpha1.KafkaClusterReference{Operator: v1alpha1.StrimziOperator, Namespace: "kafka", Name: "my-cluster", Listener: "mtls", User: "bob"},
+			want: want{cluster: &Cluster{
+				BootstrapServers: "my-cluster-kafka-bootstrap.kafka.svc:9093",
+				Conf:             MergeConf(mtls, map[string]string{ConfSecurityProtocol: "SSL"}),
+			}},
+		},
+		"StrimziUnsupportedUser": {
+			reason: "Users cannot be resolved for listeners without authentication.",
+			kafka:  strimzi,
+			ref:    v1alpha1.KafkaClusterReference{Operator: v1alpha1.StrimziOperator, Namespace: "kafka", Name: "my-cluster", Listener: "plain", User: "alice"},
+			want:   want{err: errors.Errorf(errFmtUserAuth, "plain")},
+		},
+		"StrimziUnknownListener": {
+			reason: "Unknown listeners should return an error.",
+			kafka:  strimzi,
+			ref:    v1alpha1.KafkaClusterReference{Operator: v1alpha1.StrimziOperator, Namespace: "kafka", Name: "my-cluster", Listener: "external"},
+			want:   want{err: errors.Errorf(errFmtNoListener, "external")},
+		},
+		"CFKPlainUser": {
+			reason: "A CFK PLAIN user should be resolved along with the CA of the cluster.",
+			kafka:  cfk,
+			ref:    v1alpha1.KafkaClusterReference{Operator: v1alpha1.CFKOperator, Namespace: "confluent", Name: "kafka", Listener: "internal", User: "kafka"},
+			want: want{cluster: &Cluster{
+				BootstrapServers: "kafka.confluent.svc.cluster.local:9071",
+				Conf:             MergeConf(plain, caOnly, map[string]string{ConfSecurityProtocol: "SASL_SSL"}),
+			}},
+		},
+		"CFKUnknownUser": {
+			reason: "Users missing from the PLAIN users of the listener should return an error.",
+			kafka:  cfk,
+			ref:    v1alpha1.KafkaClusterReference{Operator: v1alpha1.CFKOperator, Namespace: "confluent", Name: "kafka", Listener: "internal", User: "mallory"},
+			want:   want{err: errors.Errorf(errFmtUnknownUser, "mallory", "internal")},
+		},
+		"GetKafkaError": {
+			reason: "Errors getting the Kafka object should be returned.",
+			ref:    v1alpha1.KafkaClusterReference{Operator: v1alpha1.CFKOperator, Namespace: "confluent", Name: "kafka", Listener: "internal"},
+			want:   want{err: errors.Wrap(errors.New("boom"), errGetKafka)},
+		},
+		"UnsupportedOperator": {
+			reason: "Unknown operators should return an error.",
+			ref:    v1alpha1.KafkaClusterReference{Operator: "Koperator", Namespace: "kafka", Name: "kafka", Listener: "internal"},
+			want:   want{err: errors.Errorf(errFmtOperator, "Koperator")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := &test.MockClient{MockGet: objects(tc.kafka, secrets)}
+			got, err := ResolveCluster(context.Background(), kube, tc.ref)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nResolveCluster(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cluster, got); diff != "" {
+				t.Errorf("\n%s\nResolveCluster(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
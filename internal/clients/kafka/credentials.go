@@ -0,0 +1,133 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kafka turns Kafka credentials into client configurations.
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Keys of the credentials understood besides Kafka client configurations.
+const (
+	KeyUsername  = "username"
+	KeyPassword  = "password"
+	KeyMechanism = "mechanism"
+)
+
+// Kafka client configurations derived from credentials.
+const (
+	ConfSASLJAASConfig      = "sasl.jaas.config"
+	ConfSASLMechanism       = "sasl.mechanism"
+	ConfSecurityProtocol    = "security.protocol"
+	defaultSASLMechanism    = "PLAIN"
+	defaultSecurityProtocol = "SASL_SSL"
+)
+
+const (
+	errParseCredentials = "cannot parse credentials, they must be a JSON object of strings"
+	errNoPassword       = "a password is required along with the username"
+	errFmtMechanism     = "unsupported SASL mechanism %q, use PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512"
+)
+
+var jaasEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+var loginModules = map[string]string{
+	"PLAIN":         "org.apache.kafka.common.security.plain.PlainLoginModule",
+	"SCRAM-SHA-256": "org.apache.kafka.common.security.scram.ScramLoginModule",
+	"SCRAM-SHA-512": "org.apache.kafka.common.security.scram.ScramLoginModule",
+}
+
+// ParseCredentials returns the Kafka client configuration described by the
+// supplied credentials. Credentials are a JSON object whose keys containing a
+// dot, such as sasl.jaas.config or sasl.oauthbearer.token.endpoint.url, are
+// passed through as client configurations. A username and password are turned
+// into a sasl.jaas.config for the supplied mechanism, PLAIN by default. Empty
+// credentials return an empty configuration.
+func ParseCredentials(data []byte) (map[string]string, error) {
+	conf := map[string]string{}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return conf, nil
+	}
+
+	creds := map[string]string{}
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, errors.Wrap(err, errParseCredentials)
+	}
+	for k, v := range creds {
+		if strings.Contains(k, ".") {
+			conf[k] = v
+		}
+	}
+
+	username := creds[KeyUsername]
+	if username == "" {
+		return conf, nil
+	}
+	if creds[KeyPassword] == "" {
+		return nil, errors.New(errNoPassword)
+	}
+	sasl, err := SASLConf(username, creds[KeyPassword], creds[KeyMechanism])
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := conf[ConfSecurityProtocol]; !ok {
+		conf[ConfSecurityProtocol] = defaultSecurityProtocol
+	}
+	return MergeConf(conf, sasl), nil
+}
+
+// IsJSONObject returns whether the supplied credentials look like a JSON
+// object, as opposed to the opaque credentials earlier releases accepted.
+func IsJSONObject(data []byte) bool {
+	return strings.HasPrefix(strings.TrimSpace(string(data)), "{")
+}
+
+// SASLConf returns the sasl.mechanism and sasl.jaas.config client
+// configurations of the supplied username and password. The mechanism is
+// PLAIN when empty.
+func SASLConf(username, password, mechanism string) (map[string]string, error) {
+	if mechanism == "" {
+		mechanism = defaultSASLMechanism
+	}
+	module, ok := loginModules[mechanism]
+	if !ok {
+		return nil, errors.Errorf(errFmtMechanism, mechanism)
+	}
+	return map[string]string{
+		ConfSASLMechanism:  mechanism,
+		ConfSASLJAASConfig: fmt.Sprintf(`%s required username="%s" password="%s";`, module, jaasEscaper.Replace(username), jaasEscaper.Replace(password)),
+	}, nil
+}
+
+// MergeConf returns a new client configuration holding the keys of base
+// overridden by those of each override in turn.
+func MergeConf(base map[string]string, overrides ...map[string]string) map[string]string {
+	out := make(map[string]string, len(base))
+	for k, v := range base {
+		out[k] = v
+	}
+	for _, o := range overrides {
+		for k, v := range o {
+			out[k] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafka
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestParseCredentials(t *testing.T) {
+	type want struct {
+		conf map[string]string
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		data   string
+		want   want
+	}{
+		"Empty": {
+			reason: "Empty credentials should return an empty configuration.",
+			data:   "",
+			want:   want{conf: map[string]string{}},
+		},
+		"NotJSON": {
+			reason: "Credentials that are not a JSON object should be rejected.",
+			data:   "test",
+			want:   want{err: errors.Wrap(errors.New("invalid character 'e' in literal true (expecting 'r')"), errParseCredentials)},
+		},
+		"Passthrough": {
+			reason: "Client configurations should be passed through.",
+			data:   `{"sasl.mechanism":"OAUTHBEARER","sasl.oauthbearer.token.endpoint.url":"https://idp/token","ignored":"x"}`,
+			want: want{conf: map[string]string{
+				"sasl.mechanism":                      "OAUTHBEARER",
+				"sasl.oauthbearer.token.endpoint.url": "https://idp/token",
+			}},
+		},
+		"UsernamePassword": {
+			reason: "A username and password should produce a PLAIN sasl.jaas.config.",
+			data:   `{"username":"user","password":"p\"ss"}`,
+			want: want{conf: map[string]string{
+				"sasl.mechanism":    "PLAIN",
+				"security.protocol": "SASL_SSL",
+				"sasl.jaas.config":  `org.apache.kafka.common.security.plain.PlainLoginModule required username="user" password="p\"ss";`,
+			}},
+		},
+		"Scram": {
+			reason: "SCRAM mechanisms should use the SCRAM login module, and an explicit protocol should win.",
+			data:   `{"username":"user","password":"pass","mechanism":"SCRAM-SHA-512","security.protocol":"SASL_PLAINTEXT"}`,
+			want: want{conf: map[string]string{
+				"sasl.mechanism":    "SCRAM-SHA-512",
+				"security.protocol": "SASL_PLAINTEXT",
+				"sasl.jaas.config":  `org.apache.kafka.common.security.scram.ScramLoginModule required username="user" password="pass";`,
+			}},
+		},
+		"NoPassword": {
+			reason: "A username without a password should be rejected.",
+			data:   `{"username":"user"}`,
+			want:   want{err: errors.New(errNoPassword)},
+		},
+		"UnknownMechanism": {
+			reason: "Unknown SASL mechanisms should be rejected.",
+			data:   `{"username":"user","password":"pass","mechanism":"GSSAPI"}`,
+			want:   want{err: errors.Errorf(errFmtMechanism, "GSSAPI")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseCredentials([]byte(tc.data))
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParseCredentials(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.conf, got); diff != "" {
+				t.Errorf("\n%s\nParseCredentials(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestIsJSONObject(t *testing.T) {
+	cases := map[string]struct {
+		data string
+		want bool
+	}{
+		"Object": {data: ` {"username":"user"}`, want: true},
+		"Opaque": {data: "test\n", want: false},
+		"Empty":  {data: "", want: false},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := IsJSONObject([]byte(tc.data)); got != tc.want {
+				t.Errorf("IsJSONObject(%q): want %t, got %t", tc.data, tc.want, got)
+			}
+		})
+	}
+}
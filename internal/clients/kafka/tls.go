@@ -0,0 +1,162 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafka
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Kafka client configurations of inline PEM TLS material.
+const (
+	ConfTruststoreType         = "ssl.truststore.type"
+	ConfTruststoreCertificates = "ssl.truststore.certificates"
+	ConfKeystoreType           = "ssl.keystore.type"
+	ConfKeystoreCertChain      = "ssl.keystore.certificate.chain"
+	ConfKeystoreKey            = "ssl.keystore.key"
+
+	storeTypePEM = "PEM"
+	protocolSSL  = "SSL"
+)
+
+const (
+	pemCertificate   = "CERTIFICATE"
+	pemPKCS8Key      = "PRIVATE KEY"
+	pemPKCS8EncKey   = "ENCRYPTED PRIVATE KEY"
+	pemPKCS1Key      = "RSA PRIVATE KEY"
+	pemECKey         = "EC PRIVATE KEY"
+	pemEncryptedType = "Proc-Type"
+)
+
+const (
+	errNoCA         = "no CA certificate found"
+	errNoCert       = "no client certificate found"
+	errNoKey        = "no client private key found"
+	errKeyWithout   = "a client key requires a client certificate"
+	errParseKey     = "cannot parse client private key"
+	errEncryptedKey = "encrypted client private keys are not supported"
+	errMarshalKey   = "cannot convert client private key to PKCS #8"
+	errFmtPEMBlock  = "unexpected PEM block %q"
+)
+
+// TLSConf returns the inline PEM client configuration of the supplied CA
+// bundle and, when cert is not empty, client certificate chain and key.
+// Kafka only reads PKCS #8 keys, so PKCS #1 and EC keys are converted.
+func TLSConf(ca, cert, key []byte) (map[string]string, error) {
+	conf := map[string]string{}
+	certs, err := certificates(ca)
+	if err != nil {
+		return nil, err
+	}
+	if certs == "" {
+		return nil, errors.New(errNoCA)
+	}
+	conf[ConfTruststoreType] = storeTypePEM
+	conf[ConfTruststoreCertificates] = certs
+
+	if len(cert) == 0 {
+		if len(key) != 0 {
+			return nil, errors.New(errKeyWithout)
+		}
+		return conf, nil
+	}
+	chain, err := certificates(cert)
+	if err != nil {
+		return nil, err
+	}
+	if chain == "" {
+		return nil, errors.New(errNoCert)
+	}
+	k, err := pkcs8(key)
+	if err != nil {
+		return nil, err
+	}
+	conf[ConfKeystoreType] = storeTypePEM
+	conf[ConfKeystoreCertChain] = chain
+	conf[ConfKeystoreKey] = k
+	return conf, nil
+}
+
+// WithDefaultProtocol sets the security protocol of a client configuration
+// holding TLS material to SSL, unless a protocol is already set.
+func WithDefaultProtocol(conf map[string]string) map[string]string {
+	if _, ok := conf[ConfSecurityProtocol]; ok {
+		return conf
+	}
+	if _, ok := conf[ConfTruststoreCertificates]; !ok {
+		return conf
+	}
+	conf[ConfSecurityProtocol] = protocolSSL
+	return conf
+}
+
+// certificates returns the certificates of the supplied PEM data,
+// re-encoded one after the other.
+func certificates(data []byte) (string, error) {
+	var sb strings.Builder
+	for {
+		var b *pem.Block
+		b, data = pem.Decode(data)
+		if b == nil {
+			return sb.String(), nil
+		}
+		if b.Type != pemCertificate {
+			return "", errors.Errorf(errFmtPEMBlock, b.Type)
+		}
+		if _, err := x509.ParseCertificate(b.Bytes); err != nil {
+			return "", err
+		}
+		if err := pem.Encode(&sb, &pem.Block{Type: pemCertificate, Bytes: b.Bytes}); err != nil {
+			return "", err
+		}
+	}
+}
+
+// pkcs8 returns the supplied private key as a PEM encoded PKCS #8 key.
+func pkcs8(data []byte) (string, error) {
+	b, _ := pem.Decode(data)
+	if b == nil {
+		return "", errors.New(errNoKey)
+	}
+	if _, ok := b.Headers[pemEncryptedType]; ok || b.Type == pemPKCS8EncKey {
+		return "", errors.New(errEncryptedKey)
+	}
+
+	var key interface{}
+	var err error
+	switch b.Type {
+	case pemPKCS8Key:
+		return string(pem.EncodeToMemory(&pem.Block{Type: b.Type, Bytes: b.Bytes})), nil
+	case pemPKCS1Key:
+		key, err = x509.ParsePKCS1PrivateKey(b.Bytes)
+	case pemECKey:
+		key, err = x509.ParseECPrivateKey(b.Bytes)
+	default:
+		return "", errors.Errorf(errFmtPEMBlock, b.Type)
+	}
+	if err != nil {
+		return "", errors.Wrap(err, errParseKey)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", errors.Wrap(err, errMarshalKey)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: pemPKCS8Key, Bytes: der})), nil
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafka
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func selfSigned(t *testing.T) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "tarasque"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemCertificate, Bytes: der})
+}
+
+func TestTLSConf(t *testing.T) {
+	ca := selfSigned(t)
+	cert := selfSigned(t)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs1 := pem.EncodeToMemory(&pem.Block{Type: pemPKCS1Key, Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+	der, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8 := string(pem.EncodeToMemory(&pem.Block{Type: pemPKCS8Key, Bytes: der}))
+
+	type want struct {
+		conf map[string]string
+		err  error
+	}
+	cases := map[string]struct {
+		reason string
+		ca     []byte
+		cert   []byte
+		key    []byte
+		want   want
+	}{
+		"CAOnly": {
+			reason: "A CA bundle alone should only configure the truststore.",
+			ca:     ca,
+			want: want{conf: map[string]string{
+				ConfTruststoreType:         "PEM",
+				ConfTruststoreCertificates: string(ca),
+			}},
+		},
+		"MutualTLS": {
+			reason: "A client certificate and PKCS #1 key should configure the keystore with a PKCS #8 key.",
+			ca:     ca,
+			cert:   cert,
+			key:    pkcs1,
+			want: want{conf: map[string]string{
+				ConfTruststoreType:         "PEM",
+				ConfTruststoreCertificates: string(ca),
+				ConfKeystoreType:           "PEM",
+				ConfKeystoreCertChain:      string(cert),
+				ConfKeystoreKey:            pkcs8,
+			}},
+		},
+		"NoCA": {
+			reason: "A CA bundle is required.",
+			ca:     []byte("not a certificate"),
+			want:   want{err: errors.New(errNoCA)},
+		},
+		"KeyWithoutCert": {
+			reason: "A client key is useless without its certificate.",
+			ca:     ca,
+			key:    pkcs1,
+			want:   want{err: errors.New(errKeyWithout)},
+		},
+		"EncryptedKey": {
+			reason: "Encrypted keys should be rejected.",
+			ca:     ca,
+			cert:   cert,
+			key:    pem.EncodeToMemory(&pem.Block{Type: pemPKCS8EncKey, Bytes: []byte("x")}),
+			want:   want{err: errors.New(errEncryptedKey)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := TLSConf(tc.ca, tc.cert, tc.key)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nTLSConf(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.conf, got); diff != "" {
+				t.Errorf("\n%s\nTLSConf(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWithDefaultProtocol(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		conf   map[string]string
+		want   string
+	}{
+		"TLS": {
+			reason: "TLS material without a protocol should default to SSL.",
+			conf:   map[string]string{ConfTruststoreCertificates: "ca"},
+			want:   "SSL",
+		},
+		"SASL": {
+			reason: "An explicit protocol should be kept.",
+			conf:   map[string]string{ConfTruststoreCertificates: "ca", ConfSecurityProtocol: "SASL_SSL"},
+			want:   "SASL_SSL",
+		},
+		"NoTLS": {
+			reason: "Configurations without TLS material should be left as is.",
+			conf:   map[string]string{},
+			want:   "",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := WithDefaultProtocol(tc.conf)[ConfSecurityProtocol]
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nWithDefaultProtocol(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
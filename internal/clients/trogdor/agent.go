@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package trogdor contains a client for the REST API of Trogdor agents.
+package trogdor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+const (
+	errUnexpectedStatus = "unexpected response from agent %s: %s"
+)
+
+// AgentStatusWorkers represents the worker status as returned by Trogdor Agent API
+type AgentStatusWorkers struct {
+	State     string      `json:"state,omitempty"`
+	TaskID    string      `json:"taskId,omitempty"`
+	StartedMs int64       `json:"startedMs,omitempty"`
+	DoneMs    int64       `json:"doneMs,omitempty"`
+	Status    interface{} `json:"status,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// AgentStatusResponse encapsulates the response from the Trogdor Agent status endpoint
+type AgentStatusResponse struct {
+	ServerStartMs int64                         `json:"serverStartMs,omitempty"`
+	Workers       map[string]AgentStatusWorkers `json:"workers,omitempty"`
+}
+
+// A Client sends requests to the REST API of Trogdor agents. Every call
+// targets a single agent, identified by its host:port address.
+type Client struct {
+	http *resty.Client
+}
+
+// NewClient returns a Client that uses the supplied REST client.
+func NewClient(c *resty.Client) *Client {
+	return &Client{http: c}
+}
+
+// CreateWorker asks the agent at addr to start the worker described by body.
+func (c *Client) CreateWorker(addr string, body interface{}) error {
+	resp, err := c.http.NewRequest().
+		SetHeader("Accept", "application/json").
+		SetHeader("Content-Type", "application/json").
+		SetBody(body).Post(fmt.Sprintf("http://%s/agent/worker/create", addr))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return errors.Errorf(errUnexpectedStatus, addr, resp.Status())
+	}
+	return nil
+}
+
+// Status returns the state of every worker known by the agent at addr.
+func (c *Client) Status(addr string) (*AgentStatusResponse, error) {
+	resp, err := c.http.NewRequest().
+		SetHeader("Accept", "application/json").
+		Get(fmt.Sprintf("http://%s/agent/status", addr))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, errors.Errorf(errUnexpectedStatus, addr, resp.Status())
+	}
+	status := &AgentStatusResponse{}
+	if err := json.Unmarshal(resp.Body(), status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// StopWorker stops the given worker on the agent at addr. Unlike a deleted
+// worker, a stopped worker keeps reporting its last status.
+func (c *Client) StopWorker(addr string, workerID int64) error {
+	resp, err := c.http.NewRequest().
+		SetHeader("Accept", "application/json").
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string]int64{"workerId": workerID}).Put(fmt.Sprintf("http://%s/agent/worker/stop", addr))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return errors.Errorf(errUnexpectedStatus, addr, resp.Status())
+	}
+	return nil
+}
+
+// DeleteWorker stops the given worker on the agent at addr and forgets about
+// it. A worker the agent does not know, such as after the agent restarted, is
+// already deleted.
+func (c *Client) DeleteWorker(addr, workerID string) error {
+	resp, err := c.http.NewRequest().
+		SetHeader("Accept", "application/json").
+		Delete(fmt.Sprintf("http://%s/agent/worker?workerId=%s", addr, workerID))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK && resp.StatusCode() != http.StatusNotFound {
+		return errors.Errorf(errUnexpectedStatus, addr, resp.Status())
+	}
+	return nil
+}
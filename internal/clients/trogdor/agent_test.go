@@ -0,0 +1,88 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trogdor
+
+import (
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jarcoal/httpmock"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestClient(t *testing.T) {
+	httpClient := resty.New()
+	httpmock.ActivateNonDefault(httpClient.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", "http://healthy:8888/agent/worker/create", httpmock.NewStringResponder(200, "{}"))
+	httpmock.RegisterResponder("POST", "http://broken:8888/agent/worker/create", httpmock.NewStringResponder(400, "{}"))
+	httpmock.RegisterResponder("GET", "http://healthy:8888/agent/status", httpmock.NewStringResponder(200,
+		`{"serverStartMs": 1000, "workers": {"42": {"state": "RUNNING", "taskId": "t", "startedMs": 1001}}}`))
+	httpmock.RegisterResponder("GET", "http://broken:8888/agent/status", httpmock.NewStringResponder(500, ""))
+	httpmock.RegisterResponder("PUT", "http://healthy:8888/agent/worker/stop", httpmock.NewStringResponder(200, "{}"))
+	httpmock.RegisterResponder("PUT", "http://broken:8888/agent/worker/stop", httpmock.NewStringResponder(404, "{}"))
+	httpmock.RegisterResponder("DELETE", "http://healthy:8888/agent/worker", httpmock.NewStringResponder(200, "{}"))
+	httpmock.RegisterResponder("DELETE", "http://restarted:8888/agent/worker", httpmock.NewStringResponder(404, "{}"))
+	httpmock.RegisterResponder("DELETE", "http://broken:8888/agent/worker", httpmock.NewStringResponder(500, "{}"))
+
+	c := NewClient(httpClient)
+
+	if err := c.CreateWorker("healthy:8888", map[string]interface{}{}); err != nil {
+		t.Errorf("c.CreateWorker(...): unexpected error: %v", err)
+	}
+	err := c.CreateWorker("broken:8888", map[string]interface{}{})
+	if diff := cmp.Diff(errors.Errorf(errUnexpectedStatus, "broken:8888", "400"), err, test.EquateErrors()); diff != "" {
+		t.Errorf("c.CreateWorker(...): -want error, +got error:\n%s\n", diff)
+	}
+
+	status, err := c.Status("healthy:8888")
+	if err != nil {
+		t.Errorf("c.Status(...): unexpected error: %v", err)
+	}
+	want := &AgentStatusResponse{
+		ServerStartMs: 1000,
+		Workers:       map[string]AgentStatusWorkers{"42": {State: "RUNNING", TaskID: "t", StartedMs: 1001}},
+	}
+	if diff := cmp.Diff(want, status); diff != "" {
+		t.Errorf("c.Status(...): -want, +got:\n%s\n", diff)
+	}
+	if _, err := c.Status("broken:8888"); err == nil {
+		t.Errorf("c.Status(...): expected an error for a failing agent")
+	}
+
+	if err := c.StopWorker("healthy:8888", 42); err != nil {
+		t.Errorf("c.StopWorker(...): unexpected error: %v", err)
+	}
+	if err := c.StopWorker("broken:8888", 42); err == nil {
+		t.Errorf("c.StopWorker(...): expected an error for a failing agent")
+	}
+
+	if err := c.DeleteWorker("healthy:8888", "42"); err != nil {
+		t.Errorf("c.DeleteWorker(...): unexpected error: %v", err)
+	}
+	if err := c.DeleteWorker("restarted:8888", "42"); err != nil {
+		t.Errorf("c.DeleteWorker(...): a worker unknown to the agent should count as deleted: %v", err)
+	}
+	err = c.DeleteWorker("broken:8888", "42")
+	if diff := cmp.Diff(errors.Errorf(errUnexpectedStatus, "broken:8888", "500"), err, test.EquateErrors()); diff != "" {
+		t.Errorf("c.DeleteWorker(...): -want error, +got error:\n%s\n", diff)
+	}
+}
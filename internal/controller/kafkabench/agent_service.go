@@ -4,10 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"math/rand"
 	"net"
-	"net/http"
 	"os"
 	"reflect"
 	"strings"
@@ -18,6 +16,8 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/clients/kafka"
+	"github.com/nachomdo/tarasque/internal/clients/trogdor"
 )
 
 const (
@@ -29,7 +29,9 @@ const (
 var (
 	agentServiceURL  = getEnvOrDefault("SERVICE_URL", defaultAgentServiceURL)
 	agentServicePort = getEnvOrDefault("SERVICE_PORT", defaultAgentServicePort)
-	sanitizeFields   = []string{"providerConfigRef", "forProvider", "deletionPolicy"}
+	sanitizeFields   = []string{"consumerTopics"}
+
+	errRawSpecClass = errors.New("rawSpec must set the class of the Trogdor task")
 )
 
 type resolver interface {
@@ -74,71 +76,104 @@ func sanitizeWorkerTask(wt *WorkerTask) (map[string]interface{}, error) {
 		return nil, err
 	}
 
+	if wt.Spec.RawSpec != nil {
+		rawSpec := map[string]interface{}{}
+		if err := json.Unmarshal(wt.Spec.RawSpec.Raw, &rawSpec); err != nil {
+			return nil, err
+		}
+		if class, _ := rawSpec["class"].(string); class == "" {
+			return nil, errRawSpecClass
+		}
+		rawSpec["startMs"] = wt.Spec.StartMs
+		wtMap["spec"] = rawSpec
+		wtMap["workerId"] = wt.WorkerID
+		return wtMap, nil
+	}
+
 	wtSpec := wtMap["spec"].(map[string]interface{})
 	for _, field := range sanitizeFields {
 		delete(wtSpec, field)
 	}
 
 	if wt.Spec.Class == consumerWorkload {
-		keys := make([]string, 0, len(wt.Spec.ActiveTopics))
-		for k := range wt.Spec.ActiveTopics {
-			if k != "" {
-				keys = append(keys, k)
-			}
-		}
-		wtSpec["activeTopics"] = keys
+		wtSpec["activeTopics"] = consumerActiveTopics(wt.Spec.KafkaBenchParameters)
 	}
 	wtMap["workerId"] = wt.WorkerID
 	return wtMap, nil
 }
 
+// consumerActiveTopics flattens the topics of a consumer bench into the list
+// expected by ConsumeBenchSpec. ConsumerTopics take precedence since they are
+// the only way to express partition ranges.
+func consumerActiveTopics(spec v1alpha1.KafkaBenchParameters) []string {
+	if len(spec.ConsumerTopics) > 0 {
+		topics := make([]string, 0, len(spec.ConsumerTopics))
+		for _, t := range spec.ConsumerTopics {
+			topics = append(topics, string(t))
+		}
+		return topics
+	}
+	keys := make([]string, 0, len(spec.ActiveTopics))
+	for k := range spec.ActiveTopics {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
 // TrogdorAgentService provides access to the Trogdor Agent REST API
 type TrogdorAgentService struct {
-	client      *resty.Client
+	client      *trogdor.Client
 	svcResolver resolver
-}
-
-// AgentStatusWorkers represents the worker status as returned by Trogdor Agent API
-type AgentStatusWorkers struct {
-	State     string      `json:"state,omitempty"`
-	TaskID    string      `json:"taskId,omitempty"`
-	StartedMs int64       `json:"startedMs,omitempty"`
-	DoneMs    int64       `json:"doneMs,omitempty"`
-	Status    interface{} `json:"status,omitempty"`
-	Error     string      `json:"error,omitempty"`
-}
-
-// AgentStatusResponse encapsulates the response from the Trogdor Agent status endpoint
-type AgentStatusResponse struct {
-	ServerStartMs int64                         `json:"serverStartMs,omitempty"`
-	Workers       map[string]AgentStatusWorkers `json:"workers,omitempty"`
+	// credentials are Kafka client configurations merged into the
+	// commonClientConf of each worker task.
+	credentials map[string]string
 }
 
 // NewTrogdorService returns a new instance of Trogdor Service
 func NewTrogdorService() *TrogdorAgentService {
 	return &TrogdorAgentService{
-		client:      resty.New(),
+		client:      trogdor.NewClient(resty.New()),
 		svcResolver: &kubeResolver{},
 	}
 }
 
+// WithCredentials makes the service merge the supplied Kafka client
+// configurations into the commonClientConf of the worker tasks it creates.
+func (tas *TrogdorAgentService) WithCredentials(creds map[string]string) *TrogdorAgentService {
+	tas.credentials = creds
+	return tas
+}
+
 func newTrogdorServiceWithRestClient(httpClient *resty.Client, svcResolver resolver) *TrogdorAgentService {
 	return &TrogdorAgentService{
-		client:      httpClient,
+		client:      trogdor.NewClient(httpClient),
 		svcResolver: svcResolver,
 	}
 }
 
-// CreateWorkerTask initiates a new worker task on Trogdor agents
-func (tas *TrogdorAgentService) CreateWorkerTask(spec v1alpha1.KafkaBenchSpec) (*WorkerTask, error) {
+// CreateWorkerTask initiates a new worker task on Trogdor agents, starting
+// now.
+func (tas *TrogdorAgentService) CreateWorkerTask(spec v1alpha1.KafkaBenchParameters) (*WorkerTask, error) {
+	return tas.CreateWorkerTaskAt(spec, time.Now().UnixMilli())
+}
+
+// CreateWorkerTaskAt initiates a new worker task on Trogdor agents, starting
+// at the supplied time in milliseconds since the epoch. Credentials and TLS
+// material are merged into a copy of the client configuration, so they never
+// make it back into the supplied spec.
+func (tas *TrogdorAgentService) CreateWorkerTaskAt(spec v1alpha1.KafkaBenchParameters, startMs int64) (*WorkerTask, error) {
+	if len(tas.credentials) > 0 && spec.RawSpec == nil && spec.Class != externalCommandWorkload {
+		spec.CommonClientConf = kafka.WithDefaultProtocol(kafka.MergeConf(spec.CommonClientConf, tas.credentials))
+	}
 	//nolint
-	payload := WorkerTask{Spec: WorkerTaskSpec{spec, time.Now().UnixMilli()}, WorkerID: rand.Int63(), TaskID: uuid.New().String()}
+	payload := WorkerTask{Spec: WorkerTaskSpec{spec, startMs}, WorkerID: rand.Int63(), TaskID: uuid.New().String()}
 
 	body, err := sanitizeWorkerTask(&payload)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Printf("Creating: %+v \n", body)
 	addrs, err := tas.svcResolver.resolveHeadlessService()
 	if err != nil {
 		return nil, err
@@ -148,19 +183,7 @@ func (tas *TrogdorAgentService) CreateWorkerTask(spec v1alpha1.KafkaBenchSpec) (
 	for _, addr := range addrs {
 		endpoint := addr
 		g.Go(func() error {
-			resp, err := tas.client.NewRequest().
-				SetHeader("Accept", "application/json").
-				SetHeader("Content-Type", "application/json").
-				SetBody(body).Post(fmt.Sprintf("http://%s/agent/worker/create", endpoint))
-
-			if err != nil {
-				return err
-			}
-			fmt.Printf("Response: %v \n", string(resp.Body()))
-			if resp.StatusCode() != http.StatusOK || err != nil {
-				return err
-			}
-			return nil
+			return tas.client.CreateWorker(endpoint, body)
 		})
 	}
 	if err := g.Wait(); err != nil {
@@ -170,31 +193,48 @@ func (tas *TrogdorAgentService) CreateWorkerTask(spec v1alpha1.KafkaBenchSpec) (
 }
 
 // CollectWorkerTaskResult checks the status of a given workerID in Trogdor agents
-func (tas *TrogdorAgentService) CollectWorkerTaskResult(workerID string) (*AgentStatusWorkers, error) {
+func (tas *TrogdorAgentService) CollectWorkerTaskResult(workerID string) (*trogdor.AgentStatusWorkers, error) {
+	workerStatus, err := tas.CollectWorkerStatus(workerID)
+	if err != nil {
+		return nil, err
+	}
+	if workerStatus.Error != "" {
+		return nil, errors.New(workerStatus.Error)
+	}
+	return workerStatus, nil
+}
+
+// CollectWorkerStatus returns the status of a given workerID in Trogdor
+// agents as reported, including any error raised by the worker.
+func (tas *TrogdorAgentService) CollectWorkerStatus(workerID string) (*trogdor.AgentStatusWorkers, error) {
 	addrs, err := tas.svcResolver.resolveHeadlessService()
 	if err != nil || len(addrs) == 0 {
 		return nil, errors.New("non resolvable address returned")
 	}
 	//nolint
 	idx := rand.Int() % len(addrs)
-	resp, err := tas.client.NewRequest().
-		SetHeader("Accept", "application/json").
-		Get(fmt.Sprintf("http://%s/agent/status", addrs[idx]))
-
-	if resp.StatusCode() != http.StatusOK || err != nil {
-		return nil, err
-	}
-	agentStatusResponse := AgentStatusResponse{}
-	if err := json.Unmarshal(resp.Body(), &agentStatusResponse); err != nil {
+	agentStatusResponse, err := tas.client.Status(addrs[idx])
+	if err != nil {
 		return nil, err
 	}
 	workerStatus := agentStatusResponse.Workers[workerID]
-	if workerStatus.Error != "" {
-		return nil, errors.New(workerStatus.Error)
-	}
 	return &workerStatus, nil
 }
 
+// StopWorkerTask stops a given worker in all Trogdor agents
+func (tas *TrogdorAgentService) StopWorkerTask(workerID int64) error {
+	addrs, err := tas.svcResolver.resolveHeadlessService()
+	if err != nil {
+		return errors.New("non resolvable address returned")
+	}
+	for _, addr := range addrs {
+		if err := tas.client.StopWorker(addr, workerID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // DeleteWorkerTask removes a given worker in Trogdor agents
 func (tas *TrogdorAgentService) DeleteWorkerTask(workerID string) error {
 	addrs, err := tas.svcResolver.resolveHeadlessService()
@@ -202,11 +242,7 @@ func (tas *TrogdorAgentService) DeleteWorkerTask(workerID string) error {
 		return errors.New("non resolvable address returned")
 	}
 	for _, addr := range addrs {
-		_, err = tas.client.NewRequest().
-			SetHeader("Accept", "application/json").
-			Delete(fmt.Sprintf("http://%s/agent/worker?workerId=%s", addr, workerID))
-
-		if err != nil {
+		if err := tas.client.DeleteWorker(addr, workerID); err != nil {
 			return err
 		}
 	}
@@ -1,6 +1,7 @@
 package kafkabench
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"testing"
@@ -9,6 +10,10 @@ import (
 	"github.com/go-resty/resty/v2"
 	"github.com/google/go-cmp/cmp"
 	"github.com/jarcoal/httpmock"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/clients/trogdor"
 )
 
 type mockResolver struct {
@@ -29,9 +34,9 @@ func TestCollectWorkerTaskResult(t *testing.T) {
 
 	httpmock.RegisterResponder("GET", agentServiceURL+"/agent/status",
 		func(req *http.Request) (*http.Response, error) {
-			statusResponse := AgentStatusResponse{
+			statusResponse := trogdor.AgentStatusResponse{
 				ServerStartMs: 1000,
-				Workers: map[string]AgentStatusWorkers{
+				Workers: map[string]trogdor.AgentStatusWorkers{
 					"task-with-error-no-status": {
 						State:     "DONE",
 						TaskID:    "1",
@@ -68,13 +73,13 @@ func TestCollectWorkerTaskResult(t *testing.T) {
 		},
 	)
 	cases := map[string]struct {
-		status *AgentStatusWorkers
+		status *trogdor.AgentStatusWorkers
 		err    error
 	}{
 		"task-with-error-no-status":  {status: nil, err: errors.New("worker expired")},
 		"task-with-status-and-error": {status: nil, err: errors.New("Unable to create topic(s): mytopic1, mytopic2, mytopic3, mytopic4, mytopic5after 3 attempt(s)")},
 		"task-with-results": {
-			status: &AgentStatusWorkers{
+			status: &trogdor.AgentStatusWorkers{
 				State:     "DONE",
 				TaskID:    "3",
 				StartedMs: 1649460862398,
@@ -105,3 +110,135 @@ func TestCollectWorkerTaskResult(t *testing.T) {
 
 	}
 }
+
+func TestSanitizeWorkerTask(t *testing.T) {
+	cases := map[string]struct {
+		spec v1alpha1.KafkaBenchParameters
+		want interface{}
+	}{
+		"producer-keeps-topic-map": {
+			spec: v1alpha1.KafkaBenchParameters{
+				Class:        producerWorkload,
+				ActiveTopics: map[string]v1alpha1.KafkaTopics{"test[1-5]": {NumPartitions: 10, ReplicationFactor: 3}},
+			},
+			want: map[string]interface{}{
+				"test[1-5]": map[string]interface{}{"numPartitions": float64(10), "replicationFactor": float64(3)},
+			},
+		},
+		"consumer-flattens-topic-map": {
+			spec: v1alpha1.KafkaBenchParameters{
+				Class:        consumerWorkload,
+				ActiveTopics: map[string]v1alpha1.KafkaTopics{"test[1-5]": {}},
+			},
+			want: []string{"test[1-5]"},
+		},
+		"consumer-prefers-consumer-topics": {
+			spec: v1alpha1.KafkaBenchParameters{
+				Class:          consumerWorkload,
+				ActiveTopics:   map[string]v1alpha1.KafkaTopics{"test[1-5]": {}},
+				ConsumerTopics: []v1alpha1.ConsumerTopic{"test1:[0-3]", "test2:4"},
+			},
+			want: []string{"test1:[0-3]", "test2:4"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := sanitizeWorkerTask(&WorkerTask{Spec: WorkerTaskSpec{KafkaBenchParameters: tc.spec}})
+			if err != nil {
+				t.Fatalf("sanitizeWorkerTask(...): unexpected error: %v", err)
+			}
+			spec := got["spec"].(map[string]interface{})
+			if _, ok := spec["consumerTopics"]; ok {
+				t.Errorf("sanitizeWorkerTask(...): consumerTopics should not be sent to the agents")
+			}
+			if diff := cmp.Diff(tc.want, spec["activeTopics"]); diff != "" {
+				t.Errorf("sanitizeWorkerTask(...): -want activeTopics, +got activeTopics:\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestSanitizeWorkerTaskRawSpec(t *testing.T) {
+	cases := map[string]struct {
+		raw  string
+		want map[string]interface{}
+		err  error
+	}{
+		"raw-spec-sent-verbatim": {
+			raw: `{"class": "org.example.CustomWorkloadSpec", "durationMs": 1000, "custom": {"nested": [1, 2]}}`,
+			want: map[string]interface{}{
+				"taskId":   "task",
+				"workerId": int64(42),
+				"spec": map[string]interface{}{
+					"class":      "org.example.CustomWorkloadSpec",
+					"durationMs": float64(1000),
+					"custom":     map[string]interface{}{"nested": []interface{}{float64(1), float64(2)}},
+					"startMs":    int64(1649460862398),
+				},
+			},
+		},
+		"raw-spec-without-class": {
+			raw: `{"durationMs": 1000}`,
+			err: errRawSpecClass,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			wt := &WorkerTask{
+				TaskID:   "task",
+				WorkerID: 42,
+				Spec: WorkerTaskSpec{
+					KafkaBenchParameters: v1alpha1.KafkaBenchParameters{RawSpec: &runtime.RawExtension{Raw: []byte(tc.raw)}},
+					StartMs:              1649460862398,
+				},
+			}
+			got, err := sanitizeWorkerTask(wt)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("sanitizeWorkerTask(...): -want error, +got error:\n%s\n", diff)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("sanitizeWorkerTask(...): -want, +got:\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestCreateWorkerTaskCredentials(t *testing.T) {
+	httpClient := resty.New()
+	svcResolver := &mockResolver{[]string{defaultAgentServiceName}, nil}
+	creds := map[string]string{"sasl.jaas.config": "secret", "sasl.mechanism": "PLAIN"}
+	client := newTrogdorServiceWithRestClient(httpClient, svcResolver).WithCredentials(creds)
+	httpmock.ActivateNonDefault(httpClient.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	var sent map[string]interface{}
+	httpmock.RegisterResponder("POST", agentServiceURL+"/agent/worker/create",
+		func(req *http.Request) (*http.Response, error) {
+			body := map[string]interface{}{}
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				return nil, err
+			}
+			sent = body["spec"].(map[string]interface{})["commonClientConf"].(map[string]interface{})
+			return httpmock.NewStringResponse(200, "{}"), nil
+		},
+	)
+
+	spec := v1alpha1.KafkaBenchParameters{
+		Class:            producerWorkload,
+		CommonClientConf: map[string]string{"sasl.mechanism": "SCRAM-SHA-512", "acks": "all"},
+	}
+	if _, err := client.CreateWorkerTask(spec); err != nil {
+		t.Fatalf("client.CreateWorkerTask(...): unexpected error: %v", err)
+	}
+
+	want := map[string]interface{}{"sasl.jaas.config": "secret", "sasl.mechanism": "PLAIN", "acks": "all"}
+	if diff := cmp.Diff(want, sent); diff != "" {
+		t.Errorf("client.CreateWorkerTask(...): credentials should be merged into commonClientConf: -want, +got:\n%s\n", diff)
+	}
+	unchanged := map[string]string{"sasl.mechanism": "SCRAM-SHA-512", "acks": "all"}
+	if diff := cmp.Diff(unchanged, spec.CommonClientConf); diff != "" {
+		t.Errorf("client.CreateWorkerTask(...): the supplied spec should not be modified: -want, +got:\n%s\n", diff)
+	}
+}
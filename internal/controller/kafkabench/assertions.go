@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkabench
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/assertion"
+	"github.com/nachomdo/tarasque/internal/benches"
+)
+
+const (
+	errAssertTimedOut  = "the bench timed out before its assertions could be evaluated"
+	errAssertFailed    = "the worker of the bench failed before its assertions could be evaluated"
+	errFmtAssertFailed = "%d of %d assertions failed: %s"
+)
+
+// assert evaluates the assertions of a bench once it is done, and sets its
+// Passed condition accordingly. The assertions of a bench that timed out or
+// whose worker failed do not pass.
+func (c *external) assert(cr bench) {
+	exprs := cr.GetBenchSpec().Assertions
+	if len(exprs) == 0 {
+		return
+	}
+	obs := &cr.GetBenchStatus().AtProvider
+	switch {
+	case obs.TaskStatus == taskStatusTimedOut:
+		obs.Assertions = nil
+		cr.SetConditions(v1alpha1.AssertionsFailed(errAssertTimedOut))
+	case obs.TaskStatus == taskStatusFailed:
+		obs.Assertions = nil
+		cr.SetConditions(v1alpha1.AssertionsFailed(errAssertFailed))
+	case obs.TaskStatus == taskStatusDone && benches.Completed(*obs):
+		obs.Assertions = assertion.Results(exprs, assertion.Metrics(effectiveParameters(cr).Class, *obs))
+		failed := assertion.Failed(obs.Assertions)
+		c.log.Debug("Evaluated assertions", "name", cr.GetName(), "failed", failed)
+		if len(failed) > 0 {
+			cr.SetConditions(v1alpha1.AssertionsFailed(fmt.Sprintf(errFmtAssertFailed, len(failed), len(exprs), strings.Join(failed, ", "))))
+			return
+		}
+		cr.SetConditions(v1alpha1.AssertionsPassed())
+	}
+}
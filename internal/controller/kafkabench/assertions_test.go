@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkabench
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+func TestAssert(t *testing.T) {
+	newBench := func(status string, p99 int64, segs int) *v1alpha1.KafkaBench {
+		cr := &v1alpha1.KafkaBench{Spec: v1alpha1.KafkaBenchSpec{
+			KafkaBenchParameters: v1alpha1.KafkaBenchParameters{Class: producerWorkload},
+			Assertions:           []string{"producer.p99LatencyMs < 50", "producer.totalSent >= 1000"},
+		}}
+		cr.Status.AtProvider.TaskStatus = status
+		cr.Status.AtProvider.ProducerStats = v1alpha1.ProducerBenchResultStats{TotalSent: 1000, P99LatencyMs: p99}
+		cr.Status.AtProvider.Segments = make([]v1alpha1.LoadSegment, segs)
+		return cr
+	}
+	type want struct {
+		condition  xpv1.Condition
+		assertions []v1alpha1.AssertionResult
+	}
+	cases := map[string]struct {
+		reason string
+		cr     *v1alpha1.KafkaBench
+		want   want
+	}{
+		"Passed": {
+			reason: "A done bench whose assertions hold should pass.",
+			cr:     newBench(taskStatusDone, 12, 0),
+			want: want{
+				condition: v1alpha1.AssertionsPassed(),
+				assertions: []v1alpha1.AssertionResult{
+					{Expression: "producer.p99LatencyMs < 50", Passed: true, Message: "producer.p99LatencyMs is 12"},
+					{Expression: "producer.totalSent >= 1000", Passed: true, Message: "producer.totalSent is 1000"},
+				},
+			},
+		},
+		"Failed": {
+			reason: "A done bench with an assertion that does not hold should fail.",
+			cr:     newBench(taskStatusDone, 62, 0),
+			want: want{
+				condition: v1alpha1.AssertionsFailed("1 of 2 assertions failed: producer.p99LatencyMs < 50"),
+				assertions: []v1alpha1.AssertionResult{
+					{Expression: "producer.p99LatencyMs < 50", Message: "producer.p99LatencyMs is 62"},
+					{Expression: "producer.totalSent >= 1000", Passed: true, Message: "producer.totalSent is 1000"},
+				},
+			},
+		},
+		"TimedOut": {
+			reason: "A bench that timed out should fail without evaluating its assertions.",
+			cr:     newBench(taskStatusTimedOut, 12, 0),
+			want:   want{condition: v1alpha1.AssertionsFailed(errAssertTimedOut)},
+		},
+		"SegmentsLeft": {
+			reason: "Assertions should not be evaluated before the last segment of a bench is done.",
+			cr:     newBench(taskStatusDone, 12, 2),
+			want:   want{condition: xpv1.Condition{Type: v1alpha1.TypePassed, Status: "Unknown"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{log: logging.NewNopLogger()}
+			e.assert(tc.cr)
+			got := want{condition: tc.cr.GetCondition(v1alpha1.TypePassed), assertions: tc.cr.Status.AtProvider.Assertions}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{}), test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.assert(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
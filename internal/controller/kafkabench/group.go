@@ -0,0 +1,55 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkabench
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+const (
+	// taskStatusWaiting is the task status of a bench of a group that waits
+	// for the other members of its group.
+	taskStatusWaiting = "WAITING"
+
+	errFmtStartMs = "cannot parse the %s annotation"
+)
+
+// holding returns whether the supplied bench belongs to a group that has yet
+// to agree on the start of its members.
+func holding(cr bench) bool {
+	a := cr.GetAnnotations()
+	_, grouped := a[v1alpha1.AnnotationKeyGroup]
+	_, agreed := a[v1alpha1.AnnotationKeyStartMs]
+	return grouped && !agreed
+}
+
+// startMs returns when the task of the supplied bench starts, in milliseconds
+// since the epoch: the start agreed by its group, or when it is due.
+func startMs(cr bench) (int64, error) {
+	v, ok := cr.GetAnnotations()[v1alpha1.AnnotationKeyStartMs]
+	if !ok {
+		start, _, err := due(cr, time.Now())
+		return start.UnixMilli(), err
+	}
+	ms, err := strconv.ParseInt(v, 10, 64)
+	return ms, errors.Wrapf(err, errFmtStartMs, v1alpha1.AnnotationKeyStartMs)
+}
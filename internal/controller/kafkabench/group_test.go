@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkabench
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jarcoal/httpmock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+func TestGroup(t *testing.T) {
+	httpClient := resty.New()
+	svc := newTrogdorServiceWithRestClient(httpClient, &mockResolver{[]string{defaultAgentServiceName}, nil})
+	httpmock.ActivateNonDefault(httpClient.GetClient())
+	defer httpmock.DeactivateAndReset()
+	var startMs int64
+	httpmock.RegisterResponder("POST", agentServiceURL+"/agent/worker/create", func(req *http.Request) (*http.Response, error) {
+		task := struct {
+			Spec struct {
+				StartMs int64 `json:"startMs"`
+			} `json:"spec"`
+		}{}
+		if err := json.NewDecoder(req.Body).Decode(&task); err != nil {
+			return nil, err
+		}
+		startMs = task.Spec.StartMs
+		return httpmock.NewStringResponse(200, "{}"), nil
+	})
+
+	kube := &test.MockClient{MockList: test.NewMockListFn(nil)}
+	e := external{
+		kube:       kube,
+		service:    svc,
+		queue:      &benchQueue{reserved: map[types.UID]time.Time{}},
+		guardrails: map[string]*v1alpha1.Guardrails{`ProviderConfig "default"`: {MaxDurationMs: 60000}},
+		log:        logging.NewNopLogger(),
+	}
+	cr := &v1alpha1.KafkaBench{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "mixed-producer",
+			UID:         "mixed-producer",
+			Annotations: map[string]string{v1alpha1.AnnotationKeyGroup: "mixed"},
+		},
+		Spec: v1alpha1.KafkaBenchSpec{KafkaBenchParameters: v1alpha1.KafkaBenchParameters{Class: producerWorkload, DurationMs: 100000000}},
+	}
+
+	// Benches of a group are validated before they wait for their group.
+	if _, err := e.Observe(context.TODO(), cr); err == nil {
+		t.Errorf("e.Observe(...): benches exceeding their guardrails should not wait for their group")
+	}
+
+	cr.Spec.DurationMs = 60000
+	got, err := e.Observe(context.TODO(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, got); diff != "" {
+		t.Errorf("e.Observe(...): a bench waiting for its group should not be created: -want, +got:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(v1alpha1.WaitingForGroup("mixed"), cr.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+		t.Errorf("e.Observe(...): -want condition, +got condition:\n%s\n", diff)
+	}
+	if !newQueueEntry(cr).running {
+		t.Errorf("e.Observe(...): a bench waiting for its group should keep its place in the agent pool")
+	}
+
+	// The group agreed on the start of its members.
+	cr.Annotations[v1alpha1.AnnotationKeyStartMs] = "1654048800000"
+	got, err = e.Observe(context.TODO(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(managed.ExternalObservation{ConnectionDetails: managed.ConnectionDetails{}}, got); diff != "" {
+		t.Errorf("e.Observe(...): a bench whose group agreed on its start should be created: -want, +got:\n%s\n", diff)
+	}
+	if _, err := e.Create(context.TODO(), cr); err != nil {
+		t.Fatalf("e.Create(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(int64(1654048800000), startMs); diff != "" {
+		t.Errorf("e.Create(...): the task should start when agreed by the group: -want, +got:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(&metav1.Time{Time: time.UnixMilli(1654048800000)}, cr.Status.AtProvider.StartTime); diff != "" {
+		t.Errorf("e.Create(...): -want start time, +got start time:\n%s\n", diff)
+	}
+}
+
+func TestStartMs(t *testing.T) {
+	cr := &v1alpha1.KafkaBench{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{v1alpha1.AnnotationKeyStartMs: "soon"}}}
+	if _, err := startMs(cr); err == nil {
+		t.Errorf("startMs(...): starts that are not milliseconds since the epoch should be reported")
+	}
+	cr.SetAnnotations(nil)
+	before := time.Now().UnixMilli()
+	if ms, err := startMs(cr); err != nil || ms < before {
+		t.Errorf("startMs(...): benches without an agreed start should start now, got %d, %v", ms, err)
+	}
+}
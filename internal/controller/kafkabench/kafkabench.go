@@ -18,17 +18,27 @@ package kafkabench
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
+	"regexp"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
@@ -38,25 +48,62 @@ import (
 
 	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
 	apisv1alpha1 "github.com/nachomdo/tarasque/apis/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/benches"
+	"github.com/nachomdo/tarasque/internal/clients/kafka"
+	"github.com/nachomdo/tarasque/internal/clients/trogdor"
+	"github.com/nachomdo/tarasque/internal/controller/kafkatarget"
+	"github.com/nachomdo/tarasque/internal/defaults"
+	"github.com/nachomdo/tarasque/internal/loadprofile"
+	"github.com/nachomdo/tarasque/internal/redact"
+	"github.com/nachomdo/tarasque/internal/validation"
 )
 
 const (
-	roundTripWorkload = "org.apache.kafka.trogdor.workload.RoundTripWorkloadSpec"
-	producerWorkload  = "org.apache.kafka.trogdor.workload.ProduceBenchSpec"
-	consumerWorkload  = "org.apache.kafka.trogdor.workload.ConsumeBenchSpec"
-	errNotKafkaBench  = "managed resource is not a KafkaBench custom resource"
+	roundTripWorkload = v1alpha1.RoundTripWorkloadClass
+	producerWorkload  = v1alpha1.ProduceBenchClass
+	consumerWorkload  = v1alpha1.ConsumeBenchClass
+
+	externalCommandWorkload = v1alpha1.ExternalCommandClass
+
+	errNotKafkaBench  = "managed resource is not a KafkaBench or NamespacedKafkaBench custom resource"
 	errTrackPCUsage   = "cannot track ProviderConfig usage"
 	errGetPC          = "cannot get ProviderConfig"
 	errGetCreds       = "cannot get credentials"
+	errGetBenchCreds  = "cannot get bench credentials"
+	errGetTLS         = "cannot get bench TLS configuration"
+	errResolveCluster = "cannot resolve Kafka cluster"
+	errConnection     = "invalid connection to Kafka"
+	errTrackTarget    = "cannot track KafkaTarget usage"
+	errGetTarget      = "cannot get KafkaTarget"
+	errGetTargetCreds = "cannot get KafkaTarget credentials"
+	errNoCommand      = "an ExternalCommandSpec workload requires a command"
+	errGuardrails     = "bench exceeds its guardrails"
 
 	errNewClient = "cannot create new Service"
+
+	defaultCAKey = "ca.crt"
 )
 
+var exitCodeRegexp = regexp.MustCompile(`exited with return code (-?\d+)`)
+
+// approvals tells whether the guardrails approvals of benches are trusted.
+var approvals atomic.Bool
+
+// SetGuardrailsApprovals sets whether the guardrails approvals of benches are
+// trusted. They must only be trusted when the webhook checking who approves
+// benches runs, as anyone allowed to annotate a bench could approve it
+// otherwise.
+func SetGuardrailsApprovals(trusted bool) {
+	approvals.Store(trusted)
+}
+
 // A NoOpService does nothing.
 type NoOpService struct{}
 
 var (
-	newNoOpService = func(_ []byte) (*TrogdorAgentService, error) { return NewTrogdorService(), nil }
+	newTrogdorAgentService = func(creds map[string]string) (*TrogdorAgentService, error) {
+		return NewTrogdorService().WithCredentials(creds), nil
+	}
 )
 
 // Setup adds a controller that reconciles KafkaBench managed resources.
@@ -72,7 +119,10 @@ func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter) error {
 		managed.WithExternalConnecter(&connector{
 			kube:         mgr.GetClient(),
 			usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
-			newServiceFn: newNoOpService}),
+			targetUsage:  kafkatarget.NewUsageTracker(mgr.GetClient()),
+			record:       event.NewAPIRecorder(mgr.GetEventRecorderFor(name)),
+			log:          l.WithValues("controller", name),
+			newServiceFn: newTrogdorAgentService}),
 		managed.WithLogger(l.WithValues("controller", name)),
 		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
 
@@ -80,12 +130,14 @@ func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter) error {
 		Named(name).
 		WithOptions(o).
 		For(&v1alpha1.KafkaBench{}).
+		Watches(&source.Kind{Type: &v1alpha1.KafkaBench{}}, handler.EnqueueRequestsFromMapFunc(queuedRequests(listKafkaBenches(mgr.GetClient())))).
+		Watches(&source.Kind{Type: &v1alpha1.NamespacedKafkaBench{}}, handler.EnqueueRequestsFromMapFunc(queuedRequests(listKafkaBenches(mgr.GetClient())))).
 		Complete(r)
 }
 
 // WorkerTaskSpec is part of the WorkerTask and contains the specification for the worker
 type WorkerTaskSpec struct {
-	v1alpha1.KafkaBenchSpec
+	v1alpha1.KafkaBenchParameters
 	StartMs int64 `json:"startMs,omitempty"`
 }
 
@@ -96,28 +148,52 @@ type WorkerTask struct {
 	Spec     WorkerTaskSpec `json:"spec,omitempty"`
 }
 
+// A bench is a KafkaBench or a NamespacedKafkaBench.
+type bench interface {
+	resource.Managed
+	GetBenchSpec() *v1alpha1.KafkaBenchSpec
+	GetBenchStatus() *v1alpha1.KafkaBenchStatus
+}
+
 // A connector is expected to produce an ExternalClient when its Connect method
 // is called.
 type connector struct {
 	kube         client.Client
 	usage        resource.Tracker
-	newServiceFn func(creds []byte) (*TrogdorAgentService, error)
+	targetUsage  resource.Tracker
+	record       event.Recorder
+	log          logging.Logger
+	newServiceFn func(creds map[string]string) (*TrogdorAgentService, error)
 }
 
 // Connect typically produces an ExternalClient by:
-// 1. Tracking that the managed resource is using a ProviderConfig.
-// 2. Getting the managed resource's ProviderConfig.
-// 3. Getting the credentials specified by the ProviderConfig.
+// 1. Tracking that the managed resource is using a ProviderConfig and,
+// optionally, a KafkaTarget.
+// 2. Getting the managed resource's ProviderConfig and KafkaTarget.
+// 3. Getting the credentials specified by the ProviderConfig, the KafkaTarget
+// and the bench.
 // 4. Using the credentials to form a client.
 func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
-	cr, ok := mg.(*v1alpha1.KafkaBench)
+	cr, ok := mg.(bench)
 	if !ok {
 		return nil, errors.New(errNotKafkaBench)
 	}
 
+	spec := localSpec(cr)
+	errs := validation.ValidateConnection(spec, field.NewPath("spec"))
+	if ns := cr.GetNamespace(); ns != "" {
+		errs = append(errs, validation.ValidateNamespacedKafkaBench(ns, spec, field.NewPath("spec"))...)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Wrap(errs.ToAggregate(), errConnection)
+	}
+
 	if err := c.usage.Track(ctx, mg); err != nil {
 		return nil, errors.Wrap(err, errTrackPCUsage)
 	}
+	if err := c.targetUsage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackTarget)
+	}
 
 	pc := &apisv1alpha1.ProviderConfig{}
 	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
@@ -129,13 +205,100 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err != nil {
 		return nil, errors.Wrap(err, errGetCreds)
 	}
+	// Earlier releases accepted opaque ProviderConfig credentials that carry
+	// no Kafka credentials, such as those of the example ProviderConfig.
+	if len(data) > 0 && !kafka.IsJSONObject(data) {
+		c.log.Debug("Ignoring ProviderConfig credentials that are not a JSON object", "providerConfig", pc.GetName(), "bytes", len(data))
+		data = nil
+	}
+	pcCreds, err := kafka.ParseCredentials(data)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
 
-	svc, err := c.newServiceFn(data)
+	// Client configurations are merged from the least to the most specific:
+	// the ProviderConfig, the KafkaTarget, the referenced Kafka cluster, then
+	// the TLS material and credentials of the bench.
+	var target *v1alpha1.KafkaTarget
+	var targetCreds map[string]string
+	if ref := spec.TargetRef; ref != nil {
+		target = &v1alpha1.KafkaTarget{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Name: ref.Name}, target); err != nil {
+			return nil, errors.Wrap(err, errGetTarget)
+		}
+		if sr := target.Spec.SecretRef; sr != nil {
+			if targetCreds, err = c.credentials(ctx, *sr); err != nil {
+				return nil, errors.Wrap(err, errGetTargetCreds)
+			}
+		}
+	}
+	cluster := &kafka.Cluster{}
+	if ref := spec.KafkaClusterRef; ref != nil {
+		if cluster, err = kafka.ResolveCluster(ctx, c.kube, *ref); err != nil {
+			cr.SetConditions(v1alpha1.ClusterResolutionFailed(redact.String(err.Error())))
+			return nil, errors.Wrap(err, errResolveCluster)
+		}
+		cr.SetConditions(v1alpha1.ClusterResolved())
+	}
+	var tlsConf, benchCreds map[string]string
+	if t := spec.TLS; t != nil {
+		if tlsConf, err = c.tlsConf(ctx, *t); err != nil {
+			return nil, errors.Wrap(err, errGetTLS)
+		}
+	}
+	if ref := spec.SecretRef; ref != nil {
+		if benchCreds, err = c.credentials(ctx, *ref); err != nil {
+			return nil, errors.Wrap(err, errGetBenchCreds)
+		}
+	}
+
+	svc, err := c.newServiceFn(kafka.MergeConf(pcCreds, targetCreds, cluster.Conf, tlsConf, benchCreds))
 	if err != nil {
 		return nil, errors.Wrap(err, errNewClient)
 	}
 
-	return &external{service: svc}, nil
+	connection := defaults.KafkaTarget(target)
+	if cluster.BootstrapServers != "" {
+		connection = &v1alpha1.KafkaBenchParameters{BootstrapServers: cluster.BootstrapServers}
+	}
+	return redact.ExternalClient(&external{
+		service:    svc,
+		kube:       c.kube,
+		queue:      queue,
+		connection: connection,
+		defaults:   pc.Spec.BenchDefaults,
+		guardrails: validation.Guardrails(pc, target),
+		approvals:  approvals.Load(),
+		record:     c.record,
+		log:        c.log,
+	}), nil
+}
+
+// credentials returns the client configuration described by the credentials
+// of a Secret key.
+func (c *connector) credentials(ctx context.Context, ref xpv1.SecretKeySelector) (map[string]string, error) {
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return nil, err
+	}
+	return kafka.ParseCredentials(s.Data[ref.Key])
+}
+
+// tlsConf returns the inline PEM client configuration of the TLS material a
+// bench references.
+func (c *connector) tlsConf(ctx context.Context, t v1alpha1.KafkaBenchTLS) (map[string]string, error) {
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: t.SecretRef.Namespace, Name: t.SecretRef.Name}, s); err != nil {
+		return nil, err
+	}
+	return kafka.TLSConf(s.Data[keyOrDefault(t.CAKey, defaultCAKey)], s.Data[keyOrDefault(t.CertKey, corev1.TLSCertKey)], s.Data[keyOrDefault(t.KeyKey, corev1.TLSPrivateKeyKey)])
+}
+
+func keyOrDefault(key, def string) string {
+	if key == "" {
+		return def
+	}
+	return key
 }
 
 // An ExternalClient observes, then either creates, updates, or deletes an
@@ -144,27 +307,86 @@ type external struct {
 	// A 'client' used to connect to the external resource API. In practice this
 	// would be something like an AWS SDK client.
 	service *TrogdorAgentService
+	kube    client.Reader
+	// queue admits benches to the agent pool. Benches start at once when it
+	// is nil.
+	queue *benchQueue
+	// connection holds the connection settings of the KafkaTarget or Kafka
+	// cluster the bench references, if any.
+	connection *v1alpha1.KafkaBenchParameters
+	// defaults are the bench defaults of the ProviderConfig.
+	defaults *v1alpha1.KafkaBenchParameters
+	// guardrails of the ProviderConfig and KafkaTarget, keyed by the object
+	// that sets them.
+	guardrails map[string]*v1alpha1.Guardrails
+	// approvals tells whether the guardrails approvals of benches are
+	// trusted.
+	approvals bool
+	record    event.Recorder
+	log       logging.Logger
 }
 
 func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
-	cr, ok := mg.(*v1alpha1.KafkaBench)
+	cr, ok := mg.(bench)
 	if !ok {
 		return managed.ExternalObservation{}, errors.New(errNotKafkaBench)
 	}
 
-	// These fmt statements should be removed in the real implementation.
-	fmt.Printf("Observing: %+v \n", cr)
+	c.log.Debug("Observing bench", "name", cr.GetName(), "taskId", cr.GetBenchStatus().AtProvider.TaskID, "taskStatus", cr.GetBenchStatus().AtProvider.TaskStatus)
+
+	if undispatched(cr) {
+		held, err := hold(cr, time.Now())
+		if err != nil {
+			return managed.ExternalObservation{}, err
+		}
+		if held {
+			// Report the bench as up to date so that it is not created
+			// before its start.
+			return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, nil
+		}
+	}
+	if c.queue != nil && queued(cr) {
+		pos, refused, err := c.queue.Position(ctx, c.kube, cr)
+		if err != nil {
+			return managed.ExternalObservation{}, err
+		}
+		cr.GetBenchStatus().AtProvider.QueuePosition = pos
+		if refused {
+			// Report the bench as up to date so that it is not created,
+			// as its group would never start.
+			cr.GetBenchStatus().AtProvider.TaskStatus = taskStatusRefused
+			cr.SetConditions(v1alpha1.GroupTooLarge(cr.GetAnnotations()[v1alpha1.AnnotationKeyGroup]))
+			return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, nil
+		}
+		if pos > 0 {
+			// Report the queued bench as up to date so that it waits for
+			// a place in the agent pool rather than being created.
+			cr.GetBenchStatus().AtProvider.TaskStatus = taskStatusQueued
+			cr.SetConditions(v1alpha1.Queued(pos))
+			return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, nil
+		}
+	}
+	if queued(cr) && holding(cr) {
+		// Report the bench as up to date so that it is not created before
+		// its group agreed on the start of its members.
+		if _, _, err := c.prepare(ctx, cr); err != nil {
+			return managed.ExternalObservation{}, err
+		}
+		cr.GetBenchStatus().AtProvider.TaskStatus = taskStatusWaiting
+		cr.SetConditions(v1alpha1.WaitingForGroup(cr.GetAnnotations()[v1alpha1.AnnotationKeyGroup]))
+		return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, nil
+	}
 
 	return managed.ExternalObservation{
 		// Return false when the external resource does not exist. This lets
 		// the managed resource reconciler know that it needs to call Create to
 		// (re)create the resource, or that it has successfully been deleted.
-		ResourceExists: cr.Status.AtProvider.TaskID != "",
+		ResourceExists: cr.GetBenchStatus().AtProvider.TaskID != "",
 
 		// Return false when the external resource exists, but it not up to date
 		// with the desired managed resource state. This lets the managed
 		// resource reconciler know that it needs to call Update.
-		ResourceUpToDate: cr.Status.AtProvider.TaskStatus == "DONE",
+		ResourceUpToDate: benches.Completed(cr.GetBenchStatus().AtProvider),
 
 		// Return any details that may be required to connect to the external
 		// resource. These will be stored as the connection secret.
@@ -173,65 +395,244 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 }
 
 func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
-	cr, ok := mg.(*v1alpha1.KafkaBench)
+	cr, ok := mg.(bench)
 	if !ok {
 		return managed.ExternalCreation{}, errors.New(errNotKafkaBench)
 	}
 	cr.SetConditions(xpv1.Creating())
+	params, segs, err := c.prepare(ctx, cr)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	dispatched := params
+	if segs != nil {
+		// Benches with a load profile run one segment at a time.
+		dispatched = loadprofile.Parameters(params, segs[0])
+	}
+	w := cr.GetBenchSpec().Warmup
+	if w != nil {
+		dispatched = warmupParameters(dispatched, *w)
+	}
+	start, err := startMs(cr)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
 
-	workerTask, err := c.service.CreateWorkerTask(cr.Spec)
+	workerTask, err := c.service.CreateWorkerTaskAt(dispatched, start)
 	if err != nil {
 		return managed.ExternalCreation{}, err
 	}
-	cr.Status.AtProvider.TaskStatus = "CREATED"
-	cr.Status.AtProvider.TaskID = workerTask.TaskID
-	cr.Status.AtProvider.WorkerID = workerTask.WorkerID
+	obs := &cr.GetBenchStatus().AtProvider
+	obs.StartTime = &metav1.Time{Time: time.UnixMilli(workerTask.Spec.StartMs)}
+	obs.Segments = loadSegments(segs)
+	obs.Runs = runs(cr)
+	obs.CurrentRun = 0
+	obs.Summary = nil
+	obs.Warmup = nil
+	obs.Assertions = nil
+	if w != nil {
+		obs.Warmup = &v1alpha1.WarmupStatus{WorkerID: workerTask.WorkerID, StartTime: obs.StartTime}
+	}
+	begin(obs, workerTask)
+	effective := params
+	redact.KafkaBenchParameters(&effective)
+	cr.GetBenchStatus().AtProvider.EffectiveSpec = &effective
+	c.log.Debug("Created worker task", "name", cr.GetName(), "taskId", workerTask.TaskID, "workerId", workerTask.WorkerID, "spec", effective)
+	if params.Class == consumerWorkload {
+		cr.GetBenchStatus().AtProvider.ConsumerAssignment = consumerAssignment(params)
+	}
 
 	return managed.ExternalCreation{
 		// Optionally return any details that may be required to connect to the
 		// external resource. These will be stored as the connection secret.
 		ConnectionDetails: managed.ConnectionDetails{
 			"taskId":    []byte(workerTask.TaskID),
-			"name":      []byte(cr.Name),
-			"namespace": []byte(cr.Namespace),
+			"name":      []byte(cr.GetName()),
+			"namespace": []byte(cr.GetNamespace()),
 		},
 	}, nil
 }
 
+// track records the supplied task as the one the bench runs, and forgets what
+// the previous task of the bench reported. Every task a bench starts, be it
+// its warm-up, one of its runs or a segment of its load profile, is tracked.
+func track(obs *v1alpha1.KafkaBenchObservation, workerTask *WorkerTask) {
+	obs.TaskID = workerTask.TaskID
+	obs.WorkerID = workerTask.WorkerID
+	obs.TaskStatus = "CREATED"
+	obs.CompletionTime = nil
+	obs.LastProgressTime = nil
+	obs.ProducerStats = v1alpha1.ProducerBenchResultStats{}
+	obs.ConsumerStats = nil
+	obs.RoundTripStats = v1alpha1.RoundTripBenchResultStats{}
+	obs.RawStatus = nil
+	obs.CommandStatus = nil
+}
+
+// begin tracks the supplied task as the one the bench runs. Unless it is the
+// task of the warm-up of the bench, it starts the first segment of the current
+// run.
+func begin(obs *v1alpha1.KafkaBenchObservation, workerTask *WorkerTask) {
+	track(obs, workerTask)
+	obs.CurrentSegment = 0
+	if benches.WarmingUp(*obs) {
+		return
+	}
+	started := &metav1.Time{Time: time.UnixMilli(workerTask.Spec.StartMs)}
+	if len(obs.Segments) > 0 {
+		obs.Segments[0].WorkerID = workerTask.WorkerID
+		obs.Segments[0].StartTime = started
+	}
+	if len(obs.Runs) > 0 {
+		obs.Runs[obs.CurrentRun] = v1alpha1.BenchRun{WorkerID: workerTask.WorkerID, TaskStatus: obs.TaskStatus, StartTime: started}
+	}
+}
+
+// prepare returns the parameters of the supplied bench, and the segments of
+// its load profile if any, once they have been checked against its
+// guardrails.
+func (c *external) prepare(ctx context.Context, cr bench) (v1alpha1.KafkaBenchParameters, []loadprofile.Segment, error) {
+	params, err := c.parameters(cr)
+	if err != nil {
+		return v1alpha1.KafkaBenchParameters{}, nil, err
+	}
+	if params.Class == externalCommandWorkload && len(params.Command) == 0 {
+		return v1alpha1.KafkaBenchParameters{}, nil, errors.New(errNoCommand)
+	}
+	if cr.GetBenchSpec().Warmup != nil && (params.Class != producerWorkload || params.RawSpec != nil) {
+		return v1alpha1.KafkaBenchParameters{}, nil, errors.New(errWarmupNotProducer)
+	}
+	segs, err := c.segments(ctx, cr, params)
+	if err != nil {
+		return v1alpha1.KafkaBenchParameters{}, nil, err
+	}
+	checked := params
+	if segs != nil {
+		// Benches with a load profile must stay within their guardrails
+		// over all of their segments.
+		checked = loadprofile.Envelope(params, segs)
+	}
+	if !c.approved(cr) {
+		if errs := validation.ValidateGuardrails(c.guardrails, &checked, field.NewPath("spec")); len(errs) > 0 {
+			cr.SetConditions(v1alpha1.GuardrailsViolated(errs.ToAggregate().Error()))
+			return v1alpha1.KafkaBenchParameters{}, nil, errors.Wrap(errs.ToAggregate(), errGuardrails)
+		}
+	}
+	return params, segs, nil
+}
+
+// approved returns whether the supplied bench was approved to exceed its
+// guardrails. Approvals are only trusted when the webhook, which checks who
+// may set them, runs.
+func (c *external) approved(cr bench) bool {
+	if !validation.GuardrailsApproved(cr) {
+		return false
+	}
+	if !c.approvals {
+		c.log.Debug("Ignoring the guardrails approval of a bench as no webhook checks who approved it", "name", cr.GetName())
+	}
+	return c.approvals
+}
+
+// parameters returns the parameters of the supplied bench once the settings
+// of its KafkaTarget or Kafka cluster, then the bench defaults of its
+// ProviderConfig, have been applied.
+func (c *external) parameters(cr bench) (v1alpha1.KafkaBenchParameters, error) {
+	params, err := defaults.ApplyKafkaBench(c.connection, cr.GetBenchSpec().KafkaBenchParameters)
+	if err != nil {
+		return v1alpha1.KafkaBenchParameters{}, err
+	}
+	return defaults.ApplyKafkaBench(c.defaults, params)
+}
+
+// consumerAssignment tells how the consumers of a consumer bench get their
+// partitions. Trogdor falls back to manual assignment as soon as a topic names
+// its partitions.
+func consumerAssignment(spec v1alpha1.KafkaBenchParameters) string {
+	for _, t := range spec.ConsumerTopics {
+		if strings.Contains(string(t), ":") {
+			return v1alpha1.ConsumerManualAssignment
+		}
+	}
+	return v1alpha1.ConsumerGroupSubscription
+}
+
 func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
-	cr, ok := mg.(*v1alpha1.KafkaBench)
+	cr, ok := mg.(bench)
 	if !ok {
 		return managed.ExternalUpdate{}, errors.New(errNotKafkaBench)
 	}
-	fmt.Printf("Updating: %+v \n", cr)
+	before := progress(cr)
+	u, err := c.collect(cr)
+	if err != nil {
+		return u, err
+	}
+	if err := c.endWarmup(cr); err != nil {
+		return u, err
+	}
+	if err := c.nextSegment(cr); err != nil {
+		return u, err
+	}
+	if err := c.nextRun(cr); err != nil {
+		return u, err
+	}
+	if err := c.watchdog(cr, before); err != nil {
+		return u, err
+	}
+	c.assert(cr)
+	return u, nil
+}
 
-	workerID := strconv.FormatInt(cr.Status.AtProvider.WorkerID, 10)
-	statusResponse, err := c.service.CollectWorkerTaskResult(workerID)
+// collect records the status Trogdor reports for the task of the supplied
+// bench.
+func (c *external) collect(cr bench) (managed.ExternalUpdate, error) {
+	params := effectiveParameters(cr)
+	workerID := strconv.FormatInt(cr.GetBenchStatus().AtProvider.WorkerID, 10)
+	c.log.Debug("Collecting worker status", "name", cr.GetName(), "workerId", workerID)
+	if params.Class == externalCommandWorkload {
+		return c.updateExternalCommand(cr, workerID)
+	}
+	statusResponse, err := c.service.CollectWorkerStatus(workerID)
 	if err != nil {
 		return managed.ExternalUpdate{}, err
 	}
+	if statusResponse.Error != "" {
+		// The last stats collected are kept.
+		c.fail(cr, statusResponse.Error)
+		return managed.ExternalUpdate{}, nil
+	}
 
-	cr.Status.AtProvider.TaskStatus = statusResponse.State
+	cr.GetBenchStatus().AtProvider.TaskStatus = statusResponse.State
+	if statusResponse.DoneMs > 0 {
+		cr.GetBenchStatus().AtProvider.CompletionTime = &metav1.Time{Time: time.UnixMilli(statusResponse.DoneMs)}
+	}
+	if params.RawSpec != nil {
+		raw, err := json.Marshal(statusResponse.Status)
+		if err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+		cr.GetBenchStatus().AtProvider.RawStatus = redact.RawExtension(&runtime.RawExtension{Raw: raw})
+	}
 	// status could be a string like "creating topics..."
 	if _, ok := statusResponse.Status.(map[string]interface{}); !ok {
-		fmt.Printf("Tasks running but waiting for a condition: %v \n", statusResponse.Status)
+		c.log.Debug("Worker task waiting for a condition", "name", cr.GetName(), "workerId", workerID, "status", redact.Value(statusResponse.Status))
 		return managed.ExternalUpdate{}, nil
 	}
-	switch cr.Spec.Class {
+	switch params.Class {
 	case producerWorkload:
-		if err := mapstructure.Decode(statusResponse.Status, &cr.Status.AtProvider.ProducerStats); err != nil {
+		if err := mapstructure.Decode(statusResponse.Status, &cr.GetBenchStatus().AtProvider.ProducerStats); err != nil {
 			return managed.ExternalUpdate{}, err
 		}
 	case roundTripWorkload:
-		if err := mapstructure.Decode(statusResponse.Status, &cr.Status.AtProvider.RoundTripStats); err != nil {
+		if err := mapstructure.Decode(statusResponse.Status, &cr.GetBenchStatus().AtProvider.RoundTripStats); err != nil {
 			return managed.ExternalUpdate{}, err
 		}
 	case consumerWorkload:
-		if err := mapstructure.Decode(statusResponse.Status, &cr.Status.AtProvider.ConsumerStats); err != nil {
+		if err := mapstructure.Decode(statusResponse.Status, &cr.GetBenchStatus().AtProvider.ConsumerStats); err != nil {
 			return managed.ExternalUpdate{}, err
 		}
 	}
-	fmt.Printf("Got from collect %v for workerId %s\n", statusResponse, workerID)
+	c.log.Debug("Collected worker status", "name", cr.GetName(), "workerId", workerID, "state", statusResponse.State, "status", redact.Value(statusResponse.Status))
 	cr.SetConditions(xpv1.Available())
 	return managed.ExternalUpdate{
 		// Optionally return any details that may be required to connect to the
@@ -240,19 +641,73 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 	}, nil
 }
 
+// effectiveParameters returns the parameters that were sent to Trogdor for
+// the supplied bench.
+func effectiveParameters(cr bench) v1alpha1.KafkaBenchParameters {
+	if cr.GetBenchStatus().AtProvider.EffectiveSpec != nil {
+		return *cr.GetBenchStatus().AtProvider.EffectiveSpec
+	}
+	return cr.GetBenchSpec().KafkaBenchParameters
+}
+
+// updateExternalCommand records the last status reported by the command of an
+// ExternalCommandSpec workload and, once it exits, its exit code. Unlike the
+// other workloads the status is kept when the worker reports an error, since
+// that is how Trogdor reports a non-zero exit code.
+func (c *external) updateExternalCommand(cr bench, workerID string) (managed.ExternalUpdate, error) {
+	ws, err := c.service.CollectWorkerStatus(workerID)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	cr.GetBenchStatus().AtProvider.TaskStatus = ws.State
+	cs := cr.GetBenchStatus().AtProvider.CommandStatus
+	if cs == nil {
+		cs = &v1alpha1.ExternalCommandStatus{}
+		cr.GetBenchStatus().AtProvider.CommandStatus = cs
+	}
+	if ws.Status != nil {
+		raw, err := json.Marshal(ws.Status)
+		if err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+		cs.LastStatus = redact.RawExtension(&runtime.RawExtension{Raw: raw})
+	}
+	if code, ok := exitCode(ws); ok {
+		cs.ExitCode = &code
+	}
+	if ws.Error != "" {
+		c.fail(cr, ws.Error)
+		return managed.ExternalUpdate{}, nil
+	}
+
+	cr.SetConditions(xpv1.Available())
+	return managed.ExternalUpdate{ConnectionDetails: managed.ConnectionDetails{}}, nil
+}
+
+// exitCode extracts the exit code of the command run by an ExternalCommandSpec
+// worker. Trogdor only reports it through the worker error when it is not 0.
+func exitCode(ws *trogdor.AgentStatusWorkers) (int32, bool) {
+	if m := exitCodeRegexp.FindStringSubmatch(ws.Error); m != nil {
+		code, err := strconv.ParseInt(m[1], 10, 32)
+		return int32(code), err == nil
+	}
+	return 0, ws.State == "DONE" && ws.Error == ""
+}
+
 func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
-	cr, ok := mg.(*v1alpha1.KafkaBench)
+	cr, ok := mg.(bench)
 	if !ok {
 		return errors.New(errNotKafkaBench)
 	}
 
-	fmt.Printf("Deleting: %+v", cr)
 	cr.SetConditions(xpv1.Deleting())
-	workerID := strconv.FormatInt(cr.Status.AtProvider.WorkerID, 10)
+	workerID := strconv.FormatInt(cr.GetBenchStatus().AtProvider.WorkerID, 10)
+	c.log.Debug("Deleting worker task", "name", cr.GetName(), "workerId", workerID)
 	if err := c.service.DeleteWorkerTask(workerID); err != nil {
 		return err
 	}
 
-	cr.Status.AtProvider.TaskID = ""
+	cr.GetBenchStatus().AtProvider.TaskID = ""
 	return nil
 }
@@ -22,18 +22,35 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
 
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/jarcoal/httpmock"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	apisv1alpha1 "github.com/nachomdo/tarasque/apis/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/benches/benchestest"
+	"github.com/nachomdo/tarasque/internal/clients/trogdor"
+	"github.com/nachomdo/tarasque/internal/defaults"
+	"github.com/nachomdo/tarasque/internal/redact"
 )
 
 // Unlike many Kubernetes projects Crossplane does not use third party testing
@@ -77,12 +94,14 @@ func TestObserve(t *testing.T) {
 						Name:      "newBenchmark",
 					},
 					Spec: v1alpha1.KafkaBenchSpec{
-						Class:            "org.apache.kafka.trogdor.workload.ProduceBenchSpec",
-						BootstrapServers: "localhost:9092",
-						ActiveTopics: map[string]v1alpha1.KafkaTopics{
-							"myTopic": {
-								NumPartitions:     10,
-								ReplicationFactor: 3,
+						KafkaBenchParameters: v1alpha1.KafkaBenchParameters{
+							Class:            "org.apache.kafka.trogdor.workload.ProduceBenchSpec",
+							BootstrapServers: "localhost:9092",
+							ActiveTopics: map[string]v1alpha1.KafkaTopics{
+								"myTopic": {
+									NumPartitions:     10,
+									ReplicationFactor: 3,
+								},
 							},
 						},
 					},
@@ -101,7 +120,7 @@ func TestObserve(t *testing.T) {
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := external{service: tc.fields.service}
+			e := external{service: tc.fields.service, log: logging.NewNopLogger()}
 			got, err := e.Observe(tc.args.ctx, tc.args.mg)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
@@ -170,12 +189,14 @@ func TestCreate(t *testing.T) {
 						Name:      "newBenchmark",
 					},
 					Spec: v1alpha1.KafkaBenchSpec{
-						Class:            "org.apache.kafka.trogdor.workload.ProduceBenchSpec",
-						BootstrapServers: "localhost:9092",
-						ActiveTopics: map[string]v1alpha1.KafkaTopics{
-							"myTopic": {
-								NumPartitions:     10,
-								ReplicationFactor: 3,
+						KafkaBenchParameters: v1alpha1.KafkaBenchParameters{
+							Class:            "org.apache.kafka.trogdor.workload.ProduceBenchSpec",
+							BootstrapServers: "localhost:9092",
+							ActiveTopics: map[string]v1alpha1.KafkaTopics{
+								"myTopic": {
+									NumPartitions:     10,
+									ReplicationFactor: 3,
+								},
 							},
 						},
 					},
@@ -192,7 +213,7 @@ func TestCreate(t *testing.T) {
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := external{service: tc.fields.service}
+			e := external{service: tc.fields.service, log: logging.NewNopLogger()}
 			got, err := e.Create(tc.args.ctx, tc.args.mg)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
@@ -228,9 +249,9 @@ func TestUpdate(t *testing.T) {
 	defer httpmock.DeactivateAndReset()
 	httpmock.RegisterResponder("GET", agentServiceURL+"/agent/status",
 		func(req *http.Request) (*http.Response, error) {
-			statusResponse := AgentStatusResponse{
+			statusResponse := trogdor.AgentStatusResponse{
 				ServerStartMs: 1000,
-				Workers: map[string]AgentStatusWorkers{
+				Workers: map[string]trogdor.AgentStatusWorkers{
 					"1234": {
 						State:     "DONE",
 						TaskID:    "1",
@@ -306,12 +327,14 @@ func TestUpdate(t *testing.T) {
 						Name:      "newBenchmark",
 					},
 					Spec: v1alpha1.KafkaBenchSpec{
-						Class:            producerWorkload,
-						BootstrapServers: "localhost:9092",
-						ActiveTopics: map[string]v1alpha1.KafkaTopics{
-							"myTopic": {
-								NumPartitions:     10,
-								ReplicationFactor: 3,
+						KafkaBenchParameters: v1alpha1.KafkaBenchParameters{
+							Class:            producerWorkload,
+							BootstrapServers: "localhost:9092",
+							ActiveTopics: map[string]v1alpha1.KafkaTopics{
+								"myTopic": {
+									NumPartitions:     10,
+									ReplicationFactor: 3,
+								},
 							},
 						},
 					},
@@ -342,12 +365,14 @@ func TestUpdate(t *testing.T) {
 						Name:      "newBenchmark",
 					},
 					Spec: v1alpha1.KafkaBenchSpec{
-						Class:            consumerWorkload,
-						BootstrapServers: "localhost:9092",
-						ActiveTopics: map[string]v1alpha1.KafkaTopics{
-							"myTopic": {
-								NumPartitions:     10,
-								ReplicationFactor: 3,
+						KafkaBenchParameters: v1alpha1.KafkaBenchParameters{
+							Class:            consumerWorkload,
+							BootstrapServers: "localhost:9092",
+							ActiveTopics: map[string]v1alpha1.KafkaTopics{
+								"myTopic": {
+									NumPartitions:     10,
+									ReplicationFactor: 3,
+								},
 							},
 						},
 					},
@@ -371,7 +396,7 @@ func TestUpdate(t *testing.T) {
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := external{service: tc.fields.service}
+			e := external{service: tc.fields.service, log: logging.NewNopLogger()}
 			got, err := e.Update(tc.args.ctx, tc.args.mg)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
@@ -382,3 +407,534 @@ func TestUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestConsumerAssignment(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		spec   v1alpha1.KafkaBenchParameters
+		want   string
+	}{
+		"TopicMap": {
+			reason: "Topics from the activeTopics map are subscribed through the consumer group",
+			spec: v1alpha1.KafkaBenchParameters{
+				ActiveTopics: map[string]v1alpha1.KafkaTopics{"test[1-5]": {}},
+			},
+			want: v1alpha1.ConsumerGroupSubscription,
+		},
+		"TopicRanges": {
+			reason: "Topic ranges without partitions are subscribed through the consumer group",
+			spec: v1alpha1.KafkaBenchParameters{
+				ConsumerTopics: []v1alpha1.ConsumerTopic{"test[1-5]"},
+			},
+			want: v1alpha1.ConsumerGroupSubscription,
+		},
+		"PartitionRanges": {
+			reason: "Naming partitions of any topic forces manual assignment",
+			spec: v1alpha1.KafkaBenchParameters{
+				ConsumerTopics: []v1alpha1.ConsumerTopic{"test1", "test2:[0-3]"},
+			},
+			want: v1alpha1.ConsumerManualAssignment,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, consumerAssignment(tc.spec)); diff != "" {
+				t.Errorf("\n%s\nconsumerAssignment(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdateRawStatus(t *testing.T) {
+	httpClient := resty.New()
+	svcResolver := &mockResolver{[]string{defaultAgentServiceName}, nil}
+	client := newTrogdorServiceWithRestClient(httpClient, svcResolver)
+	httpmock.ActivateNonDefault(httpClient.GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", agentServiceURL+"/agent/status",
+		httpmock.NewStringResponder(200, `{"workers": {
+			"1": {"state": "RUNNING", "taskId": "1", "status": "Creating 5 topic(s)"},
+			"2": {"state": "DONE", "taskId": "2", "doneMs": 1649460862431, "status": {"custom": {"totalSent": 10}}}}}`))
+
+	cases := map[string]struct {
+		reason   string
+		workerID int64
+		want     v1alpha1.KafkaBenchObservation
+	}{
+		"StringStatus": {
+			reason:   "Progress messages should be kept as raw JSON strings",
+			workerID: 1,
+			want: v1alpha1.KafkaBenchObservation{
+				TaskStatus: "RUNNING",
+				WorkerID:   1,
+				RawStatus:  &runtime.RawExtension{Raw: []byte(`"Creating 5 topic(s)"`)},
+			},
+		},
+		"ObjectStatus": {
+			reason:   "Results of custom workloads should be kept as raw JSON objects, with the time they were done",
+			workerID: 2,
+			want: v1alpha1.KafkaBenchObservation{
+				TaskStatus:     "DONE",
+				WorkerID:       2,
+				RawStatus:      &runtime.RawExtension{Raw: []byte(`{"custom":{"totalSent":10}}`)},
+				CompletionTime: &metav1.Time{Time: time.UnixMilli(1649460862431)},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.KafkaBench{
+				Spec: v1alpha1.KafkaBenchSpec{
+					KafkaBenchParameters: v1alpha1.KafkaBenchParameters{
+						RawSpec: &runtime.RawExtension{Raw: []byte(`{"class": "org.example.CustomWorkloadSpec"}`)},
+					},
+				},
+				Status: v1alpha1.KafkaBenchStatus{
+					AtProvider: v1alpha1.KafkaBenchObservation{WorkerID: tc.workerID},
+				},
+			}
+			e := external{service: client, log: logging.NewNopLogger()}
+			if _, err := e.Update(context.TODO(), cr); err != nil {
+				t.Fatalf("\n%s\ne.Update(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, cr.Status.AtProvider); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdateExternalCommand(t *testing.T) {
+	httpClient := resty.New()
+	svcResolver := &mockResolver{[]string{defaultAgentServiceName}, nil}
+	client := newTrogdorServiceWithRestClient(httpClient, svcResolver)
+	httpmock.ActivateNonDefault(httpClient.GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", agentServiceURL+"/agent/status",
+		httpmock.NewStringResponder(200, `{"workers": {
+			"1": {"state": "RUNNING", "taskId": "1", "status": {"sent": 100}},
+			"2": {"state": "DONE", "taskId": "2", "status": {"sent": 500}},
+			"3": {"state": "DONE", "taskId": "3", "error": "exited with return code 3"}}}`))
+
+	exitCode := func(c int32) *int32 { return &c }
+	cases := map[string]struct {
+		reason   string
+		workerID int64
+		previous *v1alpha1.ExternalCommandStatus
+		want     *v1alpha1.ExternalCommandStatus
+		err      error
+	}{
+		"Running": {
+			reason:   "The last status reported by a running command should be recorded",
+			workerID: 1,
+			want:     &v1alpha1.ExternalCommandStatus{LastStatus: &runtime.RawExtension{Raw: []byte(`{"sent":100}`)}},
+		},
+		"Succeeded": {
+			reason:   "A command that exited without error should report exit code 0",
+			workerID: 2,
+			want: &v1alpha1.ExternalCommandStatus{
+				LastStatus: &runtime.RawExtension{Raw: []byte(`{"sent":500}`)},
+				ExitCode:   exitCode(0),
+			},
+		},
+		"Failed": {
+			reason:   "A command that exited with an error should keep its last status and report its exit code",
+			workerID: 3,
+			previous: &v1alpha1.ExternalCommandStatus{LastStatus: &runtime.RawExtension{Raw: []byte(`{"sent":100}`)}},
+			want: &v1alpha1.ExternalCommandStatus{
+				LastStatus: &runtime.RawExtension{Raw: []byte(`{"sent":100}`)},
+				ExitCode:   exitCode(3),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.KafkaBench{
+				Spec: v1alpha1.KafkaBenchSpec{
+					KafkaBenchParameters: v1alpha1.KafkaBenchParameters{
+						Class:   externalCommandWorkload,
+						Command: []string{"/bin/load-generator"},
+					},
+				},
+				Status: v1alpha1.KafkaBenchStatus{
+					AtProvider: v1alpha1.KafkaBenchObservation{WorkerID: tc.workerID, CommandStatus: tc.previous},
+				},
+			}
+			e := external{service: client, record: &benchestest.Recorder{}, log: logging.NewNopLogger()}
+			_, err := e.Update(context.TODO(), cr)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want, cr.Status.AtProvider.CommandStatus); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreateExternalCommandWithoutCommand(t *testing.T) {
+	e := external{service: NewTrogdorService(), log: logging.NewNopLogger()}
+	_, err := e.Create(context.TODO(), &v1alpha1.KafkaBench{
+		Spec: v1alpha1.KafkaBenchSpec{KafkaBenchParameters: v1alpha1.KafkaBenchParameters{Class: externalCommandWorkload}},
+	})
+	if diff := cmp.Diff(errors.New(errNoCommand), err, test.EquateErrors()); diff != "" {
+		t.Errorf("e.Create(...): -want error, +got error:\n%s\n", diff)
+	}
+}
+
+func TestCreateGuardrails(t *testing.T) {
+	httpClient := resty.New()
+	svc := newTrogdorServiceWithRestClient(httpClient, &mockResolver{[]string{defaultAgentServiceName}, nil})
+	httpmock.ActivateNonDefault(httpClient.GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", agentServiceURL+"/agent/worker/create", httpmock.NewStringResponder(200, "{}"))
+
+	e := external{
+		service:    svc,
+		guardrails: map[string]*v1alpha1.Guardrails{`ProviderConfig "default"`: {MaxDurationMs: 60000}},
+		log:        logging.NewNopLogger(),
+	}
+	cr := &v1alpha1.KafkaBench{Spec: v1alpha1.KafkaBenchSpec{
+		KafkaBenchParameters: v1alpha1.KafkaBenchParameters{Class: producerWorkload, DurationMs: 100000000},
+	}}
+	if _, err := e.Create(context.TODO(), cr); err == nil {
+		t.Errorf("e.Create(...): benches exceeding their guardrails should not be dispatched")
+	}
+	if diff := cmp.Diff(v1alpha1.ReasonGuardrailsViolated, cr.GetCondition(xpv1.TypeReady).Reason); diff != "" {
+		t.Errorf("e.Create(...): -want reason, +got reason:\n%s\n", diff)
+	}
+	if httpmock.GetTotalCallCount() != 0 {
+		t.Errorf("e.Create(...): benches exceeding their guardrails should not reach the agents")
+	}
+
+	// No webhook checks who approved the bench.
+	meta.AddAnnotations(cr, map[string]string{v1alpha1.AnnotationKeyGuardrailsApproved: "true"})
+	if _, err := e.Create(context.TODO(), cr); err == nil {
+		t.Errorf("e.Create(...): approvals should be ignored when no webhook checks them")
+	}
+	if httpmock.GetTotalCallCount() != 0 {
+		t.Errorf("e.Create(...): benches approved without a webhook should not reach the agents")
+	}
+
+	e.approvals = true
+	if _, err := e.Create(context.TODO(), cr); err != nil {
+		t.Errorf("e.Create(...): approved benches should be dispatched: %v", err)
+	}
+}
+
+func TestCreateKafkaTarget(t *testing.T) {
+	httpClient := resty.New()
+	svc := newTrogdorServiceWithRestClient(httpClient, &mockResolver{[]string{defaultAgentServiceName}, nil})
+	httpmock.ActivateNonDefault(httpClient.GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", agentServiceURL+"/agent/worker/create", httpmock.NewStringResponder(200, "{}"))
+
+	target := &v1alpha1.KafkaTarget{Spec: v1alpha1.KafkaTargetSpec{
+		BootstrapServers: "kafka.target:9092",
+		CommonClientConf: map[string]string{"acks": "1", "linger.ms": "5"},
+	}}
+	e := external{
+		service:    svc,
+		connection: defaults.KafkaTarget(target),
+		defaults: &v1alpha1.KafkaBenchParameters{
+			BootstrapServers: "kafka.defaults:9092",
+			CommonClientConf: map[string]string{"acks": "0", "client.id": "tarasque"},
+		},
+		log: logging.NewNopLogger(),
+	}
+	cr := &v1alpha1.KafkaBench{Spec: v1alpha1.KafkaBenchSpec{
+		KafkaBenchParameters: v1alpha1.KafkaBenchParameters{
+			Class:            producerWorkload,
+			CommonClientConf: map[string]string{"linger.ms": "10"},
+		},
+		TargetRef: &xpv1.Reference{Name: "target"},
+	}}
+	if _, err := e.Create(context.TODO(), cr); err != nil {
+		t.Fatalf("e.Create(...): unexpected error: %v", err)
+	}
+
+	want := &v1alpha1.KafkaBenchParameters{
+		Class:            producerWorkload,
+		BootstrapServers: "kafka.target:9092",
+		CommonClientConf: map[string]string{"acks": "1", "linger.ms": "10", "client.id": "tarasque"},
+	}
+	if diff := cmp.Diff(want, cr.Status.AtProvider.EffectiveSpec); diff != "" {
+		t.Errorf("e.Create(...): the KafkaTarget should apply over the bench defaults and under the bench: -want, +got:\n%s\n", diff)
+	}
+}
+
+func TestConnectCredentials(t *testing.T) {
+	secrets := map[string]string{
+		"pc":     `{"username":"pc-user","password":"pc-pass","security.protocol":"SASL_PLAINTEXT"}`,
+		"target": `{"sasl.mechanism":"SCRAM-SHA-512","security.protocol":"SASL_SSL"}`,
+		"bench":  `{"sasl.jaas.config":"bench-jaas"}`,
+	}
+	kube := &test.MockClient{MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+		switch o := obj.(type) {
+		case *apisv1alpha1.ProviderConfig:
+			o.Spec.Credentials.Source = xpv1.CredentialsSourceSecret
+			o.Spec.Credentials.SecretRef = &xpv1.SecretKeySelector{
+				SecretReference: xpv1.SecretReference{Namespace: "tarasque", Name: "pc"},
+				Key:             "credentials",
+			}
+		case *v1alpha1.KafkaTarget:
+			o.Spec.SecretRef = &xpv1.SecretKeySelector{
+				SecretReference: xpv1.SecretReference{Namespace: "tarasque", Name: "target"},
+				Key:             "credentials",
+			}
+		case *corev1.Secret:
+			o.Data = map[string][]byte{"credentials": []byte(secrets[key.Name])}
+		}
+		return nil
+	}}
+
+	var got map[string]string
+	c := &connector{
+		kube:        kube,
+		usage:       resource.TrackerFn(func(_ context.Context, _ resource.Managed) error { return nil }),
+		targetUsage: resource.TrackerFn(func(_ context.Context, _ resource.Managed) error { return nil }),
+		newServiceFn: func(creds map[string]string) (*TrogdorAgentService, error) {
+			got = creds
+			return NewTrogdorService(), nil
+		},
+	}
+	cr := &v1alpha1.KafkaBench{Spec: v1alpha1.KafkaBenchSpec{
+		ResourceSpec: xpv1.ResourceSpec{ProviderConfigReference: &xpv1.Reference{Name: "example"}},
+		SecretRef: &xpv1.SecretKeySelector{
+			SecretReference: xpv1.SecretReference{Namespace: "tarasque", Name: "bench"},
+			Key:             "credentials",
+		},
+		TargetRef: &xpv1.Reference{Name: "target"},
+	}}
+	if _, err := c.Connect(context.TODO(), cr); err != nil {
+		t.Fatalf("c.Connect(...): unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"sasl.mechanism":    "SCRAM-SHA-512",
+		"security.protocol": "SASL_SSL",
+		"sasl.jaas.config":  "bench-jaas",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("c.Connect(...): bench credentials should be merged over those of the KafkaTarget and the ProviderConfig: -want, +got:\n%s\n", diff)
+	}
+}
+
+func TestConnectOpaqueCredentials(t *testing.T) {
+	kube := &test.MockClient{MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+		switch o := obj.(type) {
+		case *apisv1alpha1.ProviderConfig:
+			o.Spec.Credentials.Source = xpv1.CredentialsSourceSecret
+			o.Spec.Credentials.SecretRef = &xpv1.SecretKeySelector{
+				SecretReference: xpv1.SecretReference{Namespace: "tarasque", Name: "pc"},
+				Key:             "credentials",
+			}
+		case *corev1.Secret:
+			o.Data = map[string][]byte{"credentials": []byte("test\n")}
+		}
+		return nil
+	}}
+
+	var got map[string]string
+	c := &connector{
+		kube:        kube,
+		usage:       resource.TrackerFn(func(_ context.Context, _ resource.Managed) error { return nil }),
+		targetUsage: resource.TrackerFn(func(_ context.Context, _ resource.Managed) error { return nil }),
+		log:         logging.NewNopLogger(),
+		newServiceFn: func(creds map[string]string) (*TrogdorAgentService, error) {
+			got = creds
+			return NewTrogdorService(), nil
+		},
+	}
+	cr := &v1alpha1.KafkaBench{Spec: v1alpha1.KafkaBenchSpec{
+		ResourceSpec: xpv1.ResourceSpec{ProviderConfigReference: &xpv1.Reference{Name: "example"}},
+	}}
+	if _, err := c.Connect(context.TODO(), cr); err != nil {
+		t.Fatalf("c.Connect(...): ProviderConfig credentials that are not a JSON object should be ignored, got error: %v", err)
+	}
+	if diff := cmp.Diff(map[string]string{}, got); diff != "" {
+		t.Errorf("c.Connect(...): ProviderConfig credentials that are not a JSON object should carry no client configuration: -want, +got:\n%s\n", diff)
+	}
+}
+
+func TestConnectClusterResolutionFailed(t *testing.T) {
+	kube := &test.MockClient{MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+		if pc, ok := obj.(*apisv1alpha1.ProviderConfig); ok {
+			pc.Spec.Credentials.Source = xpv1.CredentialsSourceNone
+			return nil
+		}
+		return errors.New("boom")
+	}}
+	c := &connector{
+		kube:        kube,
+		usage:       resource.TrackerFn(func(_ context.Context, _ resource.Managed) error { return nil }),
+		targetUsage: resource.TrackerFn(func(_ context.Context, _ resource.Managed) error { return nil }),
+		newServiceFn: func(_ map[string]string) (*TrogdorAgentService, error) {
+			t.Fatal("c.Connect(...): no service should be created when the cluster cannot be resolved")
+			return nil, nil
+		},
+	}
+	cr := &v1alpha1.KafkaBench{Spec: v1alpha1.KafkaBenchSpec{
+		ResourceSpec:    xpv1.ResourceSpec{ProviderConfigReference: &xpv1.Reference{Name: "example"}},
+		KafkaClusterRef: &v1alpha1.KafkaClusterReference{Operator: v1alpha1.StrimziOperator, Namespace: "kafka", Name: "my-cluster", Listener: "tls"},
+	}}
+	if _, err := c.Connect(context.TODO(), cr); err == nil {
+		t.Fatal("c.Connect(...): expected an error")
+	}
+	if got := cr.GetCondition(v1alpha1.TypeClusterResolved); got.Reason != v1alpha1.ReasonResolutionFailure {
+		t.Errorf("c.Connect(...): want %s condition with reason %s, got %+v", v1alpha1.TypeClusterResolved, v1alpha1.ReasonResolutionFailure, got)
+	}
+}
+
+func TestConnectTargetAndCluster(t *testing.T) {
+	c := &connector{
+		kube:        &test.MockClient{MockGet: test.NewMockGetFn(nil)},
+		usage:       resource.TrackerFn(func(_ context.Context, _ resource.Managed) error { return nil }),
+		targetUsage: resource.TrackerFn(func(_ context.Context, _ resource.Managed) error { return nil }),
+		newServiceFn: func(_ map[string]string) (*TrogdorAgentService, error) {
+			t.Fatal("c.Connect(...): no service should be created for a bench referencing both a KafkaTarget and a Kafka cluster")
+			return nil, nil
+		},
+	}
+	cr := &v1alpha1.KafkaBench{Spec: v1alpha1.KafkaBenchSpec{
+		ResourceSpec:    xpv1.ResourceSpec{ProviderConfigReference: &xpv1.Reference{Name: "example"}},
+		TargetRef:       &xpv1.Reference{Name: "target"},
+		KafkaClusterRef: &v1alpha1.KafkaClusterReference{Operator: v1alpha1.StrimziOperator, Namespace: "kafka", Name: "my-cluster", Listener: "tls"},
+	}}
+	if _, err := c.Connect(context.TODO(), cr); err == nil {
+		t.Fatal("c.Connect(...): expected an error for a bench referencing both a KafkaTarget and a Kafka cluster")
+	}
+}
+
+func TestConnectNamespacedTarget(t *testing.T) {
+	kube := &test.MockClient{MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+		switch o := obj.(type) {
+		case *apisv1alpha1.ProviderConfig:
+			o.Spec.Credentials.Source = xpv1.CredentialsSourceNone
+		case *v1alpha1.KafkaTarget:
+			o.Spec.SecretRef = &xpv1.SecretKeySelector{
+				SecretReference: xpv1.SecretReference{Namespace: "team-b", Name: "creds"},
+				Key:             "credentials",
+			}
+		case *corev1.Secret:
+			t.Fatal("c.Connect(...): a namespaced bench should not read the Secret of a KafkaTarget")
+		}
+		return nil
+	}}
+	c := &connector{
+		kube:        kube,
+		usage:       resource.TrackerFn(func(_ context.Context, _ resource.Managed) error { return nil }),
+		targetUsage: resource.TrackerFn(func(_ context.Context, _ resource.Managed) error { return nil }),
+		newServiceFn: func(_ map[string]string) (*TrogdorAgentService, error) {
+			t.Fatal("c.Connect(...): no service should be created for a namespaced bench referencing a KafkaTarget")
+			return nil, nil
+		},
+	}
+	cr := &v1alpha1.NamespacedKafkaBench{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "bench"},
+		Spec: v1alpha1.KafkaBenchSpec{
+			ResourceSpec: xpv1.ResourceSpec{ProviderConfigReference: &xpv1.Reference{Name: "example"}},
+			TargetRef:    &xpv1.Reference{Name: "shared"},
+		},
+	}
+	if _, err := c.Connect(context.TODO(), cr); err == nil {
+		t.Fatal("c.Connect(...): expected an error for a namespaced bench referencing a KafkaTarget")
+	}
+}
+
+func TestNoCredentialsOnStdout(t *testing.T) {
+	const (
+		password   = "s3cr3t-pw"
+		keyPass    = "k3y-pw"
+		rawPass    = "r4w-pw"
+		workerFail = 1111
+		workerRaw  = 2222
+	)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+	log := logging.NewLogrLogger(zap.New(zap.UseDevMode(true), zap.WriteTo(os.Stdout)))
+
+	httpClient := resty.New()
+	svc := newTrogdorServiceWithRestClient(httpClient, &mockResolver{[]string{defaultAgentServiceName}, nil}).
+		WithCredentials(map[string]string{"sasl.jaas.config": `PlainLoginModule required username="u" password="` + password + `";`})
+	httpmock.ActivateNonDefault(httpClient.GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", agentServiceURL+"/agent/worker/create", httpmock.NewStringResponder(200, "{}"))
+	httpmock.RegisterResponder("GET", agentServiceURL+"/agent/status",
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponse(200, trogdor.AgentStatusResponse{Workers: map[string]trogdor.AgentStatusWorkers{
+				strconv.Itoa(workerFail): {State: "DONE", Error: `invalid sasl.jaas.config: password="` + password + `"`},
+				strconv.Itoa(workerRaw): {State: "RUNNING", Status: map[string]interface{}{
+					"config": map[string]interface{}{"ssl.truststore.password": rawPass},
+				}},
+			}})
+		},
+	)
+	e := redact.ExternalClient(&external{service: svc, record: &benchestest.Recorder{}, log: log})
+
+	benches := map[string]*v1alpha1.KafkaBench{
+		"Typed": {
+			Spec: v1alpha1.KafkaBenchSpec{KafkaBenchParameters: v1alpha1.KafkaBenchParameters{
+				Class:            producerWorkload,
+				CommonClientConf: map[string]string{"ssl.key.password": keyPass},
+			}},
+		},
+		"Raw": {
+			Spec: v1alpha1.KafkaBenchSpec{KafkaBenchParameters: v1alpha1.KafkaBenchParameters{
+				RawSpec: &runtime.RawExtension{Raw: []byte(`{"class":"org.example.Spec","commonClientConf":{"ssl.truststore.password":"` + rawPass + `"}}`)},
+			}},
+		},
+	}
+
+	var surfaced []string
+	for name, cr := range benches {
+		if _, err := e.Create(context.TODO(), cr); err != nil {
+			t.Fatalf("%s: e.Create(...): unexpected error: %v", name, err)
+		}
+		cr.Status.AtProvider.WorkerID = workerRaw
+		if name == "Typed" {
+			cr.Status.AtProvider.WorkerID = workerFail
+		}
+		if _, err := e.Observe(context.TODO(), cr); err != nil {
+			surfaced = append(surfaced, err.Error())
+		}
+		if _, err := e.Update(context.TODO(), cr); err != nil {
+			surfaced = append(surfaced, err.Error())
+		}
+		if err := e.Delete(context.TODO(), cr); err != nil {
+			surfaced = append(surfaced, err.Error())
+		}
+		status, err := json.Marshal(cr.Status)
+		if err != nil {
+			t.Fatal(err)
+		}
+		surfaced = append(surfaced, string(status))
+	}
+
+	_ = w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) == 0 {
+		t.Fatal("expected debug logs on stdout")
+	}
+	surfaced = append(surfaced, string(out))
+
+	for _, s := range surfaced {
+		for _, secret := range []string{password, keyPass, rawPass} {
+			if strings.Contains(s, secret) {
+				t.Errorf("credential %q surfaced in:\n%s", secret, s)
+			}
+		}
+	}
+}
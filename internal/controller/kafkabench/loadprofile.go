@@ -0,0 +1,163 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkabench
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/benches"
+	"github.com/nachomdo/tarasque/internal/loadprofile"
+)
+
+const (
+	errGetConfigMap = "cannot get the load profile ConfigMap"
+	errParseReplay  = "cannot parse the load profile replay"
+	errNoSegments   = "the load profile of the bench has no segments"
+	errNotProducer  = "load profiles are only supported by " + v1alpha1.ProduceBenchClass + " workloads"
+	errStartSegment = "cannot start the next segment of the bench"
+)
+
+// segments returns the segments of the load profile of the supplied bench,
+// run with the supplied parameters, or nil if it has none.
+func (c *external) segments(ctx context.Context, cr bench, params v1alpha1.KafkaBenchParameters) ([]loadprofile.Segment, error) {
+	p := localSpec(cr).LoadProfile
+	if p == nil {
+		return nil, nil
+	}
+	if params.Class != producerWorkload || params.RawSpec != nil {
+		return nil, errors.New(errNotProducer)
+	}
+	var replay []v1alpha1.LoadPoint
+	if r := p.Replay; r != nil {
+		cm := &corev1.ConfigMap{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: r.ConfigMapRef.Namespace, Name: r.ConfigMapRef.Name}, cm); err != nil {
+			return nil, errors.Wrap(err, errGetConfigMap)
+		}
+		points, err := loadprofile.ParseCSV([]byte(cm.Data[r.ConfigMapRef.Key]))
+		if err != nil {
+			return nil, errors.Wrap(err, errParseReplay)
+		}
+		replay = points
+	}
+	segs := loadprofile.Segments(p, params.DurationMs, replay)
+	if len(segs) == 0 {
+		return nil, errors.New(errNoSegments)
+	}
+	return segs, nil
+}
+
+// loadSegments returns the status of the supplied segments before they run,
+// or nil if there are none.
+func loadSegments(segs []loadprofile.Segment) []v1alpha1.LoadSegment {
+	if len(segs) == 0 {
+		return nil
+	}
+	ls := make([]v1alpha1.LoadSegment, len(segs))
+	for i, s := range segs {
+		ls[i] = v1alpha1.LoadSegment{OffsetMs: s.OffsetMs, DurationMs: s.DurationMs, TargetMessagesPerSec: s.MessagesPerSec}
+	}
+	return ls
+}
+
+// nextSegment records the results of the current segment of a bench with a
+// load profile and, once it is done, starts the next one. The stats of the
+// bench add up those of every segment once the last one is done.
+func (c *external) nextSegment(cr bench) error {
+	obs := &cr.GetBenchStatus().AtProvider
+	if len(obs.Segments) == 0 || benches.WarmingUp(*obs) {
+		return nil
+	}
+	cur := &obs.Segments[obs.CurrentSegment]
+	cur.TaskStatus = obs.TaskStatus
+	cur.ProducerStats = obs.ProducerStats
+	cur.CompletionTime = obs.CompletionTime
+	cur.MessagesPerSec = rate(obs.ProducerStats.TotalSent, cur.StartTime, cur.CompletionTime)
+	if !benches.SegmentsLeft(*obs) {
+		if obs.TaskStatus == taskStatusDone {
+			obs.ProducerStats = total(obs.Segments)
+		}
+		return nil
+	}
+
+	params, err := c.parameters(cr)
+	if err != nil {
+		return errors.Wrap(err, errStartSegment)
+	}
+	next := &obs.Segments[obs.CurrentSegment+1]
+	s := loadprofile.Segment{OffsetMs: next.OffsetMs, DurationMs: next.DurationMs, MessagesPerSec: next.TargetMessagesPerSec}
+	workerTask, err := c.service.CreateWorkerTask(loadprofile.Parameters(params, s))
+	if err != nil {
+		return errors.Wrap(err, errStartSegment)
+	}
+	// The worker of the previous segment is no longer needed.
+	previous := strconv.FormatInt(obs.WorkerID, 10)
+	if err := c.service.DeleteWorkerTask(previous); err != nil {
+		c.log.Debug("Cannot delete the worker of the previous segment", "name", cr.GetName(), "workerId", previous, "error", err)
+	}
+	c.log.Debug("Started segment", "name", cr.GetName(), "segment", obs.CurrentSegment+1, "workerId", workerTask.WorkerID, "targetMessagesPerSec", s.MessagesPerSec)
+
+	obs.CurrentSegment++
+	track(obs, workerTask)
+	next.WorkerID = workerTask.WorkerID
+	next.StartTime = &metav1.Time{Time: time.UnixMilli(workerTask.Spec.StartMs)}
+	return nil
+}
+
+// rate returns the supplied messages per second between the supplied times,
+// or zero if either is unknown.
+func rate(messages int64, start, end *metav1.Time) int64 {
+	if start == nil || end == nil || !end.After(start.Time) {
+		return 0
+	}
+	return int64(float64(messages) / end.Sub(start.Time).Seconds())
+}
+
+// total returns the stats of the supplied segments together. Latency
+// percentiles are the worst of the segments, and the average latency is
+// weighted by the messages of each segment.
+func total(segs []v1alpha1.LoadSegment) v1alpha1.ProducerBenchResultStats {
+	t := v1alpha1.ProducerBenchResultStats{}
+	weighted := 0.0
+	for _, s := range segs {
+		ps := s.ProducerStats
+		t.TotalSent += ps.TotalSent
+		t.TransactionsCommitted += ps.TransactionsCommitted
+		weighted += ps.AverageLatencyMs * float64(ps.TotalSent)
+		t.P50LatencyMs = max(t.P50LatencyMs, ps.P50LatencyMs)
+		t.P95LatencyMs = max(t.P95LatencyMs, ps.P95LatencyMs)
+		t.P99LatencyMs = max(t.P99LatencyMs, ps.P99LatencyMs)
+	}
+	if t.TotalSent > 0 {
+		t.AverageLatencyMs = weighted / float64(t.TotalSent)
+	}
+	return t
+}
+
+func max(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
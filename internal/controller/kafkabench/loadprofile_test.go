@@ -0,0 +1,145 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkabench
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jarcoal/httpmock"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/benches"
+	"github.com/nachomdo/tarasque/internal/clients/trogdor"
+)
+
+// dispatched is the rate and length of a worker task sent to the agents.
+type dispatched struct {
+	TargetMessagesPerSec int32 `json:"targetMessagesPerSec"`
+	DurationMs           int64 `json:"durationMs"`
+	MaxMessages          int64 `json:"maxMessages"`
+}
+
+func TestLoadProfile(t *testing.T) {
+	httpClient := resty.New()
+	svc := newTrogdorServiceWithRestClient(httpClient, &mockResolver{[]string{defaultAgentServiceName}, nil})
+	httpmock.ActivateNonDefault(httpClient.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	var created []dispatched
+	deleted := []string{}
+	httpmock.RegisterResponder("POST", agentServiceURL+"/agent/worker/create", func(req *http.Request) (*http.Response, error) {
+		task := struct {
+			Spec dispatched `json:"spec"`
+		}{}
+		if err := json.NewDecoder(req.Body).Decode(&task); err != nil {
+			return nil, err
+		}
+		created = append(created, task.Spec)
+		return httpmock.NewStringResponse(200, "{}"), nil
+	})
+	httpmock.RegisterResponder("DELETE", agentServiceURL+"/agent/worker", func(req *http.Request) (*http.Response, error) {
+		deleted = append(deleted, req.URL.Query().Get("workerId"))
+		return httpmock.NewStringResponse(200, "{}"), nil
+	})
+
+	cr := &v1alpha1.KafkaBench{Spec: v1alpha1.KafkaBenchSpec{
+		KafkaBenchParameters: v1alpha1.KafkaBenchParameters{Class: producerWorkload, DurationMs: 120000},
+		LoadProfile: &v1alpha1.LoadProfile{
+			Replay: &v1alpha1.ReplayProfile{ConfigMapRef: v1alpha1.ConfigMapKeySelector{Name: "traffic", Namespace: "bench", Key: "rates.csv"}},
+		},
+	}}
+	sent := int64(60000)
+	httpmock.RegisterResponder("GET", agentServiceURL+"/agent/status", func(req *http.Request) (*http.Response, error) {
+		obs := cr.Status.AtProvider
+		start := obs.Segments[obs.CurrentSegment].StartTime.UnixMilli()
+		return httpmock.NewJsonResponse(200, trogdor.AgentStatusResponse{Workers: map[string]trogdor.AgentStatusWorkers{
+			strconv.FormatInt(obs.WorkerID, 10): {
+				State:     "DONE",
+				StartedMs: start,
+				DoneMs:    start + 60000,
+				Status:    map[string]interface{}{"totalSent": sent, "p99LatencyMs": 10 * (obs.CurrentSegment + 1)},
+			},
+		}})
+	})
+
+	kube := &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			if diff := cmp.Diff(client.ObjectKey{Namespace: "bench", Name: "traffic"}, key); diff != "" {
+				t.Errorf("kube.Get(...): -want key, +got key:\n%s\n", diff)
+			}
+			obj.(*corev1.ConfigMap).Data = map[string]string{"rates.csv": "offsetMs,messagesPerSec\n0,1000\n60000,2000\n"}
+			return nil
+		},
+	}
+	e := external{kube: kube, service: svc, log: logging.NewNopLogger()}
+
+	if _, err := e.Create(context.TODO(), cr); err != nil {
+		t.Fatalf("e.Create(...): unexpected error: %v", err)
+	}
+	first := cr.Status.AtProvider.WorkerID
+	cr.Status.AtProvider.ConsumerStats = map[string]v1alpha1.ConsumerBenchResultStats{"stale": {TotalMessagesReceived: 42}}
+
+	// The first segment is done and the second one starts.
+	if _, err := e.Update(context.TODO(), cr); err != nil {
+		t.Fatalf("e.Update(...): unexpected error: %v", err)
+	}
+	if cr.Status.AtProvider.CurrentSegment != 1 {
+		t.Errorf("e.Update(...): the second segment should start once the first is done")
+	}
+	if cr.Status.AtProvider.ConsumerStats != nil || cr.Status.AtProvider.RawStatus != nil {
+		t.Errorf("e.Update(...): the second segment should not report what the first one did")
+	}
+	if diff := cmp.Diff([]string{strconv.FormatInt(first, 10)}, deleted); diff != "" {
+		t.Errorf("e.Update(...): the worker of the previous segment should be deleted: -want, +got:\n%s\n", diff)
+	}
+
+	// The second segment is done and so is the bench.
+	sent = 120000
+	if _, err := e.Update(context.TODO(), cr); err != nil {
+		t.Fatalf("e.Update(...): unexpected error: %v", err)
+	}
+
+	want := []dispatched{
+		{TargetMessagesPerSec: 1000, DurationMs: 60000, MaxMessages: 60000},
+		{TargetMessagesPerSec: 2000, DurationMs: 60000, MaxMessages: 120000},
+	}
+	if diff := cmp.Diff(want, created); diff != "" {
+		t.Errorf("e.Create(...), e.Update(...): each segment should be dispatched at its rate: -want, +got:\n%s\n", diff)
+	}
+	obs := cr.Status.AtProvider
+	if diff := cmp.Diff([]int64{1000, 2000}, []int64{obs.Segments[0].MessagesPerSec, obs.Segments[1].MessagesPerSec}); diff != "" {
+		t.Errorf("e.Update(...): -want segment rates, +got segment rates:\n%s\n", diff)
+	}
+	wantStats := v1alpha1.ProducerBenchResultStats{TotalSent: 180000, P99LatencyMs: 20}
+	if diff := cmp.Diff(wantStats, obs.ProducerStats); diff != "" {
+		t.Errorf("e.Update(...): the stats of the bench should add up those of its segments: -want, +got:\n%s\n", diff)
+	}
+	if benches.SegmentsLeft(obs) || !benches.Finished(obs.TaskStatus) {
+		t.Errorf("e.Update(...): the bench should be finished after its last segment")
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkabench
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	apisv1alpha1 "github.com/nachomdo/tarasque/apis/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/controller/kafkatarget"
+)
+
+const (
+	managedFinalizerName = "finalizer.managedresource.crossplane.io"
+
+	errDeleteUsage         = "cannot delete usage"
+	errFmtForeignNamespace = "connection secret namespace %q differs from the namespace %q of the bench"
+)
+
+// SetupNamespaced adds a controller that reconciles NamespacedKafkaBench
+// managed resources.
+func SetupNamespaced(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter) error {
+	name := managed.ControllerName(v1alpha1.NamespacedKafkaBenchGroupKind)
+
+	o := controller.Options{
+		RateLimiter: ratelimiter.NewDefaultManagedRateLimiter(rl),
+	}
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.NamespacedKafkaBenchGroupVersionKind),
+		managed.WithExternalConnecter(&connector{
+			kube:         mgr.GetClient(),
+			usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			targetUsage:  kafkatarget.NewUsageTracker(mgr.GetClient()),
+			record:       event.NewAPIRecorder(mgr.GetEventRecorderFor(name)),
+			log:          l.WithValues("controller", name),
+			newServiceFn: newTrogdorAgentService}),
+		managed.WithFinalizer(&usageFinalizer{
+			Finalizer: resource.NewAPIFinalizer(mgr.GetClient(), managedFinalizerName),
+			kube:      mgr.GetClient(),
+		}),
+		managed.WithConnectionPublishers(&localPublisher{
+			ConnectionPublisher: managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme()),
+		}),
+		managed.WithLogger(l.WithValues("controller", name)),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o).
+		For(&v1alpha1.NamespacedKafkaBench{}).
+		Watches(&source.Kind{Type: &v1alpha1.KafkaBench{}}, handler.EnqueueRequestsFromMapFunc(queuedRequests(listNamespacedKafkaBenches(mgr.GetClient())))).
+		Watches(&source.Kind{Type: &v1alpha1.NamespacedKafkaBench{}}, handler.EnqueueRequestsFromMapFunc(queuedRequests(listNamespacedKafkaBenches(mgr.GetClient())))).
+		Complete(r)
+}
+
+// localSpec returns the spec of the supplied bench. The Secrets, ConfigMap and
+// Kafka cluster referenced by a namespaced bench are always read from its own
+// namespace, so that a team cannot use those of another.
+func localSpec(cr bench) *v1alpha1.KafkaBenchSpec {
+	ns := cr.GetNamespace()
+	if ns == "" {
+		return cr.GetBenchSpec()
+	}
+	spec := cr.GetBenchSpec().DeepCopy()
+	if spec.SecretRef != nil {
+		spec.SecretRef.Namespace = ns
+	}
+	if spec.TLS != nil {
+		spec.TLS.SecretRef.Namespace = ns
+	}
+	if spec.KafkaClusterRef != nil {
+		spec.KafkaClusterRef.Namespace = ns
+	}
+	if p := spec.LoadProfile; p != nil && p.Replay != nil {
+		p.Replay.ConfigMapRef.Namespace = ns
+	}
+	return spec
+}
+
+// A usageFinalizer deletes the usages of a namespaced bench before removing
+// its finalizer. Usages are cluster scoped, so they are not garbage collected
+// along with the namespaced bench that controls them.
+type usageFinalizer struct {
+	resource.Finalizer
+	kube client.Writer
+}
+
+// RemoveFinalizer deletes the ProviderConfigUsage and KafkaTargetUsage of the
+// supplied bench, then removes its finalizer.
+func (f *usageFinalizer) RemoveFinalizer(ctx context.Context, obj resource.Object) error {
+	for _, u := range []client.Object{&apisv1alpha1.ProviderConfigUsage{}, &v1alpha1.KafkaTargetUsage{}} {
+		u.SetName(string(obj.GetUID()))
+		if err := f.kube.Delete(ctx, u); resource.IgnoreNotFound(err) != nil {
+			return errors.Wrap(err, errDeleteUsage)
+		}
+	}
+	return f.Finalizer.RemoveFinalizer(ctx, obj)
+}
+
+// A localPublisher refuses to publish the connection details of a namespaced
+// bench outside of its namespace.
+type localPublisher struct {
+	managed.ConnectionPublisher
+}
+
+// PublishConnection details of the supplied bench, provided they are written
+// to its own namespace.
+func (p *localPublisher) PublishConnection(ctx context.Context, mg resource.Managed, c managed.ConnectionDetails) error {
+	if ref := mg.GetWriteConnectionSecretToReference(); ref != nil && ref.Namespace != mg.GetNamespace() {
+		return errors.Errorf(errFmtForeignNamespace, ref.Namespace, mg.GetNamespace())
+	}
+	return p.ConnectionPublisher.PublishConnection(ctx, mg, c)
+}
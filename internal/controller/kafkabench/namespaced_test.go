@@ -0,0 +1,106 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkabench
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	apisv1alpha1 "github.com/nachomdo/tarasque/apis/v1alpha1"
+)
+
+func TestLocalSpec(t *testing.T) {
+	spec := v1alpha1.KafkaBenchSpec{
+		SecretRef:       &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Namespace: "team-b", Name: "creds"}, Key: "credentials"},
+		TLS:             &v1alpha1.KafkaBenchTLS{SecretRef: xpv1.SecretReference{Namespace: "team-b", Name: "tls"}},
+		KafkaClusterRef: &v1alpha1.KafkaClusterReference{Operator: v1alpha1.StrimziOperator, Namespace: "kafka", Name: "my-cluster", Listener: "tls"},
+	}
+
+	cluster := &v1alpha1.KafkaBench{Spec: spec}
+	if diff := cmp.Diff(&spec, localSpec(cluster)); diff != "" {
+		t.Errorf("localSpec(...): cluster scoped benches should be left as is: -want, +got:\n%s\n", diff)
+	}
+
+	namespaced := &v1alpha1.NamespacedKafkaBench{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"}, Spec: *spec.DeepCopy()}
+	got := localSpec(namespaced)
+	for _, ns := range []string{got.SecretRef.Namespace, got.TLS.SecretRef.Namespace, got.KafkaClusterRef.Namespace} {
+		if ns != "team-a" {
+			t.Errorf("localSpec(...): want references in namespace team-a, got %q", ns)
+		}
+	}
+	if namespaced.Spec.SecretRef.Namespace != "team-b" {
+		t.Errorf("localSpec(...): the spec of the bench should not be modified")
+	}
+}
+
+func TestUsageFinalizer(t *testing.T) {
+	var deleted []string
+	f := &usageFinalizer{
+		Finalizer: resource.FinalizerFns{RemoveFinalizerFn: func(_ context.Context, _ resource.Object) error {
+			deleted = append(deleted, "finalizer")
+			return nil
+		}},
+		kube: &test.MockClient{MockDelete: func(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+			switch obj.(type) {
+			case *apisv1alpha1.ProviderConfigUsage:
+				deleted = append(deleted, "ProviderConfigUsage/"+obj.GetName())
+			case *v1alpha1.KafkaTargetUsage:
+				deleted = append(deleted, "KafkaTargetUsage/"+obj.GetName())
+			}
+			return kerrors.NewNotFound(schema.GroupResource{}, obj.GetName())
+		}},
+	}
+	cr := &v1alpha1.NamespacedKafkaBench{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "bench", UID: "uid"}}
+	if err := f.RemoveFinalizer(context.Background(), cr); err != nil {
+		t.Fatalf("f.RemoveFinalizer(...): unexpected error: %v", err)
+	}
+	want := []string{"ProviderConfigUsage/uid", "KafkaTargetUsage/uid", "finalizer"}
+	if diff := cmp.Diff(want, deleted); diff != "" {
+		t.Errorf("f.RemoveFinalizer(...): usages should be deleted before the finalizer: -want, +got:\n%s\n", diff)
+	}
+}
+
+func TestLocalPublisher(t *testing.T) {
+	published := false
+	p := &localPublisher{ConnectionPublisher: managed.ConnectionPublisherFns{
+		PublishConnectionFn: func(_ context.Context, _ resource.Managed, _ managed.ConnectionDetails) error {
+			published = true
+			return nil
+		},
+	}}
+	cr := &v1alpha1.NamespacedKafkaBench{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"}}
+	cr.SetWriteConnectionSecretToReference(&xpv1.SecretReference{Namespace: "team-b", Name: "conn"})
+	if err := p.PublishConnection(context.Background(), cr, managed.ConnectionDetails{}); err == nil || published {
+		t.Errorf("p.PublishConnection(...): connection details should not be published to another namespace")
+	}
+	cr.SetWriteConnectionSecretToReference(&xpv1.SecretReference{Namespace: "team-a", Name: "conn"})
+	if err := p.PublishConnection(context.Background(), cr, managed.ConnectionDetails{}); err != nil || !published {
+		t.Errorf("p.PublishConnection(...): connection details should be published to the namespace of the bench: %v", err)
+	}
+}
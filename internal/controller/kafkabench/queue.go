@@ -0,0 +1,391 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkabench
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	apisv1alpha1 "github.com/nachomdo/tarasque/apis/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/benches"
+)
+
+const (
+	taskStatusQueued = "QUEUED"
+	taskStatusDone   = "DONE"
+	// taskStatusRefused is the task status of a bench of a group that does
+	// not fit within the concurrency limits of the agent pool.
+	taskStatusRefused = "REFUSED"
+
+	// reservationTTL is how long a bench admitted by the queue counts as
+	// running before its task shows up in the cache.
+	reservationTTL = 30 * time.Second
+
+	errListBenches = "cannot list benches"
+	errListPCs     = "cannot list ProviderConfigs"
+)
+
+// queue is the queue of the agent pool, shared by the KafkaBench and
+// NamespacedKafkaBench controllers.
+var queue = &benchQueue{reserved: map[types.UID]time.Time{}}
+
+// SetMaxConcurrentBenches limits the benches that run at once across the
+// agent pool. A limit of 0 means no limit.
+func SetMaxConcurrentBenches(n int32) {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	queue.max = n
+}
+
+// A queueEntry is a bench as seen by the queue.
+type queueEntry struct {
+	uid            types.UID
+	name           string
+	namespace      string
+	providerConfig string
+	priority       int32
+	created        metav1.Time
+	running        bool
+	// group is the group the bench belongs to, if any, and groupSize the
+	// number of members of that group, or 0 if unknown.
+	group     string
+	groupSize int
+}
+
+func newQueueEntry(cr bench) queueEntry {
+	e := queueEntry{
+		uid:       cr.GetUID(),
+		name:      cr.GetName(),
+		namespace: cr.GetNamespace(),
+		priority:  cr.GetBenchSpec().Priority,
+		created:   cr.GetCreationTimestamp(),
+	}
+	if ref := cr.GetProviderConfigReference(); ref != nil {
+		e.providerConfig = ref.Name
+	}
+	if g, ok := cr.GetAnnotations()[v1alpha1.AnnotationKeyGroup]; ok {
+		e.group = g
+		// Groups created before their size was recorded are placed in the
+		// pool as their members are seen.
+		e.groupSize, _ = strconv.Atoi(cr.GetAnnotations()[v1alpha1.AnnotationKeyGroupSize])
+	}
+	obs := cr.GetBenchStatus().AtProvider
+	// Benches waiting for their group keep the place they were given.
+	e.running = (obs.TaskID != "" && !benches.Finished(obs.TaskStatus)) || obs.TaskStatus == taskStatusWaiting
+	return e
+}
+
+// queued returns whether the supplied bench waits for a task to be created.
+// Benches scheduled to start later, or that missed their start window, do not
+// wait for the agent pool.
+func queued(cr bench) bool {
+	return undispatched(cr) && cr.GetBenchStatus().AtProvider.TaskStatus != taskStatusScheduled && cr.GetBenchStatus().AtProvider.TaskStatus != taskStatusMissed
+}
+
+// undispatched returns whether no task was created for the supplied bench
+// yet.
+func undispatched(cr bench) bool {
+	return cr.GetBenchStatus().AtProvider.TaskID == "" && !meta.WasDeleted(cr)
+}
+
+// A benchQueue admits benches to the agent pool while it is below its limits
+// of concurrent benches.
+type benchQueue struct {
+	mu  sync.Mutex
+	max int32
+	// reserved holds the benches admitted recently, which count as running
+	// until the cache catches up with their task.
+	reserved map[types.UID]time.Time
+}
+
+// Position returns the position of the supplied bench in the queue, starting
+// at 1, or 0 when it may start now. A bench that may start is reserved a
+// place in the pool. Position also returns true when the bench belongs to a
+// group that will never fit within the limits of the pool.
+func (q *benchQueue) Position(ctx context.Context, kube client.Reader, cr bench) (int32, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries, err := listQueueEntries(ctx, kube)
+	if err != nil {
+		return 0, false, err
+	}
+	pcs := &apisv1alpha1.ProviderConfigList{}
+	if err := kube.List(ctx, pcs); err != nil {
+		return 0, false, errors.Wrap(err, errListPCs)
+	}
+	limits := map[string]*apisv1alpha1.ConcurrencyLimits{}
+	for _, pc := range pcs.Items {
+		limits[pc.GetName()] = pc.Spec.Concurrency
+	}
+
+	// The supplied bench is fresher than the cache.
+	self := newQueueEntry(cr)
+	found := false
+	now := time.Now()
+	for i := range entries {
+		e := &entries[i]
+		if e.uid == self.uid {
+			*e = self
+			found = true
+		}
+		if t, ok := q.reserved[e.uid]; ok && !e.running && now.Sub(t) < reservationTTL {
+			e.running = true
+		}
+	}
+	if !found {
+		entries = append(entries, self)
+	}
+	for uid, t := range q.reserved {
+		if now.Sub(t) >= reservationTTL {
+			delete(q.reserved, uid)
+		}
+	}
+
+	pos, refused := position(entries, self.uid, q.max, limits)
+	if pos == 0 && !refused {
+		q.reserved[self.uid] = now
+	}
+	return pos, refused, nil
+}
+
+// position returns the position of the bench with the supplied UID among the
+// queued entries, or 0 when it fits within the limits. Queued benches are
+// ordered by decreasing priority then by creation, and those ahead of the
+// bench that fit take their place in the pool first. The benches of a group
+// take their place together, once all of them are queued, so that a group
+// never holds part of the pool while the rest of its members wait. position
+// also returns true when the bench belongs to a group that does not fit
+// within the limits even in an empty pool.
+func position(entries []queueEntry, uid types.UID, max int32, limits map[string]*apisv1alpha1.ConcurrencyLimits) (int32, bool) {
+	used := newPoolUsage()
+	waiting := []queueEntry{}
+	members := map[string][]queueEntry{}
+	for _, e := range entries {
+		if e.group != "" {
+			members[e.group] = append(members[e.group], e)
+		}
+		if e.running {
+			used.add(e)
+			continue
+		}
+		waiting = append(waiting, e)
+	}
+	sortQueued(waiting)
+
+	var pos int32
+	for _, unit := range units(waiting) {
+		mine := includes(unit, uid)
+		g := unit[0].group
+		switch {
+		case g != "" && !newPoolUsage().fits(members[g], max, limits):
+			// The group will never start, so it holds no place.
+			if mine {
+				return 0, true
+			}
+		case len(members[g]) >= unit[0].groupSize && used.fits(unit, max, limits):
+			if mine {
+				return 0, false
+			}
+			used.add(unit...)
+		default:
+			pos++
+			if mine {
+				return pos, false
+			}
+		}
+	}
+	return 0, false
+}
+
+// sortQueued orders the supplied queued entries by decreasing priority then by
+// creation.
+func sortQueued(waiting []queueEntry) {
+	sort.SliceStable(waiting, func(i, j int) bool {
+		a, b := waiting[i], waiting[j]
+		if a.priority != b.priority {
+			return a.priority > b.priority
+		}
+		if !a.created.Equal(&b.created) {
+			return a.created.Before(&b.created)
+		}
+		if a.namespace != b.namespace {
+			return a.namespace < b.namespace
+		}
+		return a.name < b.name
+	})
+}
+
+// includes returns whether the bench with the supplied UID is among the
+// supplied entries.
+func includes(entries []queueEntry, uid types.UID) bool {
+	for _, e := range entries {
+		if e.uid == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// units returns the supplied queued entries in order, with the members of a
+// group gathered where the first of them is.
+func units(waiting []queueEntry) [][]queueEntry {
+	out := [][]queueEntry{}
+	at := map[string]int{}
+	for _, e := range waiting {
+		if i, ok := at[e.group]; ok && e.group != "" {
+			out[i] = append(out[i], e)
+			continue
+		}
+		at[e.group] = len(out)
+		out = append(out, []queueEntry{e})
+	}
+	return out
+}
+
+// A poolUsage counts the benches holding a place in the agent pool.
+type poolUsage struct {
+	total       int32
+	byPC        map[string]int32
+	byNamespace map[[2]string]int32
+}
+
+func newPoolUsage() *poolUsage {
+	return &poolUsage{byPC: map[string]int32{}, byNamespace: map[[2]string]int32{}}
+}
+
+func (u *poolUsage) add(entries ...queueEntry) {
+	for _, e := range entries {
+		u.total++
+		u.byPC[e.providerConfig]++
+		u.byNamespace[[2]string{e.providerConfig, e.namespace}]++
+	}
+}
+
+// fits returns whether the supplied entries fit together within the supplied
+// limits on top of the benches already counted.
+func (u *poolUsage) fits(entries []queueEntry, max int32, limits map[string]*apisv1alpha1.ConcurrencyLimits) bool {
+	after := newPoolUsage()
+	after.total = u.total
+	for k, v := range u.byPC {
+		after.byPC[k] = v
+	}
+	for k, v := range u.byNamespace {
+		after.byNamespace[k] = v
+	}
+	for _, e := range entries {
+		after.add(e)
+		if max > 0 && after.total > max {
+			return false
+		}
+		l := limits[e.providerConfig]
+		if l == nil {
+			continue
+		}
+		if l.MaxBenches > 0 && after.byPC[e.providerConfig] > l.MaxBenches {
+			return false
+		}
+		if l.MaxBenchesPerNamespace > 0 && after.byNamespace[[2]string{e.providerConfig, e.namespace}] > l.MaxBenchesPerNamespace {
+			return false
+		}
+	}
+	return true
+}
+
+// listQueueEntries returns the KafkaBenches and NamespacedKafkaBenches that
+// run or wait to run.
+func listQueueEntries(ctx context.Context, kube client.Reader) ([]queueEntry, error) {
+	entries := []queueEntry{}
+	for _, list := range []func(context.Context) ([]bench, error){listKafkaBenches(kube), listNamespacedKafkaBenches(kube)} {
+		benches, err := list(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range benches {
+			if e := newQueueEntry(b); e.running || queued(b) {
+				entries = append(entries, e)
+			}
+		}
+	}
+	return entries, nil
+}
+
+// queuedRequests returns a function that enqueues the queued benches returned
+// by the supplied list function whenever a bench that holds a place in the
+// pool changes, so that they start as soon as it frees up.
+func queuedRequests(list func(ctx context.Context) ([]bench, error)) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		if b, ok := obj.(bench); !ok || queued(b) {
+			return nil
+		}
+		benches, err := list(context.Background())
+		if err != nil {
+			return nil
+		}
+		reqs := []reconcile.Request{}
+		for _, b := range benches {
+			if queued(b) {
+				reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: b.GetNamespace(), Name: b.GetName()}})
+			}
+		}
+		return reqs
+	}
+}
+
+// listKafkaBenches returns a function that lists KafkaBenches.
+func listKafkaBenches(kube client.Reader) func(ctx context.Context) ([]bench, error) {
+	return func(ctx context.Context) ([]bench, error) {
+		l := &v1alpha1.KafkaBenchList{}
+		if err := kube.List(ctx, l); err != nil {
+			return nil, errors.Wrap(err, errListBenches)
+		}
+		benches := make([]bench, len(l.Items))
+		for i := range l.Items {
+			benches[i] = &l.Items[i]
+		}
+		return benches, nil
+	}
+}
+
+// listNamespacedKafkaBenches returns a function that lists
+// NamespacedKafkaBenches.
+func listNamespacedKafkaBenches(kube client.Reader) func(ctx context.Context) ([]bench, error) {
+	return func(ctx context.Context) ([]bench, error) {
+		l := &v1alpha1.NamespacedKafkaBenchList{}
+		if err := kube.List(ctx, l); err != nil {
+			return nil, errors.Wrap(err, errListBenches)
+		}
+		benches := make([]bench, len(l.Items))
+		for i := range l.Items {
+			benches[i] = &l.Items[i]
+		}
+		return benches, nil
+	}
+}
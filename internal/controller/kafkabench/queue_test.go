@@ -0,0 +1,294 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkabench
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	apisv1alpha1 "github.com/nachomdo/tarasque/apis/v1alpha1"
+)
+
+func TestPosition(t *testing.T) {
+	t0 := metav1.NewTime(time.Unix(0, 0))
+	t1 := metav1.NewTime(time.Unix(1, 0))
+	t2 := metav1.NewTime(time.Unix(2, 0))
+
+	type args struct {
+		entries []queueEntry
+		uid     types.UID
+		max     int32
+		limits  map[string]*apisv1alpha1.ConcurrencyLimits
+	}
+	type want struct {
+		position int32
+		refused  bool
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Unlimited": {
+			reason: "Benches should start at once without limits.",
+			args: args{
+				entries: []queueEntry{{uid: "a", running: true}, {uid: "b", created: t0}},
+				uid:     "b",
+			},
+			want: want{},
+		},
+		"GlobalLimit": {
+			reason: "Benches should wait while the agent pool runs as many benches as the global limit.",
+			args: args{
+				entries: []queueEntry{{uid: "a", running: true}, {uid: "b", created: t0}, {uid: "c", created: t1}},
+				uid:     "c",
+				max:     1,
+			},
+			want: want{position: 2},
+		},
+		"FreePlace": {
+			reason: "The oldest bench should take a free place.",
+			args: args{
+				entries: []queueEntry{{uid: "a", running: true}, {uid: "b", created: t0}, {uid: "c", created: t1}},
+				uid:     "b",
+				max:     2,
+			},
+			want: want{},
+		},
+		"Priority": {
+			reason: "Benches with a higher priority should start before older benches.",
+			args: args{
+				entries: []queueEntry{{uid: "a", running: true}, {uid: "b", created: t0}, {uid: "c", created: t1, priority: 10}},
+				uid:     "c",
+				max:     2,
+			},
+			want: want{},
+		},
+		"ProviderConfigLimit": {
+			reason: "Benches should wait while their ProviderConfig runs as many benches as its limit.",
+			args: args{
+				entries: []queueEntry{
+					{uid: "a", providerConfig: "small", running: true},
+					{uid: "b", providerConfig: "small", created: t0},
+					{uid: "c", providerConfig: "default", created: t1},
+				},
+				uid:    "b",
+				limits: map[string]*apisv1alpha1.ConcurrencyLimits{"small": {MaxBenches: 1}},
+			},
+			want: want{position: 1},
+		},
+		"OtherProviderConfig": {
+			reason: "Benches should not wait for the limits of other ProviderConfigs.",
+			args: args{
+				entries: []queueEntry{
+					{uid: "a", providerConfig: "small", running: true},
+					{uid: "b", providerConfig: "small", created: t0},
+					{uid: "c", providerConfig: "default", created: t1},
+				},
+				uid:    "c",
+				limits: map[string]*apisv1alpha1.ConcurrencyLimits{"small": {MaxBenches: 1}},
+			},
+			want: want{},
+		},
+		"NamespaceLimit": {
+			reason: "Benches should wait while their namespace runs as many benches as the limit of their ProviderConfig.",
+			args: args{
+				entries: []queueEntry{
+					{uid: "a", namespace: "team-a", running: true},
+					{uid: "b", namespace: "team-a", created: t0},
+					{uid: "c", namespace: "team-b", created: t1},
+					{uid: "d", namespace: "team-a", created: t2},
+				},
+				uid:    "d",
+				limits: map[string]*apisv1alpha1.ConcurrencyLimits{"": {MaxBenchesPerNamespace: 1}},
+			},
+			want: want{position: 2},
+		},
+		"GroupTogether": {
+			reason: "The members of a group should take their place together, or wait together.",
+			args: args{
+				entries: []queueEntry{
+					{uid: "a", running: true},
+					{uid: "b1", created: t0, group: "b", groupSize: 2},
+					{uid: "b2", created: t0, group: "b", groupSize: 2},
+					{uid: "c", created: t1},
+				},
+				uid: "b2",
+				max: 2,
+			},
+			want: want{position: 1},
+		},
+		"GroupAheadOfOthers": {
+			reason: "Benches behind a group that does not fit should take a place that is free.",
+			args: args{
+				entries: []queueEntry{
+					{uid: "a", running: true},
+					{uid: "b1", created: t0, group: "b", groupSize: 2},
+					{uid: "b2", created: t0, group: "b", groupSize: 2},
+					{uid: "c", created: t1},
+				},
+				uid: "c",
+				max: 2,
+			},
+			want: want{},
+		},
+		"GroupIncomplete": {
+			reason: "The members of a group should not take their place before all of them are queued.",
+			args: args{
+				entries: []queueEntry{{uid: "b1", created: t0, group: "b", groupSize: 2}},
+				uid:     "b1",
+				max:     2,
+			},
+			want: want{position: 1},
+		},
+		"GroupTooLarge": {
+			reason: "The members of a group larger than the limits should be refused.",
+			args: args{
+				entries: []queueEntry{
+					{uid: "b1", created: t0, providerConfig: "small", group: "b", groupSize: 3},
+					{uid: "b2", created: t0, providerConfig: "small", group: "b", groupSize: 3},
+					{uid: "b3", created: t0, providerConfig: "small", group: "b", groupSize: 3},
+				},
+				uid:    "b1",
+				limits: map[string]*apisv1alpha1.ConcurrencyLimits{"small": {MaxBenches: 2}},
+			},
+			want: want{refused: true},
+		},
+		"BehindGroupTooLarge": {
+			reason: "A group larger than the limits should not hold back other benches.",
+			args: args{
+				entries: []queueEntry{
+					{uid: "b1", created: t0, group: "b", groupSize: 2},
+					{uid: "b2", created: t0, group: "b", groupSize: 2},
+					{uid: "c", created: t1},
+				},
+				uid: "c",
+				max: 1,
+			},
+			want: want{},
+		},
+		"OtherNamespace": {
+			reason: "A namespace at its limit should not hold back benches of other namespaces.",
+			args: args{
+				entries: []queueEntry{
+					{uid: "a", namespace: "team-a", running: true},
+					{uid: "b", namespace: "team-a", created: t0},
+					{uid: "c", namespace: "team-b", created: t1},
+				},
+				uid:    "c",
+				limits: map[string]*apisv1alpha1.ConcurrencyLimits{"": {MaxBenchesPerNamespace: 1}},
+			},
+			want: want{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pos, refused := position(tc.args.entries, tc.args.uid, tc.args.max, tc.args.limits)
+			if diff := cmp.Diff(tc.want, want{position: pos, refused: refused}, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nposition(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserveQueued(t *testing.T) {
+	running := v1alpha1.KafkaBench{ObjectMeta: metav1.ObjectMeta{Name: "running", UID: "running"}}
+	running.Status.AtProvider.TaskID = "running"
+	running.Status.AtProvider.TaskStatus = "RUNNING"
+	kube := &test.MockClient{MockList: func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+		if l, ok := obj.(*v1alpha1.KafkaBenchList); ok {
+			l.Items = []v1alpha1.KafkaBench{running}
+		}
+		return nil
+	}}
+
+	e := external{kube: kube, queue: &benchQueue{max: 1, reserved: map[types.UID]time.Time{}}, log: logging.NewNopLogger()}
+	cr := &v1alpha1.KafkaBench{ObjectMeta: metav1.ObjectMeta{Name: "queued", UID: "queued"}}
+	got, err := e.Observe(context.TODO(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, got); diff != "" {
+		t.Errorf("e.Observe(...): a queued bench should not be created: -want, +got:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(int32(1), cr.Status.AtProvider.QueuePosition); diff != "" {
+		t.Errorf("e.Observe(...): -want queue position, +got queue position:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(v1alpha1.Queued(1), cr.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+		t.Errorf("e.Observe(...): -want condition, +got condition:\n%s\n", diff)
+	}
+
+	e.queue.max = 2
+	got, err = e.Observe(context.TODO(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(managed.ExternalObservation{ConnectionDetails: managed.ConnectionDetails{}}, got); diff != "" {
+		t.Errorf("e.Observe(...): an admitted bench should be created: -want, +got:\n%s\n", diff)
+	}
+	if _, ok := e.queue.reserved[cr.GetUID()]; !ok {
+		t.Errorf("e.Observe(...): an admitted bench should hold a place until its task is observed")
+	}
+}
+
+func TestObserveGroupTooLarge(t *testing.T) {
+	member := func(name string) v1alpha1.KafkaBench {
+		return v1alpha1.KafkaBench{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			UID:         types.UID(name),
+			Annotations: map[string]string{v1alpha1.AnnotationKeyGroup: "mixed", v1alpha1.AnnotationKeyGroupSize: "3"},
+		}}
+	}
+	kube := &test.MockClient{MockList: func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+		if l, ok := obj.(*v1alpha1.KafkaBenchList); ok {
+			l.Items = []v1alpha1.KafkaBench{member("mixed-a"), member("mixed-b"), member("mixed-c")}
+		}
+		return nil
+	}}
+
+	e := external{kube: kube, queue: &benchQueue{max: 2, reserved: map[types.UID]time.Time{}}, log: logging.NewNopLogger()}
+	cr := member("mixed-a")
+	got, err := e.Observe(context.TODO(), &cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, got); diff != "" {
+		t.Errorf("e.Observe(...): a bench of a group larger than the agent pool should not be created: -want, +got:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(taskStatusRefused, cr.Status.AtProvider.TaskStatus); diff != "" {
+		t.Errorf("e.Observe(...): -want task status, +got task status:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(v1alpha1.GroupTooLarge("mixed"), cr.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+		t.Errorf("e.Observe(...): -want condition, +got condition:\n%s\n", diff)
+	}
+	if len(e.queue.reserved) != 0 {
+		t.Errorf("e.Observe(...): a refused bench should not hold a place in the agent pool")
+	}
+}
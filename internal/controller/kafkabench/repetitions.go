@@ -0,0 +1,170 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkabench
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/benches"
+	"github.com/nachomdo/tarasque/internal/loadprofile"
+	"github.com/nachomdo/tarasque/internal/stats"
+)
+
+const errStartRun = "cannot start the next run of the bench"
+
+// runs returns the status of the runs of the supplied bench before they run,
+// or nil if it runs once.
+func runs(cr bench) []v1alpha1.BenchRun {
+	n := cr.GetBenchSpec().Repetitions
+	if n <= 1 {
+		return nil
+	}
+	return make([]v1alpha1.BenchRun, n)
+}
+
+// nextRun records the results of the current run of a bench with repetitions
+// and, once it is done, starts the next one after the cooldown of the bench.
+// The runs that are done are summed up once the last one is.
+func (c *external) nextRun(cr bench) error {
+	obs := &cr.GetBenchStatus().AtProvider
+	if len(obs.Runs) == 0 || benches.WarmingUp(*obs) || benches.SegmentsLeft(*obs) {
+		return nil
+	}
+	cur := &obs.Runs[obs.CurrentRun]
+	*cur = result(effectiveParameters(cr).Class, *obs, cur.StartTime)
+	if !benches.RunsLeft(*obs) {
+		if benches.Finished(obs.TaskStatus) {
+			obs.Summary = summary(effectiveParameters(cr).Class, obs.Runs)
+		}
+		return nil
+	}
+
+	params, err := c.parameters(cr)
+	if err != nil {
+		return errors.Wrap(err, errStartRun)
+	}
+	workerTask, err := c.service.CreateWorkerTaskAt(restart(obs, params), nextStart(cr).UnixMilli())
+	if err != nil {
+		return errors.Wrap(err, errStartRun)
+	}
+	// The worker of the previous run is no longer needed.
+	previous := strconv.FormatInt(obs.WorkerID, 10)
+	if err := c.service.DeleteWorkerTask(previous); err != nil {
+		c.log.Debug("Cannot delete the worker of the previous run", "name", cr.GetName(), "workerId", previous, "error", err)
+	}
+	c.log.Debug("Started run", "name", cr.GetName(), "run", obs.CurrentRun+1, "workerId", workerTask.WorkerID, "startMs", workerTask.Spec.StartMs)
+
+	obs.CurrentRun++
+	begin(obs, workerTask)
+	return nil
+}
+
+// restart resets the segments of a bench with a load profile, so that they all
+// run again from the first one, and returns the supplied parameters of the
+// bench as dispatched for its first segment.
+func restart(obs *v1alpha1.KafkaBenchObservation, params v1alpha1.KafkaBenchParameters) v1alpha1.KafkaBenchParameters {
+	if len(obs.Segments) == 0 {
+		return params
+	}
+	for i, s := range obs.Segments {
+		obs.Segments[i] = v1alpha1.LoadSegment{OffsetMs: s.OffsetMs, DurationMs: s.DurationMs, TargetMessagesPerSec: s.TargetMessagesPerSec}
+	}
+	first := obs.Segments[0]
+	return loadprofile.Parameters(params, loadprofile.Segment{OffsetMs: first.OffsetMs, DurationMs: first.DurationMs, MessagesPerSec: first.TargetMessagesPerSec})
+}
+
+// nextStart returns when the next run of the supplied bench starts: once its
+// cooldown after the completion of the current run is over, or now.
+func nextStart(cr bench) time.Time {
+	now := time.Now()
+	done := cr.GetBenchStatus().AtProvider.CompletionTime
+	if done == nil {
+		return now
+	}
+	end := done.Add(time.Duration(cr.GetBenchSpec().CooldownMs) * time.Millisecond)
+	if end.After(now) {
+		return end
+	}
+	return now
+}
+
+// result returns the results the supplied observation reports for a run of a
+// workload of the supplied class, started at the supplied time.
+func result(class string, obs v1alpha1.KafkaBenchObservation, start *metav1.Time) v1alpha1.BenchRun {
+	run := v1alpha1.BenchRun{WorkerID: obs.WorkerID, TaskStatus: obs.TaskStatus, StartTime: start, CompletionTime: obs.CompletionTime}
+	var msgs int64
+	switch class {
+	case producerWorkload:
+		ps := obs.ProducerStats
+		msgs = ps.TotalSent
+		run.AverageLatencyMs, run.P50LatencyMs, run.P95LatencyMs, run.P99LatencyMs = ps.AverageLatencyMs, ps.P50LatencyMs, ps.P95LatencyMs, ps.P99LatencyMs
+	case consumerWorkload:
+		weighted := 0.0
+		for _, cs := range obs.ConsumerStats {
+			msgs += cs.TotalMessagesReceived
+			weighted += cs.AverageLatencyMs * float64(cs.TotalMessagesReceived)
+			run.P50LatencyMs = max(run.P50LatencyMs, cs.P50LatencyMs)
+			run.P95LatencyMs = max(run.P95LatencyMs, cs.P95LatencyMs)
+			run.P99LatencyMs = max(run.P99LatencyMs, cs.P99LatencyMs)
+		}
+		if msgs > 0 {
+			run.AverageLatencyMs = weighted / float64(msgs)
+		}
+	case roundTripWorkload:
+		msgs = obs.RoundTripStats.TotalReceived
+	}
+	run.MessagesPerSec = rate(msgs, run.StartTime, run.CompletionTime)
+	return run
+}
+
+// summary returns the summary of the supplied runs that are done, or nil if
+// none is. Latencies are only summed up for workloads of the supplied class
+// that report them.
+func summary(class string, runs []v1alpha1.BenchRun) *v1alpha1.RunSummary {
+	var msgs, avg, p50, p95, p99 []float64
+	for _, r := range runs {
+		if r.TaskStatus != taskStatusDone {
+			continue
+		}
+		msgs = append(msgs, float64(r.MessagesPerSec))
+		avg = append(avg, r.AverageLatencyMs)
+		p50 = append(p50, float64(r.P50LatencyMs))
+		p95 = append(p95, float64(r.P95LatencyMs))
+		p99 = append(p99, float64(r.P99LatencyMs))
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	s := &v1alpha1.RunSummary{Runs: int32(len(msgs)), MessagesPerSec: stats.Summarize(msgs)}
+	if class == producerWorkload || class == consumerWorkload {
+		s.AverageLatencyMs = summarize(avg)
+		s.P50LatencyMs = summarize(p50)
+		s.P95LatencyMs = summarize(p95)
+		s.P99LatencyMs = summarize(p99)
+	}
+	return s
+}
+
+func summarize(values []float64) *v1alpha1.Statistic {
+	s := stats.Summarize(values)
+	return &s
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkabench
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jarcoal/httpmock"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/benches"
+	"github.com/nachomdo/tarasque/internal/clients/trogdor"
+)
+
+func TestRepetitions(t *testing.T) {
+	httpClient := resty.New()
+	svc := newTrogdorServiceWithRestClient(httpClient, &mockResolver{[]string{defaultAgentServiceName}, nil})
+	httpmock.ActivateNonDefault(httpClient.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	var starts []int64
+	deleted := []string{}
+	httpmock.RegisterResponder("POST", agentServiceURL+"/agent/worker/create", func(req *http.Request) (*http.Response, error) {
+		task := struct {
+			Spec struct {
+				StartMs int64 `json:"startMs"`
+			} `json:"spec"`
+		}{}
+		if err := json.NewDecoder(req.Body).Decode(&task); err != nil {
+			return nil, err
+		}
+		starts = append(starts, task.Spec.StartMs)
+		return httpmock.NewStringResponse(200, "{}"), nil
+	})
+	httpmock.RegisterResponder("DELETE", agentServiceURL+"/agent/worker", func(req *http.Request) (*http.Response, error) {
+		deleted = append(deleted, req.URL.Query().Get("workerId"))
+		return httpmock.NewStringResponse(200, "{}"), nil
+	})
+
+	cr := &v1alpha1.KafkaBench{Spec: v1alpha1.KafkaBenchSpec{
+		KafkaBenchParameters: v1alpha1.KafkaBenchParameters{Class: producerWorkload, DurationMs: 10000},
+		Repetitions:          3,
+		CooldownMs:           30000,
+	}}
+	// Each run takes ten seconds, sending more messages at a higher latency
+	// than the previous one.
+	sent := []int64{90000, 100000, 110000}
+	httpmock.RegisterResponder("GET", agentServiceURL+"/agent/status", func(req *http.Request) (*http.Response, error) {
+		obs := cr.Status.AtProvider
+		start := obs.Runs[obs.CurrentRun].StartTime.UnixMilli()
+		return httpmock.NewJsonResponse(200, trogdor.AgentStatusResponse{Workers: map[string]trogdor.AgentStatusWorkers{
+			strconv.FormatInt(obs.WorkerID, 10): {
+				State:     "DONE",
+				StartedMs: start,
+				DoneMs:    start + 10000,
+				Status:    map[string]interface{}{"totalSent": sent[obs.CurrentRun], "p99LatencyMs": 10 * (obs.CurrentRun + 1)},
+			},
+		}})
+	})
+
+	e := external{service: svc, log: logging.NewNopLogger()}
+	if _, err := e.Create(context.TODO(), cr); err != nil {
+		t.Fatalf("e.Create(...): unexpected error: %v", err)
+	}
+	for i := 1; i < 3; i++ {
+		previous := cr.Status.AtProvider.WorkerID
+		if _, err := e.Update(context.TODO(), cr); err != nil {
+			t.Fatalf("e.Update(...): unexpected error: %v", err)
+		}
+		if cr.Status.AtProvider.CurrentRun != int32(i) {
+			t.Fatalf("e.Update(...): run %d should start once the previous one is done", i)
+		}
+		if diff := cmp.Diff(strconv.FormatInt(previous, 10), deleted[len(deleted)-1]); diff != "" {
+			t.Errorf("e.Update(...): the worker of the previous run should be deleted: -want, +got:\n%s\n", diff)
+		}
+		if got, want := starts[i], starts[i-1]+10000+30000; got != want {
+			t.Errorf("e.Update(...): run %d should start %d after its cooldown, got %d", i, want, got)
+		}
+	}
+	if _, err := e.Update(context.TODO(), cr); err != nil {
+		t.Fatalf("e.Update(...): unexpected error: %v", err)
+	}
+
+	obs := cr.Status.AtProvider
+	if benches.RunsLeft(obs) || !benches.Finished(obs.TaskStatus) {
+		t.Errorf("e.Update(...): the bench should be finished after its last run")
+	}
+	got := []int64{}
+	for _, r := range obs.Runs {
+		got = append(got, r.MessagesPerSec)
+	}
+	if diff := cmp.Diff([]int64{9000, 10000, 11000}, got); diff != "" {
+		t.Errorf("e.Update(...): -want run rates, +got run rates:\n%s\n", diff)
+	}
+	// The t critical value is 4.303 at two degrees of freedom.
+	want := &v1alpha1.RunSummary{
+		Runs:             3,
+		MessagesPerSec:   v1alpha1.Statistic{Mean: 10000, StdDev: 1000, Min: 9000, Max: 11000, ConfidenceLow: 7515.662, ConfidenceHigh: 12484.338},
+		AverageLatencyMs: &v1alpha1.Statistic{},
+		P50LatencyMs:     &v1alpha1.Statistic{},
+		P95LatencyMs:     &v1alpha1.Statistic{},
+		P99LatencyMs:     &v1alpha1.Statistic{Mean: 20, StdDev: 10, Min: 10, Max: 30, ConfidenceLow: -4.843, ConfidenceHigh: 44.843},
+	}
+	if diff := cmp.Diff(want, obs.Summary, cmpopts.EquateApprox(0, 0.001)); diff != "" {
+		t.Errorf("e.Update(...): -want summary, +got summary:\n%s\n", diff)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		class  string
+		runs   []v1alpha1.BenchRun
+		want   *v1alpha1.RunSummary
+	}{
+		"NoneDone": {
+			reason: "Runs that are not done should not be summed up.",
+			class:  producerWorkload,
+			runs:   []v1alpha1.BenchRun{{TaskStatus: taskStatusTimedOut, MessagesPerSec: 1000}},
+		},
+		"SkipNotDone": {
+			reason: "Only the runs that are done should be summed up.",
+			class:  roundTripWorkload,
+			runs: []v1alpha1.BenchRun{
+				{TaskStatus: taskStatusDone, MessagesPerSec: 1000},
+				{TaskStatus: taskStatusTimedOut, MessagesPerSec: 10},
+			},
+			want: &v1alpha1.RunSummary{Runs: 1, MessagesPerSec: v1alpha1.Statistic{Mean: 1000, Min: 1000, Max: 1000, ConfidenceLow: 1000, ConfidenceHigh: 1000}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := summary(tc.class, tc.runs)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nsummary(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkabench
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+const (
+	taskStatusScheduled = "SCHEDULED"
+	taskStatusMissed    = "MISSED"
+
+	// dispatchLead is how long before their start scheduled benches are
+	// dispatched, so that they start on time despite the poll interval.
+	dispatchLead = time.Minute
+
+	errFmtTimeOfDay = "cannot parse time of day %q"
+)
+
+// due returns when the supplied bench starts if it is dispatched at the
+// supplied time, and when the start window it is due in closes, or the zero
+// time if it has no start window. Benches without a startAt are due from
+// their creation.
+func due(cr bench, now time.Time) (start, closes time.Time, err error) {
+	spec := cr.GetBenchSpec()
+	start = cr.GetCreationTimestamp().Time
+	if spec.StartAt != nil {
+		start = spec.StartAt.Time
+	}
+	if w := spec.StartWindow; w != nil {
+		start, closes, err = window(w, start)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	if now.After(start) {
+		start = now
+	}
+	return start, closes, nil
+}
+
+// window returns the first time of the supplied start window at or after the
+// supplied time, and when that window closes.
+func window(w *v1alpha1.StartWindow, t time.Time) (time.Time, time.Time, error) {
+	from, err := timeOfDay(w.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := timeOfDay(w.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	length := to - from
+	if length <= 0 {
+		// The window spans midnight.
+		length += 24 * time.Hour
+	}
+	t = t.UTC()
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	for d := -1; ; d++ {
+		opens := midnight.AddDate(0, 0, d).Add(from)
+		closes := opens.Add(length)
+		if closes.After(t) {
+			if t.After(opens) {
+				return t, closes, nil
+			}
+			return opens, closes, nil
+		}
+	}
+}
+
+// timeOfDay returns the time since midnight of the supplied time of day.
+func timeOfDay(hhmm string) (time.Duration, error) {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return 0, errors.Wrapf(err, errFmtTimeOfDay, hhmm)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// hold returns whether the supplied bench, which has yet to be dispatched,
+// must wait for its start or missed its start window, and reports it in its
+// status.
+func hold(cr bench, now time.Time) (bool, error) {
+	obs := &cr.GetBenchStatus().AtProvider
+	start, closes, err := due(cr, now)
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case !closes.IsZero() && !now.Before(closes):
+		obs.TaskStatus = taskStatusMissed
+		obs.ScheduledTime = nil
+		cr.SetConditions(v1alpha1.StartWindowMissed(closes))
+		return true, nil
+	case start.Sub(now) > dispatchLead:
+		obs.TaskStatus = taskStatusScheduled
+		obs.ScheduledTime = &metav1.Time{Time: start}
+		cr.SetConditions(v1alpha1.Scheduled(start))
+		return true, nil
+	}
+	if obs.TaskStatus == taskStatusScheduled || obs.TaskStatus == taskStatusMissed {
+		// The bench is due, or was rescheduled.
+		obs.TaskStatus = ""
+	}
+	obs.ScheduledTime = nil
+	return false, nil
+}
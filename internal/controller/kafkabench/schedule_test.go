@@ -0,0 +1,212 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkabench
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jarcoal/httpmock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+func TestWindow(t *testing.T) {
+	at := func(day, hour, min int) time.Time { return time.Date(2022, 6, day, hour, min, 0, 0, time.UTC) }
+	type want struct {
+		opens  time.Time
+		closes time.Time
+	}
+	cases := map[string]struct {
+		reason string
+		window v1alpha1.StartWindow
+		t      time.Time
+		want   want
+	}{
+		"Before": {
+			reason: "Times before the window should wait for it to open.",
+			window: v1alpha1.StartWindow{Start: "02:00", End: "04:00"},
+			t:      at(1, 1, 30),
+			want:   want{opens: at(1, 2, 0), closes: at(1, 4, 0)},
+		},
+		"Within": {
+			reason: "Times within the window should not wait.",
+			window: v1alpha1.StartWindow{Start: "02:00", End: "04:00"},
+			t:      at(1, 3, 15),
+			want:   want{opens: at(1, 3, 15), closes: at(1, 4, 0)},
+		},
+		"After": {
+			reason: "Times after the window should wait for the window of the next day.",
+			window: v1alpha1.StartWindow{Start: "02:00", End: "04:00"},
+			t:      at(1, 5, 0),
+			want:   want{opens: at(2, 2, 0), closes: at(2, 4, 0)},
+		},
+		"AcrossMidnight": {
+			reason: "Windows ending before they start should span midnight.",
+			window: v1alpha1.StartWindow{Start: "23:00", End: "01:00"},
+			t:      at(2, 0, 30),
+			want:   want{opens: at(2, 0, 30), closes: at(2, 1, 0)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			opens, closes, err := window(&tc.window, tc.t)
+			if err != nil {
+				t.Fatalf("\n%s\nwindow(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, want{opens: opens, closes: closes}, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nwindow(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestHold(t *testing.T) {
+	created := time.Date(2022, 6, 1, 1, 0, 0, 0, time.UTC)
+	type want struct {
+		held      bool
+		status    string
+		scheduled *metav1.Time
+		reason    xpv1.ConditionReason
+	}
+	cases := map[string]struct {
+		reason string
+		spec   v1alpha1.KafkaBenchSpec
+		status string
+		now    time.Time
+		want   want
+	}{
+		"Unscheduled": {
+			reason: "Benches without a start should not be held.",
+			now:    created,
+			want:   want{},
+		},
+		"StartAt": {
+			reason: "Benches should be held until their startAt.",
+			spec:   v1alpha1.KafkaBenchSpec{StartAt: &metav1.Time{Time: created.Add(time.Hour)}},
+			now:    created,
+			want: want{
+				held:      true,
+				status:    taskStatusScheduled,
+				scheduled: &metav1.Time{Time: created.Add(time.Hour)},
+				reason:    v1alpha1.ReasonScheduled,
+			},
+		},
+		"Due": {
+			reason: "Benches should be dispatched shortly before their start.",
+			spec:   v1alpha1.KafkaBenchSpec{StartAt: &metav1.Time{Time: created.Add(time.Hour)}},
+			status: taskStatusScheduled,
+			now:    created.Add(time.Hour - 30*time.Second),
+			want:   want{},
+		},
+		"Window": {
+			reason: "Benches should be held until their start window opens.",
+			spec:   v1alpha1.KafkaBenchSpec{StartWindow: &v1alpha1.StartWindow{Start: "02:00", End: "04:00"}},
+			now:    created,
+			want: want{
+				held:      true,
+				status:    taskStatusScheduled,
+				scheduled: &metav1.Time{Time: created.Add(time.Hour)},
+				reason:    v1alpha1.ReasonScheduled,
+			},
+		},
+		"Missed": {
+			reason: "Benches still waiting when their start window closed should not start.",
+			spec:   v1alpha1.KafkaBenchSpec{StartWindow: &v1alpha1.StartWindow{Start: "02:00", End: "04:00"}},
+			status: taskStatusQueued,
+			now:    created.Add(3*time.Hour + time.Minute),
+			want:   want{held: true, status: taskStatusMissed, reason: v1alpha1.ReasonStartWindowMissed},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.KafkaBench{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.Time{Time: created}}, Spec: tc.spec}
+			cr.Status.AtProvider.TaskStatus = tc.status
+			held, err := hold(cr, tc.now)
+			if err != nil {
+				t.Fatalf("\n%s\nhold(...): unexpected error: %v", tc.reason, err)
+			}
+			got := want{
+				held:      held,
+				status:    cr.Status.AtProvider.TaskStatus,
+				scheduled: cr.Status.AtProvider.ScheduledTime,
+				reason:    cr.GetCondition(xpv1.TypeReady).Reason,
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nhold(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestStartAt(t *testing.T) {
+	httpClient := resty.New()
+	svc := newTrogdorServiceWithRestClient(httpClient, &mockResolver{[]string{defaultAgentServiceName}, nil})
+	httpmock.ActivateNonDefault(httpClient.GetClient())
+	defer httpmock.DeactivateAndReset()
+	var startMs int64
+	httpmock.RegisterResponder("POST", agentServiceURL+"/agent/worker/create", func(req *http.Request) (*http.Response, error) {
+		task := struct {
+			Spec struct {
+				StartMs int64 `json:"startMs"`
+			} `json:"spec"`
+		}{}
+		if err := json.NewDecoder(req.Body).Decode(&task); err != nil {
+			return nil, err
+		}
+		startMs = task.Spec.StartMs
+		return httpmock.NewStringResponse(200, "{}"), nil
+	})
+
+	e := external{service: svc, log: logging.NewNopLogger()}
+	start := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+	cr := &v1alpha1.KafkaBench{Spec: v1alpha1.KafkaBenchSpec{
+		KafkaBenchParameters: v1alpha1.KafkaBenchParameters{Class: producerWorkload},
+		StartAt:              &metav1.Time{Time: start},
+	}}
+	got, err := e.Observe(context.TODO(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, got); diff != "" {
+		t.Errorf("e.Observe(...): a scheduled bench should not be created: -want, +got:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(v1alpha1.Scheduled(start), cr.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+		t.Errorf("e.Observe(...): -want condition, +got condition:\n%s\n", diff)
+	}
+
+	// The bench is dispatched ahead of its start.
+	if _, err := e.Create(context.TODO(), cr); err != nil {
+		t.Fatalf("e.Create(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(start.UnixMilli(), startMs); diff != "" {
+		t.Errorf("e.Create(...): the task should start at the startAt of the bench: -want, +got:\n%s\n", diff)
+	}
+}
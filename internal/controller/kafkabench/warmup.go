@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkabench
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/benches"
+)
+
+const (
+	errWarmupNotProducer = "warm-ups are only supported by " + v1alpha1.ProduceBenchClass + " workloads"
+	errStartSteadyState  = "cannot start the bench once warmed up"
+)
+
+// warmupParameters returns the supplied parameters of a bench as dispatched
+// for the supplied warm-up. A warm-up lasting for a number of messages is
+// bounded by the duration of the bench, and one lasting for a duration by the
+// messages its target rate sends meanwhile, or by those of the bench when it
+// has no target rate.
+func warmupParameters(params v1alpha1.KafkaBenchParameters, w v1alpha1.Warmup) v1alpha1.KafkaBenchParameters {
+	if w.Messages > 0 {
+		params.MaxMessages = w.Messages
+		return params
+	}
+	params.DurationMs = w.DurationMs
+	if params.TargetMessagesPerSec > 0 {
+		params.MaxMessages = max(1, int64(params.TargetMessagesPerSec)*w.DurationMs/1000)
+	}
+	return params
+}
+
+// endWarmup records the results of the warm-up of a bench and, once it is
+// done, starts the bench itself. The start of the bench is then that of its
+// steady state.
+func (c *external) endWarmup(cr bench) error {
+	obs := &cr.GetBenchStatus().AtProvider
+	if !benches.WarmingUp(*obs) {
+		return nil
+	}
+	w := obs.Warmup
+	w.TaskStatus = obs.TaskStatus
+	w.ProducerStats = obs.ProducerStats
+	w.CompletionTime = obs.CompletionTime
+	w.MessagesPerSec = rate(obs.ProducerStats.TotalSent, w.StartTime, w.CompletionTime)
+	if !benches.WarmupLeft(*obs) {
+		return nil
+	}
+
+	params, err := c.parameters(cr)
+	if err != nil {
+		return errors.Wrap(err, errStartSteadyState)
+	}
+	workerTask, err := c.service.CreateWorkerTask(restart(obs, params))
+	if err != nil {
+		return errors.Wrap(err, errStartSteadyState)
+	}
+	// The worker of the warm-up is no longer needed.
+	previous := strconv.FormatInt(obs.WorkerID, 10)
+	if err := c.service.DeleteWorkerTask(previous); err != nil {
+		c.log.Debug("Cannot delete the worker of the warm-up", "name", cr.GetName(), "workerId", previous, "error", err)
+	}
+	c.log.Debug("Warmed up", "name", cr.GetName(), "workerId", workerTask.WorkerID, "warmupMessages", w.ProducerStats.TotalSent)
+
+	obs.StartTime = &metav1.Time{Time: time.UnixMilli(workerTask.Spec.StartMs)}
+	begin(obs, workerTask)
+	return nil
+}
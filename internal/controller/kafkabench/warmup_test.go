@@ -0,0 +1,166 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkabench
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jarcoal/httpmock"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/benches"
+	"github.com/nachomdo/tarasque/internal/clients/trogdor"
+)
+
+func TestWarmup(t *testing.T) {
+	httpClient := resty.New()
+	svc := newTrogdorServiceWithRestClient(httpClient, &mockResolver{[]string{defaultAgentServiceName}, nil})
+	httpmock.ActivateNonDefault(httpClient.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	var created []dispatched
+	deleted := []string{}
+	httpmock.RegisterResponder("POST", agentServiceURL+"/agent/worker/create", func(req *http.Request) (*http.Response, error) {
+		task := struct {
+			Spec dispatched `json:"spec"`
+		}{}
+		if err := json.NewDecoder(req.Body).Decode(&task); err != nil {
+			return nil, err
+		}
+		created = append(created, task.Spec)
+		return httpmock.NewStringResponse(200, "{}"), nil
+	})
+	httpmock.RegisterResponder("DELETE", agentServiceURL+"/agent/worker", func(req *http.Request) (*http.Response, error) {
+		deleted = append(deleted, req.URL.Query().Get("workerId"))
+		return httpmock.NewStringResponse(200, "{}"), nil
+	})
+
+	cr := &v1alpha1.KafkaBench{Spec: v1alpha1.KafkaBenchSpec{
+		KafkaBenchParameters: v1alpha1.KafkaBenchParameters{Class: producerWorkload, DurationMs: 60000, TargetMessagesPerSec: 1000, MaxMessages: 60000},
+		Warmup:               &v1alpha1.Warmup{DurationMs: 10000},
+	}}
+	// The warm-up is slow, the steady state is not.
+	status := map[string]interface{}{"totalSent": 10000, "p99LatencyMs": 250}
+	httpmock.RegisterResponder("GET", agentServiceURL+"/agent/status", func(req *http.Request) (*http.Response, error) {
+		obs := cr.Status.AtProvider
+		return httpmock.NewJsonResponse(200, trogdor.AgentStatusResponse{Workers: map[string]trogdor.AgentStatusWorkers{
+			strconv.FormatInt(obs.WorkerID, 10): {
+				State:     "DONE",
+				StartedMs: obs.StartTime.UnixMilli(),
+				DoneMs:    obs.StartTime.UnixMilli() + 10000,
+				Status:    status,
+			},
+		}})
+	})
+
+	e := external{service: svc, log: logging.NewNopLogger()}
+	if _, err := e.Create(context.TODO(), cr); err != nil {
+		t.Fatalf("e.Create(...): unexpected error: %v", err)
+	}
+	warmup := cr.Status.AtProvider.WorkerID
+	if !benches.WarmingUp(cr.Status.AtProvider) {
+		t.Errorf("e.Create(...): the warm-up of the bench should run first")
+	}
+
+	// The warm-up is done and the bench starts.
+	if _, err := e.Update(context.TODO(), cr); err != nil {
+		t.Fatalf("e.Update(...): unexpected error: %v", err)
+	}
+	if benches.WarmingUp(cr.Status.AtProvider) {
+		t.Errorf("e.Update(...): the bench should start once warmed up")
+	}
+	if diff := cmp.Diff([]string{strconv.FormatInt(warmup, 10)}, deleted); diff != "" {
+		t.Errorf("e.Update(...): the worker of the warm-up should be deleted: -want, +got:\n%s\n", diff)
+	}
+
+	// The bench is done.
+	status = map[string]interface{}{"totalSent": 60000, "p99LatencyMs": 12}
+	if _, err := e.Update(context.TODO(), cr); err != nil {
+		t.Fatalf("e.Update(...): unexpected error: %v", err)
+	}
+
+	want := []dispatched{
+		{TargetMessagesPerSec: 1000, DurationMs: 10000, MaxMessages: 10000},
+		{TargetMessagesPerSec: 1000, DurationMs: 60000, MaxMessages: 60000},
+	}
+	if diff := cmp.Diff(want, created); diff != "" {
+		t.Errorf("e.Create(...), e.Update(...): the warm-up should be dispatched before the bench: -want, +got:\n%s\n", diff)
+	}
+	obs := cr.Status.AtProvider
+	if diff := cmp.Diff(v1alpha1.ProducerBenchResultStats{TotalSent: 60000, P99LatencyMs: 12}, obs.ProducerStats); diff != "" {
+		t.Errorf("e.Update(...): the stats of the bench should exclude its warm-up: -want, +got:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(v1alpha1.ProducerBenchResultStats{TotalSent: 10000, P99LatencyMs: 250}, obs.Warmup.ProducerStats); diff != "" {
+		t.Errorf("e.Update(...): the stats of the warm-up should be kept apart: -want, +got:\n%s\n", diff)
+	}
+	if obs.Warmup.MessagesPerSec != 1000 || obs.Warmup.TaskStatus != taskStatusDone {
+		t.Errorf("e.Update(...): the warm-up should be done at 1000 messages per second, got %d in %s", obs.Warmup.MessagesPerSec, obs.Warmup.TaskStatus)
+	}
+	if benches.WarmupLeft(obs) || !benches.Finished(obs.TaskStatus) {
+		t.Errorf("e.Update(...): the bench should be finished")
+	}
+}
+
+func TestWarmupParameters(t *testing.T) {
+	params := v1alpha1.KafkaBenchParameters{Class: producerWorkload, DurationMs: 600000, MaxMessages: 5000000, TargetMessagesPerSec: 1000}
+	unthrottled := params
+	unthrottled.TargetMessagesPerSec = 0
+
+	cases := map[string]struct {
+		reason string
+		params v1alpha1.KafkaBenchParameters
+		warmup v1alpha1.Warmup
+		want   dispatched
+	}{
+		"Messages": {
+			reason: "Warm-ups lasting for a number of messages should be bounded by the duration of the bench.",
+			params: params,
+			warmup: v1alpha1.Warmup{Messages: 10000},
+			want:   dispatched{TargetMessagesPerSec: 1000, DurationMs: 600000, MaxMessages: 10000},
+		},
+		"Duration": {
+			reason: "Warm-ups lasting for a duration should send the messages of their target rate meanwhile.",
+			params: params,
+			warmup: v1alpha1.Warmup{DurationMs: 30000},
+			want:   dispatched{TargetMessagesPerSec: 1000, DurationMs: 30000, MaxMessages: 30000},
+		},
+		"DurationWithoutRate": {
+			reason: "Warm-ups lasting for a duration without a target rate should only be bounded by their duration.",
+			params: unthrottled,
+			warmup: v1alpha1.Warmup{DurationMs: 30000},
+			want:   dispatched{DurationMs: 30000, MaxMessages: 5000000},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := warmupParameters(tc.params, tc.warmup)
+			got := dispatched{TargetMessagesPerSec: p.TargetMessagesPerSec, DurationMs: p.DurationMs, MaxMessages: p.MaxMessages}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nwarmupParameters(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkabench
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/benches"
+	"github.com/nachomdo/tarasque/internal/redact"
+)
+
+const (
+	taskStatusTimedOut = "TIMED_OUT"
+	taskStatusFailed   = "FAILED"
+
+	// defaultGracePeriod is how long past its durationMs a bench without a
+	// watchdog may take to be done.
+	defaultGracePeriod = 5 * time.Minute
+
+	reasonTimedOut     event.Reason = "TimedOut"
+	reasonWorkerFailed event.Reason = "WorkerFailed"
+
+	errStopTask      = "cannot stop the task of the bench"
+	errFmtNotDone    = "not done %s after it started, past its durationMs and grace period"
+	errFmtNoProgress = "no progress for %s"
+)
+
+// fail records the error the worker of a bench reported. The bench will not
+// make any further progress, and keeps its last stats.
+func (c *external) fail(cr bench, msg string) {
+	obs := &cr.GetBenchStatus().AtProvider
+	msg = redact.String(msg)
+	c.log.Debug("Worker task failed", "name", cr.GetName(), "workerId", obs.WorkerID, "error", msg)
+	obs.TaskStatus = taskStatusFailed
+	obs.Error = msg
+	cr.SetConditions(v1alpha1.WorkerFailed(msg))
+	c.record.Event(cr, event.Warning(reasonWorkerFailed, errors.New(msg)))
+}
+
+// progress returns a fingerprint of the status Trogdor reported for the
+// supplied bench, which changes whenever the bench makes progress.
+func progress(cr bench) string {
+	obs := cr.GetBenchStatus().AtProvider
+	b, _ := json.Marshal([]interface{}{obs.TaskStatus, obs.ProducerStats, obs.ConsumerStats, obs.RoundTripStats, obs.RawStatus, obs.CommandStatus})
+	return string(b)
+}
+
+// watchdog stops the task of a bench that is not done within its durationMs
+// and grace period, or whose status did not change for its stall timeout. The
+// bench keeps the last stats collected, and is reported as timed out. A task
+// is only timed once it started, so that waiting for its startMs does not
+// count as a stall.
+func (c *external) watchdog(cr bench, before string) error {
+	obs := &cr.GetBenchStatus().AtProvider
+	if obs.StartTime == nil || benches.Finished(obs.TaskStatus) {
+		return nil
+	}
+	now := time.Now()
+	started, d := timing(cr)
+	if now.Before(started.Time) {
+		return nil
+	}
+	switch {
+	case progress(cr) != before:
+		obs.LastProgressTime = &metav1.Time{Time: now}
+	case obs.LastProgressTime == nil || obs.LastProgressTime.Before(started):
+		obs.LastProgressTime = started.DeepCopy()
+	}
+
+	msg := overdue(cr, now.Sub(started.Time), d)
+	if msg == "" {
+		return nil
+	}
+	c.log.Debug("Stopping worker task", "name", cr.GetName(), "workerId", obs.WorkerID, "reason", msg)
+	if err := c.service.StopWorkerTask(obs.WorkerID); err != nil {
+		return errors.Wrap(err, errStopTask)
+	}
+	obs.TaskStatus = taskStatusTimedOut
+	cr.SetConditions(v1alpha1.TimedOut(msg))
+	c.record.Event(cr, event.Warning(reasonTimedOut, errors.New(msg)))
+	return nil
+}
+
+// timing returns when the current task of the supplied bench started, and its
+// duration in milliseconds. The warm-up of a bench is timed as the bench
+// itself, while the runs of a bench with repetitions and the segments of a
+// bench with a load profile are timed one by one.
+func timing(cr bench) (*metav1.Time, int64) {
+	obs := cr.GetBenchStatus().AtProvider
+	started, d := obs.StartTime, durationMs(effectiveParameters(cr))
+	switch {
+	case benches.WarmingUp(obs):
+	case len(obs.Segments) > 0:
+		seg := obs.Segments[obs.CurrentSegment]
+		started, d = seg.StartTime, seg.DurationMs
+	case len(obs.Runs) > 0:
+		started = obs.Runs[obs.CurrentRun].StartTime
+	}
+	if started == nil {
+		started = obs.StartTime
+	}
+	return started, d
+}
+
+// overdue returns why the task of the supplied bench, running for elapsed out
+// of its d milliseconds, should be stopped, or an empty string if it should
+// keep running.
+func overdue(cr bench, elapsed time.Duration, d int64) string {
+	grace, stall := defaultGracePeriod, time.Duration(0)
+	if w := cr.GetBenchSpec().Watchdog; w != nil {
+		grace = time.Duration(w.GracePeriodMs) * time.Millisecond
+		stall = time.Duration(w.StallTimeoutMs) * time.Millisecond
+	}
+	idle := time.Since(cr.GetBenchStatus().AtProvider.LastProgressTime.Time)
+	switch {
+	case d > 0 && elapsed > time.Duration(d)*time.Millisecond+grace:
+		return fmt.Sprintf(errFmtNotDone, elapsed.Round(time.Second))
+	case stall > 0 && idle > stall:
+		return fmt.Sprintf(errFmtNoProgress, idle.Round(time.Second))
+	}
+	return ""
+}
+
+// durationMs returns the duration of the supplied parameters, including
+// those of a rawSpec.
+func durationMs(params v1alpha1.KafkaBenchParameters) int64 {
+	if params.RawSpec == nil {
+		return params.DurationMs
+	}
+	raw := struct {
+		DurationMs int64 `json:"durationMs"`
+	}{}
+	_ = json.Unmarshal(params.RawSpec.Raw, &raw)
+	return raw.DurationMs
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkabench
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jarcoal/httpmock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/benches"
+	"github.com/nachomdo/tarasque/internal/benches/benchestest"
+)
+
+func TestWatchdog(t *testing.T) {
+	ago := func(d time.Duration) *metav1.Time {
+		return &metav1.Time{Time: time.Now().Add(-d)}
+	}
+	newBench := func(status string, started, progressed *metav1.Time, w *v1alpha1.KafkaBenchWatchdog) *v1alpha1.KafkaBench {
+		cr := &v1alpha1.KafkaBench{Spec: v1alpha1.KafkaBenchSpec{
+			KafkaBenchParameters: v1alpha1.KafkaBenchParameters{Class: producerWorkload, DurationMs: 60000},
+			Watchdog:             w,
+		}}
+		cr.Status.AtProvider.TaskID = "task"
+		cr.Status.AtProvider.WorkerID = 42
+		cr.Status.AtProvider.TaskStatus = status
+		cr.Status.AtProvider.StartTime = started
+		cr.Status.AtProvider.LastProgressTime = progressed
+		cr.Status.AtProvider.ProducerStats.TotalSent = 100
+		return cr
+	}
+
+	type want struct {
+		stopped    bool
+		taskStatus string
+		reason     xpv1.ConditionReason
+		// lastProgress is checked to the second when set.
+		lastProgress *metav1.Time
+	}
+	cases := map[string]struct {
+		reason string
+		cr     *v1alpha1.KafkaBench
+		// progressed makes the bench report new stats during the update.
+		progressed bool
+		want       want
+	}{
+		"NotStarted": {
+			reason: "Benches whose start was not recorded should be left alone.",
+			cr:     newBench("RUNNING", nil, nil, nil),
+			want:   want{taskStatus: "RUNNING"},
+		},
+		"WithinGracePeriod": {
+			reason: "Benches within their durationMs and grace period should keep running.",
+			cr:     newBench("RUNNING", ago(3*time.Minute), nil, nil),
+			want:   want{taskStatus: "RUNNING"},
+		},
+		"PastGracePeriod": {
+			reason: "Benches not done within their durationMs and grace period should be stopped.",
+			cr:     newBench("RUNNING", ago(10*time.Minute), ago(time.Second), nil),
+			want:   want{stopped: true, taskStatus: taskStatusTimedOut, reason: v1alpha1.ReasonTimedOut},
+		},
+		"CustomGracePeriod": {
+			reason: "The grace period of the watchdog of a bench should apply.",
+			cr:     newBench("RUNNING", ago(10*time.Minute), ago(time.Second), &v1alpha1.KafkaBenchWatchdog{GracePeriodMs: 3600000}),
+			want:   want{taskStatus: "RUNNING"},
+		},
+		"Stalled": {
+			reason: "Benches reporting the same status for their stall timeout should be stopped.",
+			cr:     newBench("creating topics...", ago(2*time.Minute), ago(2*time.Minute), &v1alpha1.KafkaBenchWatchdog{GracePeriodMs: 300000, StallTimeoutMs: 60000}),
+			want:   want{stopped: true, taskStatus: taskStatusTimedOut, reason: v1alpha1.ReasonTimedOut},
+		},
+		"Progressing": {
+			reason:     "Benches reporting new stats should not be considered stalled.",
+			cr:         newBench("RUNNING", ago(2*time.Minute), ago(2*time.Minute), &v1alpha1.KafkaBenchWatchdog{GracePeriodMs: 300000, StallTimeoutMs: 60000}),
+			progressed: true,
+			want:       want{taskStatus: "RUNNING"},
+		},
+		"FutureStart": {
+			reason: "Benches waiting for their startMs should not be considered stalled.",
+			cr:     newBench("PENDING", ago(-time.Minute), ago(2*time.Minute), &v1alpha1.KafkaBenchWatchdog{GracePeriodMs: 300000, StallTimeoutMs: 60000}),
+			want:   want{taskStatus: "PENDING", lastProgress: ago(2 * time.Minute)},
+		},
+		"JustStarted": {
+			reason: "The stall timeout of a bench should count from its start rather than from when it was created.",
+			cr:     newBench("RUNNING", ago(10*time.Second), ago(2*time.Minute), &v1alpha1.KafkaBenchWatchdog{GracePeriodMs: 300000, StallTimeoutMs: 60000}),
+			want:   want{taskStatus: "RUNNING", lastProgress: ago(10 * time.Second)},
+		},
+		"Cooldown": {
+			reason: "Benches cooling down before their next run should not be considered stalled.",
+			cr: func() *v1alpha1.KafkaBench {
+				cr := newBench("PENDING", ago(time.Hour), ago(2*time.Minute), &v1alpha1.KafkaBenchWatchdog{GracePeriodMs: 300000, StallTimeoutMs: 60000})
+				cr.Status.AtProvider.CurrentRun = 1
+				cr.Status.AtProvider.Runs = []v1alpha1.BenchRun{{StartTime: ago(time.Hour)}, {StartTime: ago(-time.Minute)}}
+				return cr
+			}(),
+			want: want{taskStatus: "PENDING", lastProgress: ago(2 * time.Minute)},
+		},
+		"Done": {
+			reason: "Finished benches should be left alone.",
+			cr:     newBench("DONE", ago(time.Hour), ago(time.Hour), nil),
+			want:   want{taskStatus: "DONE"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			httpClient := resty.New()
+			svc := newTrogdorServiceWithRestClient(httpClient, &mockResolver{[]string{defaultAgentServiceName}, nil})
+			httpmock.ActivateNonDefault(httpClient.GetClient())
+			defer httpmock.DeactivateAndReset()
+			httpmock.RegisterResponder("PUT", agentServiceURL+"/agent/worker/stop", httpmock.NewStringResponder(200, "{}"))
+
+			r := &benchestest.Recorder{}
+			e := external{service: svc, record: r, log: logging.NewNopLogger()}
+			before := progress(tc.cr)
+			if tc.progressed {
+				tc.cr.Status.AtProvider.ProducerStats.TotalSent++
+			}
+			if err := e.watchdog(tc.cr, before); err != nil {
+				t.Fatalf("\n%s\ne.watchdog(...): unexpected error: %v", tc.reason, err)
+			}
+
+			if diff := cmp.Diff(tc.want.stopped, httpmock.GetTotalCallCount() == 1); diff != "" {
+				t.Errorf("\n%s\ne.watchdog(...): -want stopped, +got stopped:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.taskStatus, tc.cr.Status.AtProvider.TaskStatus); diff != "" {
+				t.Errorf("\n%s\ne.watchdog(...): -want task status, +got task status:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.reason, tc.cr.GetCondition(xpv1.TypeReady).Reason); diff != "" {
+				t.Errorf("\n%s\ne.watchdog(...): -want reason, +got reason:\n%s\n", tc.reason, diff)
+			}
+			if want, got := tc.want.lastProgress, tc.cr.Status.AtProvider.LastProgressTime; want != nil && (got == nil || got.Sub(want.Time).Abs() > time.Second) {
+				t.Errorf("\n%s\ne.watchdog(...): want last progress at %v, got %v", tc.reason, want, got)
+			}
+			if tc.want.stopped {
+				if diff := cmp.Diff([]event.Reason{reasonTimedOut}, r.Reasons); diff != "" {
+					t.Errorf("\n%s\ne.watchdog(...): -want events, +got events:\n%s\n", tc.reason, diff)
+				}
+				if diff := cmp.Diff(int64(100), tc.cr.Status.AtProvider.ProducerStats.TotalSent); diff != "" {
+					t.Errorf("\n%s\ne.watchdog(...): the last stats should be kept: -want, +got:\n%s\n", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestWatchdogWorkerError(t *testing.T) {
+	httpClient := resty.New()
+	svc := newTrogdorServiceWithRestClient(httpClient, &mockResolver{[]string{defaultAgentServiceName}, nil})
+	httpmock.ActivateNonDefault(httpClient.GetClient())
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", agentServiceURL+"/agent/status",
+		httpmock.NewStringResponder(200, `{"workers": {"42": {"state": "DONE", "taskId": "task", "error": "Topic creation failed"}}}`))
+
+	cr := &v1alpha1.KafkaBench{Spec: v1alpha1.KafkaBenchSpec{
+		KafkaBenchParameters: v1alpha1.KafkaBenchParameters{Class: producerWorkload, DurationMs: 60000},
+		Assertions:           []string{"producer.totalSent > 0"},
+	}}
+	cr.Status.AtProvider.TaskID = "task"
+	cr.Status.AtProvider.WorkerID = 42
+	cr.Status.AtProvider.TaskStatus = "RUNNING"
+	cr.Status.AtProvider.StartTime = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+	cr.Status.AtProvider.ProducerStats.TotalSent = 100
+
+	r := &benchestest.Recorder{}
+	e := external{service: svc, record: r, log: logging.NewNopLogger()}
+	if _, err := e.Update(context.TODO(), cr); err != nil {
+		t.Fatalf("e.Update(...): a worker reporting an error should not fail the update: %v", err)
+	}
+
+	obs := cr.Status.AtProvider
+	if !benches.Finished(obs.TaskStatus) || obs.TaskStatus != taskStatusFailed {
+		t.Errorf("e.Update(...): want task status %s, got %s", taskStatusFailed, obs.TaskStatus)
+	}
+	if diff := cmp.Diff("Topic creation failed", obs.Error); diff != "" {
+		t.Errorf("e.Update(...): -want error, +got error:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(v1alpha1.ReasonWorkerFailed, cr.GetCondition(xpv1.TypeReady).Reason); diff != "" {
+		t.Errorf("e.Update(...): -want reason, +got reason:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(corev1.ConditionFalse, cr.GetCondition(v1alpha1.TypePassed).Status); diff != "" {
+		t.Errorf("e.Update(...): the assertions of a failed bench should not pass: -want, +got:\n%s\n", diff)
+	}
+	if diff := cmp.Diff([]event.Reason{reasonWorkerFailed}, r.Reasons); diff != "" {
+		t.Errorf("e.Update(...): -want events, +got events:\n%s\n", diff)
+	}
+	if diff := cmp.Diff(int64(100), obs.ProducerStats.TotalSent); diff != "" {
+		t.Errorf("e.Update(...): the last stats should be kept: -want, +got:\n%s\n", diff)
+	}
+}
@@ -0,0 +1,292 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kafkabenchscenario runs the KafkaBenches of the steps of
+// KafkaBenchScenarios in the order of their dependencies.
+package kafkabenchscenario
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/benches"
+)
+
+const (
+	// LabelKeyScenario is set to the name of the KafkaBenchScenario that
+	// created a bench.
+	LabelKeyScenario = "tarasque.crossplane.io/scenario"
+
+	timeout = 2 * time.Minute
+
+	errGetScenario    = "cannot get KafkaBenchScenario"
+	errCreateBench    = "cannot create bench"
+	errUpdateStatus   = "cannot update KafkaBenchScenario status"
+	errFmtDuplicate   = "step %q is declared more than once"
+	errFmtUnknownStep = "step %q depends on unknown step %q"
+	errFmtCycle       = "steps %s depend on one another"
+)
+
+// Event reasons.
+const (
+	reasonCreateBench  event.Reason = "CreateBench"
+	reasonSkipStep     event.Reason = "SkipStep"
+	reasonBadSteps     event.Reason = "InvalidSteps"
+	reasonCannotCreate event.Reason = "CannotCreateBench"
+)
+
+// Setup adds a controller that runs the KafkaBenches of KafkaBenchScenarios.
+func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter) error {
+	name := "scenario/" + strings.ToLower(v1alpha1.KafkaBenchScenarioGroupKind)
+
+	r := &Reconciler{
+		kube:   mgr.GetClient(),
+		log:    l.WithValues("controller", name),
+		record: event.NewAPIRecorder(mgr.GetEventRecorderFor(name)),
+		now:    time.Now,
+	}
+
+	return benches.SetupOwner(mgr, name, &v1alpha1.KafkaBenchScenario{}, r, rl)
+}
+
+// A Reconciler creates the KafkaBench of each step of a KafkaBenchScenario
+// once its dependencies succeeded, skips the steps that depend on a failed
+// one, and summarizes their results.
+type Reconciler struct {
+	kube   client.Client
+	log    logging.Logger
+	record event.Recorder
+	now    func() time.Time
+}
+
+// Reconcile a KafkaBenchScenario.
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	log := r.log.WithValues("request", req)
+	log.Debug("Reconciling")
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	s := &v1alpha1.KafkaBenchScenario{}
+	if ok, err := benches.Get(ctx, r.kube, req.NamespacedName, s); !ok {
+		return reconcile.Result{}, errors.Wrap(err, errGetScenario)
+	}
+
+	order, err := topological(s.Spec.Steps)
+	if err != nil {
+		log.Debug("Cannot order steps", "error", err)
+		r.record.Event(s, event.Warning(reasonBadSteps, err))
+		// There's no need to requeue until the steps are fixed.
+		return reconcile.Result{}, errors.Wrap(benches.Failed(ctx, r.kube, s, err), errUpdateStatus)
+	}
+
+	labels := map[string]string{LabelKeyScenario: s.GetName()}
+	owned, err := benches.Owned(ctx, r.kube, s, labels)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(benches.Failed(ctx, r.kube, s, err), errUpdateStatus)
+	}
+	byName := make(map[string]*v1alpha1.KafkaBench, len(owned))
+	for i := range owned {
+		byName[owned[i].GetName()] = &owned[i]
+	}
+	previous := make(map[string]v1alpha1.ScenarioStepStatus, len(s.Status.Steps))
+	for _, st := range s.Status.Steps {
+		previous[st.Name] = st
+	}
+
+	now := r.now()
+	if s.Status.StartTime == nil {
+		s.Status.StartTime = &metav1.Time{Time: now}
+	}
+
+	steps := make([]v1alpha1.ScenarioStepStatus, len(s.Spec.Steps))
+	byStep := make(map[string]*v1alpha1.ScenarioStepStatus, len(steps))
+	var wait time.Duration
+	for _, i := range order {
+		step := s.Spec.Steps[i]
+		st := &steps[i]
+		*st = v1alpha1.ScenarioStepStatus{Name: step.Name, Phase: v1alpha1.StepPending}
+		byStep[step.Name] = st
+
+		name := benchName(s, step)
+		if kb, ok := byName[name]; ok {
+			st.Bench = name
+			st.Phase = v1alpha1.StepRunning
+			if res, done := benches.Result(kb); done {
+				st.Phase = res
+				st.CompletionTime = completionTime(previous[step.Name], kb, now)
+				st.MessagesPerSec, st.P99LatencyMs = benches.Throughput(kb)
+			}
+			continue
+		}
+
+		start, ready, skip := dependencies(step, byStep, s.Status.StartTime.Time)
+		if skip {
+			st.Phase = v1alpha1.StepSkipped
+			if previous[step.Name].Phase != v1alpha1.StepSkipped {
+				r.record.Event(s, event.Normal(reasonSkipStep, fmt.Sprintf("Skipped step %s after a failed dependency", step.Name)))
+			}
+			continue
+		}
+		if !ready {
+			continue
+		}
+		if d := start.Add(time.Duration(step.StartAfterMs) * time.Millisecond).Sub(now); d > 0 {
+			if wait == 0 || d < wait {
+				wait = d
+			}
+			continue
+		}
+
+		kb := benches.New(s, v1alpha1.KafkaBenchScenarioGroupVersionKind, name, step.BenchTemplate, labels)
+		if err := r.kube.Create(ctx, kb); resource.Ignore(kerrors.IsAlreadyExists, err) != nil {
+			err = errors.Wrap(err, errCreateBench)
+			r.record.Event(s, event.Warning(reasonCannotCreate, err))
+			return reconcile.Result{}, errors.Wrap(benches.Failed(ctx, r.kube, s, err), errUpdateStatus)
+		}
+		r.record.Event(s, event.Normal(reasonCreateBench, fmt.Sprintf("Created bench %s of step %s", name, step.Name)))
+		st.Bench = name
+		st.Phase = v1alpha1.StepRunning
+	}
+
+	completed, failed := 0, false
+	for _, st := range steps {
+		switch st.Phase {
+		case v1alpha1.BenchSucceeded:
+			completed++
+		case v1alpha1.BenchFailed, v1alpha1.StepSkipped:
+			completed++
+			failed = true
+		}
+	}
+	s.Status.Steps = steps
+	s.Status.Completed = int32(completed)
+	s.Status.Result = ""
+	s.Status.SetConditions(xpv1.ReconcileSuccess(), xpv1.Creating())
+	if completed == len(steps) {
+		s.Status.Result = v1alpha1.BenchSucceeded
+		if failed {
+			s.Status.Result = v1alpha1.BenchFailed
+		}
+		s.Status.SetConditions(xpv1.Available())
+	}
+	// The scenario is also reconciled again once the next delayed step is
+	// due.
+	return reconcile.Result{RequeueAfter: wait}, errors.Wrap(r.kube.Status().Update(ctx, s), errUpdateStatus)
+}
+
+func benchName(s *v1alpha1.KafkaBenchScenario, step v1alpha1.ScenarioStep) string {
+	return fmt.Sprintf("%s-%s", s.GetName(), step.Name)
+}
+
+// dependencies returns when the supplied step may start, past its
+// startAfterMs, and whether all of its dependencies succeeded, or true if one
+// of them failed or was skipped. Steps without dependencies start with the
+// scenario.
+func dependencies(step v1alpha1.ScenarioStep, byStep map[string]*v1alpha1.ScenarioStepStatus, scenarioStart time.Time) (start time.Time, ready bool, skip bool) {
+	start, ready = scenarioStart, true
+	for _, d := range step.DependsOn {
+		dep := byStep[d]
+		switch dep.Phase {
+		case v1alpha1.BenchFailed, v1alpha1.StepSkipped:
+			skip = true
+		case v1alpha1.BenchSucceeded:
+			if dep.CompletionTime.After(start) {
+				start = dep.CompletionTime.Time
+			}
+		default:
+			ready = false
+		}
+	}
+	return start, ready, skip
+}
+
+// completionTime returns when the supplied finished bench of a step was first
+// seen finished, preferring the completion time reported by Trogdor.
+func completionTime(previous v1alpha1.ScenarioStepStatus, kb *v1alpha1.KafkaBench, now time.Time) *metav1.Time {
+	switch {
+	case kb.Status.AtProvider.CompletionTime != nil:
+		return kb.Status.AtProvider.CompletionTime
+	case previous.CompletionTime != nil:
+		return previous.CompletionTime
+	}
+	return &metav1.Time{Time: now}
+}
+
+// topological returns the indexes of the supplied steps ordered so that every
+// step follows its dependencies, or an error if a step is declared twice,
+// depends on an unknown step or the steps depend on one another.
+func topological(steps []v1alpha1.ScenarioStep) ([]int, error) {
+	index := make(map[string]int, len(steps))
+	for i, s := range steps {
+		if _, ok := index[s.Name]; ok {
+			return nil, errors.Errorf(errFmtDuplicate, s.Name)
+		}
+		index[s.Name] = i
+	}
+	dependents := make([][]int, len(steps))
+	pending := make([]int, len(steps))
+	for i, s := range steps {
+		for _, d := range s.DependsOn {
+			j, ok := index[d]
+			if !ok {
+				return nil, errors.Errorf(errFmtUnknownStep, s.Name, d)
+			}
+			dependents[j] = append(dependents[j], i)
+			pending[i]++
+		}
+	}
+
+	order := make([]int, 0, len(steps))
+	for i := range steps {
+		if pending[i] == 0 {
+			order = append(order, i)
+		}
+	}
+	for k := 0; k < len(order); k++ {
+		for _, i := range dependents[order[k]] {
+			if pending[i]--; pending[i] == 0 {
+				order = append(order, i)
+			}
+		}
+	}
+	if len(order) < len(steps) {
+		cycle := []string{}
+		for i, s := range steps {
+			if pending[i] > 0 {
+				cycle = append(cycle, s.Name)
+			}
+		}
+		return nil, errors.Errorf(errFmtCycle, strings.Join(cycle, ", "))
+	}
+	return order, nil
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkabenchscenario
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/benches/benchestest"
+)
+
+func TestTopological(t *testing.T) {
+	type want struct {
+		order []int
+		err   bool
+	}
+	cases := map[string]struct {
+		reason string
+		steps  []v1alpha1.ScenarioStep
+		want   want
+	}{
+		"Ordered": {
+			reason: "Steps should follow their dependencies.",
+			steps: []v1alpha1.ScenarioStep{
+				{Name: "consume", DependsOn: []string{"produce"}},
+				{Name: "roundtrip", DependsOn: []string{"produce", "consume"}},
+				{Name: "produce"},
+			},
+			want: want{order: []int{2, 0, 1}},
+		},
+		"Duplicate": {
+			reason: "Steps declared twice should be reported.",
+			steps:  []v1alpha1.ScenarioStep{{Name: "produce"}, {Name: "produce"}},
+			want:   want{err: true},
+		},
+		"Unknown": {
+			reason: "Dependencies on unknown steps should be reported.",
+			steps:  []v1alpha1.ScenarioStep{{Name: "consume", DependsOn: []string{"produce"}}},
+			want:   want{err: true},
+		},
+		"Cycle": {
+			reason: "Steps depending on one another should be reported.",
+			steps: []v1alpha1.ScenarioStep{
+				{Name: "produce"},
+				{Name: "consume", DependsOn: []string{"produce", "roundtrip"}},
+				{Name: "roundtrip", DependsOn: []string{"consume"}},
+			},
+			want: want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			order, err := topological(tc.steps)
+			if diff := cmp.Diff(tc.want.err, err != nil); diff != "" {
+				t.Fatalf("\n%s\ntopological(...): -want error, +got error: %v\n", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.order, order); diff != "" {
+				t.Errorf("\n%s\ntopological(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	start := time.Date(2022, 6, 1, 2, 0, 0, 0, time.UTC)
+	template := v1alpha1.KafkaBenchTemplate{Spec: v1alpha1.KafkaBenchSpec{KafkaBenchParameters: v1alpha1.KafkaBenchParameters{
+		Class: v1alpha1.ProduceBenchClass,
+	}}}
+	scenario := &v1alpha1.KafkaBenchScenario{
+		ObjectMeta: metav1.ObjectMeta{Name: "pipeline", UID: "pipeline-uid"},
+		Spec: v1alpha1.KafkaBenchScenarioSpec{Steps: []v1alpha1.ScenarioStep{
+			{Name: "produce", BenchTemplate: template},
+			{Name: "consume", DependsOn: []string{"produce"}, BenchTemplate: template},
+			{Name: "roundtrip", DependsOn: []string{"produce"}, StartAfterMs: 60000, BenchTemplate: template},
+		}},
+		Status: v1alpha1.KafkaBenchScenarioStatus{StartTime: &metav1.Time{Time: start}},
+	}
+	completion := &metav1.Time{Time: start.Add(10 * time.Second)}
+	newBench := func(name, status string) v1alpha1.KafkaBench {
+		kb := v1alpha1.KafkaBench{ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{LabelKeyScenario: "pipeline"},
+		}}
+		kb.Spec.Class = v1alpha1.ProduceBenchClass
+		meta.AddOwnerReference(&kb, meta.AsController(meta.TypedReferenceTo(scenario, v1alpha1.KafkaBenchScenarioGroupVersionKind)))
+		kb.Status.AtProvider.TaskStatus = status
+		kb.Status.AtProvider.StartTime = &metav1.Time{Time: start}
+		if status == "DONE" {
+			kb.Status.AtProvider.CompletionTime = completion
+		}
+		kb.Status.AtProvider.ProducerStats = v1alpha1.ProducerBenchResultStats{TotalSent: 100000, P99LatencyMs: 42}
+		return kb
+	}
+	done := func(name, bench string) v1alpha1.ScenarioStepStatus {
+		return v1alpha1.ScenarioStepStatus{Name: name, Bench: bench, Phase: v1alpha1.BenchSucceeded, CompletionTime: completion, MessagesPerSec: 10000, P99LatencyMs: 42}
+	}
+
+	type want struct {
+		result  reconcile.Result
+		created []string
+		status  v1alpha1.KafkaBenchScenarioStatus
+		ready   xpv1.ConditionReason
+		events  []event.Reason
+	}
+	cases := map[string]struct {
+		reason  string
+		now     time.Time
+		benches []v1alpha1.KafkaBench
+		want    want
+	}{
+		"First": {
+			reason: "Steps without dependencies should start with the scenario.",
+			now:    start,
+			want: want{
+				created: []string{"pipeline-produce"},
+				status: v1alpha1.KafkaBenchScenarioStatus{StartTime: &metav1.Time{Time: start}, Steps: []v1alpha1.ScenarioStepStatus{
+					{Name: "produce", Bench: "pipeline-produce", Phase: v1alpha1.StepRunning},
+					{Name: "consume", Phase: v1alpha1.StepPending},
+					{Name: "roundtrip", Phase: v1alpha1.StepPending},
+				}},
+				ready:  xpv1.ReasonCreating,
+				events: []event.Reason{reasonCreateBench},
+			},
+		},
+		"Delayed": {
+			reason:  "Steps should start once their dependencies succeeded, past their startAfterMs.",
+			now:     start.Add(30 * time.Second),
+			benches: []v1alpha1.KafkaBench{newBench("pipeline-produce", "DONE")},
+			want: want{
+				result:  reconcile.Result{RequeueAfter: 40 * time.Second},
+				created: []string{"pipeline-consume"},
+				status: v1alpha1.KafkaBenchScenarioStatus{StartTime: &metav1.Time{Time: start}, Completed: 1, Steps: []v1alpha1.ScenarioStepStatus{
+					done("produce", "pipeline-produce"),
+					{Name: "consume", Bench: "pipeline-consume", Phase: v1alpha1.StepRunning},
+					{Name: "roundtrip", Phase: v1alpha1.StepPending},
+				}},
+				ready:  xpv1.ReasonCreating,
+				events: []event.Reason{reasonCreateBench},
+			},
+		},
+		"Failed": {
+			reason:  "Steps depending on a failed step should be skipped, and the scenario should fail.",
+			now:     start.Add(30 * time.Second),
+			benches: []v1alpha1.KafkaBench{newBench("pipeline-produce", "TIMED_OUT")},
+			want: want{
+				status: v1alpha1.KafkaBenchScenarioStatus{StartTime: &metav1.Time{Time: start}, Completed: 3, Result: v1alpha1.BenchFailed, Steps: []v1alpha1.ScenarioStepStatus{
+					{Name: "produce", Bench: "pipeline-produce", Phase: v1alpha1.BenchFailed, CompletionTime: &metav1.Time{Time: start.Add(30 * time.Second)}, P99LatencyMs: 42},
+					{Name: "consume", Phase: v1alpha1.StepSkipped},
+					{Name: "roundtrip", Phase: v1alpha1.StepSkipped},
+				}},
+				ready:  xpv1.ReasonAvailable,
+				events: []event.Reason{reasonSkipStep, reasonSkipStep},
+			},
+		},
+		"Succeeded": {
+			reason: "The scenario should succeed once every step succeeded.",
+			now:    start.Add(2 * time.Minute),
+			benches: []v1alpha1.KafkaBench{
+				newBench("pipeline-produce", "DONE"),
+				newBench("pipeline-consume", "DONE"),
+				newBench("pipeline-roundtrip", "DONE"),
+			},
+			want: want{
+				status: v1alpha1.KafkaBenchScenarioStatus{StartTime: &metav1.Time{Time: start}, Completed: 3, Result: v1alpha1.BenchSucceeded, Steps: []v1alpha1.ScenarioStepStatus{
+					done("produce", "pipeline-produce"),
+					done("consume", "pipeline-consume"),
+					done("roundtrip", "pipeline-roundtrip"),
+				}},
+				ready: xpv1.ReasonAvailable,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got want
+			var updated *v1alpha1.KafkaBenchScenario
+			kube := &test.MockClient{
+				MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					scenario.DeepCopyInto(obj.(*v1alpha1.KafkaBenchScenario))
+					return nil
+				}),
+				MockList: test.NewMockListFn(nil, func(obj client.ObjectList) error {
+					obj.(*v1alpha1.KafkaBenchList).Items = tc.benches
+					return nil
+				}),
+				MockCreate: func(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+					got.created = append(got.created, obj.GetName())
+					return nil
+				},
+				MockStatusUpdate: func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+					updated = obj.(*v1alpha1.KafkaBenchScenario)
+					return nil
+				},
+			}
+			rec := &benchestest.Recorder{}
+			r := &Reconciler{kube: kube, log: logging.NewNopLogger(), record: rec, now: func() time.Time { return tc.now }}
+
+			res, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: client.ObjectKey{Name: "pipeline"}})
+			if err != nil {
+				t.Fatalf("\n%s\nr.Reconcile(...): unexpected error: %v", tc.reason, err)
+			}
+			got.result = res
+			got.events = rec.Reasons
+			got.ready = updated.Status.GetCondition(xpv1.TypeReady).Reason
+			got.status = updated.Status
+			got.status.ConditionedStatus = xpv1.ConditionedStatus{}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
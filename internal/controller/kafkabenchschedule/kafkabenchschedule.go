@@ -0,0 +1,228 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kafkabenchschedule creates KafkaBenches on a cron schedule.
+package kafkabenchschedule
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/benches"
+)
+
+const (
+	// LabelKeySchedule is set to the name of the KafkaBenchSchedule that
+	// created a bench.
+	LabelKeySchedule = "tarasque.crossplane.io/schedule"
+	// AnnotationKeyScheduledAt is set to the tick a bench was created for.
+	AnnotationKeyScheduledAt = "tarasque.crossplane.io/scheduled-at"
+
+	timeout = 2 * time.Minute
+
+	errGetSchedule   = "cannot get KafkaBenchSchedule"
+	errParseSchedule = "cannot parse schedule"
+	errCreateBench   = "cannot create bench"
+	errDeleteBench   = "cannot delete bench"
+	errUpdateStatus  = "cannot update KafkaBenchSchedule status"
+)
+
+// Event reasons.
+const (
+	reasonCreateBench  event.Reason = "CreateBench"
+	reasonDeleteBench  event.Reason = "DeleteBench"
+	reasonSkipTick     event.Reason = "SkipTick"
+	reasonBadSchedule  event.Reason = "InvalidSchedule"
+	reasonCannotCreate event.Reason = "CannotCreateBench"
+)
+
+// Setup adds a controller that creates the KafkaBenches of
+// KafkaBenchSchedules.
+func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter) error {
+	name := "schedule/" + strings.ToLower(v1alpha1.KafkaBenchScheduleGroupKind)
+
+	r := &Reconciler{
+		kube:   mgr.GetClient(),
+		log:    l.WithValues("controller", name),
+		record: event.NewAPIRecorder(mgr.GetEventRecorderFor(name)),
+		now:    time.Now,
+	}
+
+	return benches.SetupOwner(mgr, name, &v1alpha1.KafkaBenchSchedule{}, r, rl)
+}
+
+// A Reconciler creates a KafkaBench at every tick of the schedule of a
+// KafkaBenchSchedule, and prunes the benches that finished beyond its history
+// limits.
+type Reconciler struct {
+	kube   client.Client
+	log    logging.Logger
+	record event.Recorder
+	now    func() time.Time
+}
+
+// Reconcile a KafkaBenchSchedule.
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	log := r.log.WithValues("request", req)
+	log.Debug("Reconciling")
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	s := &v1alpha1.KafkaBenchSchedule{}
+	if ok, err := benches.Get(ctx, r.kube, req.NamespacedName, s); !ok {
+		return reconcile.Result{}, errors.Wrap(err, errGetSchedule)
+	}
+
+	labels := map[string]string{LabelKeySchedule: s.GetName()}
+	owned, err := benches.Owned(ctx, r.kube, s, labels)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(benches.Failed(ctx, r.kube, s, err), errUpdateStatus)
+	}
+
+	active, succeeded, failed := []*v1alpha1.KafkaBench{}, []*v1alpha1.KafkaBench{}, []*v1alpha1.KafkaBench{}
+	for i := range owned {
+		kb := &owned[i]
+		res, done := benches.Result(kb)
+		switch {
+		case !done:
+			active = append(active, kb)
+			continue
+		case res == v1alpha1.BenchSucceeded:
+			succeeded = append(succeeded, kb)
+			t := kb.GetCreationTimestamp()
+			s.Status.LastSuccessfulTime = &t
+		default:
+			failed = append(failed, kb)
+		}
+		s.Status.LastBench = kb.GetName()
+		s.Status.LastResult = res
+	}
+	if err := r.prune(ctx, succeeded, s.Spec.SuccessfulBenchesHistoryLimit); err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := r.prune(ctx, failed, s.Spec.FailedBenchesHistoryLimit); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	sched, err := cron.ParseStandard(s.Spec.Schedule)
+	if err != nil {
+		err = errors.Wrap(err, errParseSchedule)
+		log.Debug("Cannot parse schedule", "error", err)
+		r.record.Event(s, event.Warning(reasonBadSchedule, err))
+		s.Status.Active = names(active)
+		// There's no need to requeue until the schedule is fixed.
+		return reconcile.Result{}, errors.Wrap(benches.Failed(ctx, r.kube, s, err), errUpdateStatus)
+	}
+
+	now := r.now()
+	tick := lastTick(sched, s, now)
+	switch {
+	case tick.IsZero() || s.Spec.Suspend:
+	case s.Spec.ConcurrencyPolicy == v1alpha1.ForbidConcurrent && len(active) > 0:
+		// Like a CronJob, the tick is kept until the active bench is done,
+		// or until it is past its starting deadline. It is only reported
+		// once, rather than whenever one of the benches changes.
+		if t := s.Status.LastSkippedTime; t == nil || !t.Time.Equal(tick) {
+			r.record.Event(s, event.Normal(reasonSkipTick, fmt.Sprintf("Bench %s is still running", active[0].GetName())))
+			s.Status.LastSkippedTime = &metav1.Time{Time: tick}
+		}
+	default:
+		if s.Spec.ConcurrencyPolicy == v1alpha1.ReplaceConcurrent {
+			for _, kb := range active {
+				if err := r.kube.Delete(ctx, kb); resource.IgnoreNotFound(err) != nil {
+					return reconcile.Result{}, errors.Wrap(err, errDeleteBench)
+				}
+				r.record.Event(s, event.Normal(reasonDeleteBench, fmt.Sprintf("Replaced bench %s", kb.GetName())))
+			}
+			active = nil
+		}
+		kb := benches.New(s, v1alpha1.KafkaBenchScheduleGroupVersionKind, fmt.Sprintf("%s-%d", s.GetName(), tick.Unix()/60), s.Spec.BenchTemplate, labels)
+		meta.AddAnnotations(kb, map[string]string{AnnotationKeyScheduledAt: tick.Format(time.RFC3339)})
+		if err := r.kube.Create(ctx, kb); resource.Ignore(kerrors.IsAlreadyExists, err) != nil {
+			err = errors.Wrap(err, errCreateBench)
+			r.record.Event(s, event.Warning(reasonCannotCreate, err))
+			return reconcile.Result{}, errors.Wrap(benches.Failed(ctx, r.kube, s, err), errUpdateStatus)
+		}
+		r.record.Event(s, event.Normal(reasonCreateBench, fmt.Sprintf("Created bench %s", kb.GetName())))
+		s.Status.LastScheduleTime = &metav1.Time{Time: tick}
+		active = append(active, kb)
+	}
+
+	s.Status.Active = names(active)
+	s.Status.SetConditions(xpv1.ReconcileSuccess())
+	return reconcile.Result{RequeueAfter: sched.Next(now).Sub(now)}, errors.Wrap(r.kube.Status().Update(ctx, s), errUpdateStatus)
+}
+
+// prune deletes the oldest of the supplied finished benches until there are
+// no more than limit left. Nil limits keep every bench.
+func (r *Reconciler) prune(ctx context.Context, finished []*v1alpha1.KafkaBench, limit *int32) error {
+	if limit == nil || len(finished) <= int(*limit) {
+		return nil
+	}
+	for _, kb := range finished[:len(finished)-int(*limit)] {
+		if err := r.kube.Delete(ctx, kb); resource.IgnoreNotFound(err) != nil {
+			return errors.Wrap(err, errDeleteBench)
+		}
+	}
+	return nil
+}
+
+// lastTick returns the last tick of the supplied schedule that is due by now
+// and that no bench was created for yet, or the zero time if there is none.
+// Ticks past the starting deadline of the schedule are skipped.
+func lastTick(sched cron.Schedule, s *v1alpha1.KafkaBenchSchedule, now time.Time) time.Time {
+	earliest := s.GetCreationTimestamp().Time
+	if t := s.Status.LastScheduleTime; t != nil {
+		earliest = t.Time
+	}
+	if d := s.Spec.StartingDeadlineSeconds; d != nil {
+		if deadline := now.Add(-time.Duration(*d) * time.Second); deadline.After(earliest) {
+			earliest = deadline
+		}
+	}
+	tick := time.Time{}
+	for t := sched.Next(earliest); !t.After(now); t = sched.Next(t) {
+		tick = t
+	}
+	return tick
+}
+
+func names(kbs []*v1alpha1.KafkaBench) []string {
+	n := make([]string, 0, len(kbs))
+	for _, kb := range kbs {
+		n = append(n, kb.GetName())
+	}
+	return n
+}
@@ -0,0 +1,264 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkabenchschedule
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/benches/benchestest"
+)
+
+func TestReconcile(t *testing.T) {
+	now := time.Date(2022, 6, 1, 2, 30, 0, 0, time.UTC)
+	tick := time.Date(2022, 6, 1, 2, 0, 0, 0, time.UTC)
+	tickName := fmt.Sprintf("nightly-%d", tick.Unix()/60)
+	one := int32(1)
+	minute := int64(60)
+
+	newSchedule := func(m ...func(*v1alpha1.KafkaBenchSchedule)) *v1alpha1.KafkaBenchSchedule {
+		s := &v1alpha1.KafkaBenchSchedule{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "nightly",
+				UID:               "nightly-uid",
+				CreationTimestamp: metav1.NewTime(now.Add(-48 * time.Hour)),
+			},
+			Spec: v1alpha1.KafkaBenchScheduleSpec{
+				Schedule:          "0 2 * * *",
+				ConcurrencyPolicy: v1alpha1.AllowConcurrent,
+				BenchTemplate: v1alpha1.KafkaBenchTemplate{
+					Metadata: v1alpha1.KafkaBenchTemplateMeta{Labels: map[string]string{"team": "kafka"}},
+					Spec:     v1alpha1.KafkaBenchSpec{KafkaBenchParameters: v1alpha1.KafkaBenchParameters{DurationMs: 60000}},
+				},
+			},
+		}
+		s.Status.LastScheduleTime = &metav1.Time{Time: tick.Add(-24 * time.Hour)}
+		for _, fn := range m {
+			fn(s)
+		}
+		return s
+	}
+	newBench := func(name string, created time.Time, status string) v1alpha1.KafkaBench {
+		kb := v1alpha1.KafkaBench{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(created),
+			Labels:            map[string]string{LabelKeySchedule: "nightly"},
+		}}
+		meta.AddOwnerReference(&kb, meta.AsController(meta.TypedReferenceTo(newSchedule(), v1alpha1.KafkaBenchScheduleGroupVersionKind)))
+		kb.Status.AtProvider.TaskStatus = status
+		return kb
+	}
+
+	type want struct {
+		result  reconcile.Result
+		created []string
+		deleted []string
+		status  v1alpha1.KafkaBenchScheduleStatus
+		events  []event.Reason
+	}
+	cases := map[string]struct {
+		reason   string
+		schedule *v1alpha1.KafkaBenchSchedule
+		benches  []v1alpha1.KafkaBench
+		want     want
+	}{
+		"Due": {
+			reason:   "A bench should be created for a tick that is due.",
+			schedule: newSchedule(),
+			want: want{
+				result:  reconcile.Result{RequeueAfter: 23*time.Hour + 30*time.Minute},
+				created: []string{tickName},
+				status: v1alpha1.KafkaBenchScheduleStatus{
+					Active:           []string{tickName},
+					LastScheduleTime: &metav1.Time{Time: tick},
+				},
+				events: []event.Reason{reasonCreateBench},
+			},
+		},
+		"NotDue": {
+			reason: "No bench should be created when the last tick already has one.",
+			schedule: newSchedule(func(s *v1alpha1.KafkaBenchSchedule) {
+				s.Status.LastScheduleTime = &metav1.Time{Time: tick}
+			}),
+			want: want{
+				result: reconcile.Result{RequeueAfter: 23*time.Hour + 30*time.Minute},
+				status: v1alpha1.KafkaBenchScheduleStatus{Active: []string{}, LastScheduleTime: &metav1.Time{Time: tick}},
+			},
+		},
+		"Suspended": {
+			reason: "No bench should be created while the schedule is suspended.",
+			schedule: newSchedule(func(s *v1alpha1.KafkaBenchSchedule) {
+				s.Spec.Suspend = true
+			}),
+			want: want{
+				result: reconcile.Result{RequeueAfter: 23*time.Hour + 30*time.Minute},
+				status: v1alpha1.KafkaBenchScheduleStatus{Active: []string{}, LastScheduleTime: &metav1.Time{Time: tick.Add(-24 * time.Hour)}},
+			},
+		},
+		"PastStartingDeadline": {
+			reason: "Ticks missed for longer than the starting deadline should be skipped.",
+			schedule: newSchedule(func(s *v1alpha1.KafkaBenchSchedule) {
+				s.Spec.StartingDeadlineSeconds = &minute
+			}),
+			want: want{
+				result: reconcile.Result{RequeueAfter: 23*time.Hour + 30*time.Minute},
+				status: v1alpha1.KafkaBenchScheduleStatus{Active: []string{}, LastScheduleTime: &metav1.Time{Time: tick.Add(-24 * time.Hour)}},
+			},
+		},
+		"Forbid": {
+			reason: "No bench should be created while the bench of a previous tick runs under the Forbid policy.",
+			schedule: newSchedule(func(s *v1alpha1.KafkaBenchSchedule) {
+				s.Spec.ConcurrencyPolicy = v1alpha1.ForbidConcurrent
+			}),
+			benches: []v1alpha1.KafkaBench{newBench("nightly-old", tick.Add(-24*time.Hour), "RUNNING")},
+			want: want{
+				result: reconcile.Result{RequeueAfter: 23*time.Hour + 30*time.Minute},
+				status: v1alpha1.KafkaBenchScheduleStatus{
+					Active:           []string{"nightly-old"},
+					LastScheduleTime: &metav1.Time{Time: tick.Add(-24 * time.Hour)},
+					LastSkippedTime:  &metav1.Time{Time: tick},
+				},
+				events: []event.Reason{reasonSkipTick},
+			},
+		},
+		"ForbidSkipped": {
+			reason: "A tick skipped under the Forbid policy should only be reported once.",
+			schedule: newSchedule(func(s *v1alpha1.KafkaBenchSchedule) {
+				s.Spec.ConcurrencyPolicy = v1alpha1.ForbidConcurrent
+				s.Status.LastSkippedTime = &metav1.Time{Time: tick}
+			}),
+			benches: []v1alpha1.KafkaBench{newBench("nightly-old", tick.Add(-24*time.Hour), "RUNNING")},
+			want: want{
+				result: reconcile.Result{RequeueAfter: 23*time.Hour + 30*time.Minute},
+				status: v1alpha1.KafkaBenchScheduleStatus{
+					Active:           []string{"nightly-old"},
+					LastScheduleTime: &metav1.Time{Time: tick.Add(-24 * time.Hour)},
+					LastSkippedTime:  &metav1.Time{Time: tick},
+				},
+			},
+		},
+		"Replace": {
+			reason: "The bench of a previous tick should be deleted and replaced under the Replace policy.",
+			schedule: newSchedule(func(s *v1alpha1.KafkaBenchSchedule) {
+				s.Spec.ConcurrencyPolicy = v1alpha1.ReplaceConcurrent
+			}),
+			benches: []v1alpha1.KafkaBench{newBench("nightly-old", tick.Add(-24*time.Hour), "RUNNING")},
+			want: want{
+				result:  reconcile.Result{RequeueAfter: 23*time.Hour + 30*time.Minute},
+				created: []string{tickName},
+				deleted: []string{"nightly-old"},
+				status: v1alpha1.KafkaBenchScheduleStatus{
+					Active:           []string{tickName},
+					LastScheduleTime: &metav1.Time{Time: tick},
+				},
+				events: []event.Reason{reasonDeleteBench, reasonCreateBench},
+			},
+		},
+		"History": {
+			reason: "The oldest finished benches beyond the history limits should be deleted, and the last result recorded.",
+			schedule: newSchedule(func(s *v1alpha1.KafkaBenchSchedule) {
+				s.Spec.SuccessfulBenchesHistoryLimit = &one
+				s.Spec.FailedBenchesHistoryLimit = &one
+				s.Status.LastScheduleTime = &metav1.Time{Time: tick}
+			}),
+			benches: []v1alpha1.KafkaBench{
+				newBench("nightly-1", tick.Add(-96*time.Hour), "DONE"),
+				newBench("nightly-2", tick.Add(-72*time.Hour), "TIMED_OUT"),
+				newBench("nightly-3", tick.Add(-48*time.Hour), "DONE"),
+				newBench("nightly-4", tick.Add(-24*time.Hour), "TIMED_OUT"),
+			},
+			want: want{
+				result:  reconcile.Result{RequeueAfter: 23*time.Hour + 30*time.Minute},
+				deleted: []string{"nightly-1", "nightly-2"},
+				status: v1alpha1.KafkaBenchScheduleStatus{
+					Active:             []string{},
+					LastScheduleTime:   &metav1.Time{Time: tick},
+					LastSuccessfulTime: &metav1.Time{Time: tick.Add(-48 * time.Hour)},
+					LastBench:          "nightly-4",
+					LastResult:         v1alpha1.BenchFailed,
+				},
+			},
+		},
+		"InvalidSchedule": {
+			reason: "A schedule that cannot be parsed should be reported without creating benches.",
+			schedule: newSchedule(func(s *v1alpha1.KafkaBenchSchedule) {
+				s.Spec.Schedule = "every night"
+			}),
+			want: want{
+				status: v1alpha1.KafkaBenchScheduleStatus{Active: []string{}, LastScheduleTime: &metav1.Time{Time: tick.Add(-24 * time.Hour)}},
+				events: []event.Reason{reasonBadSchedule},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got want
+			var updated *v1alpha1.KafkaBenchSchedule
+			kube := &test.MockClient{
+				MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					tc.schedule.DeepCopyInto(obj.(*v1alpha1.KafkaBenchSchedule))
+					return nil
+				}),
+				MockList: test.NewMockListFn(nil, func(obj client.ObjectList) error {
+					obj.(*v1alpha1.KafkaBenchList).Items = tc.benches
+					return nil
+				}),
+				MockCreate: func(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+					got.created = append(got.created, obj.GetName())
+					return nil
+				},
+				MockDelete: func(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+					got.deleted = append(got.deleted, obj.GetName())
+					return nil
+				},
+				MockStatusUpdate: func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+					updated = obj.(*v1alpha1.KafkaBenchSchedule)
+					return nil
+				},
+			}
+			rec := &benchestest.Recorder{}
+			r := &Reconciler{kube: kube, log: logging.NewNopLogger(), record: rec, now: func() time.Time { return now }}
+
+			res, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: client.ObjectKey{Name: "nightly"}})
+			if err != nil {
+				t.Fatalf("\n%s\nr.Reconcile(...): unexpected error: %v", tc.reason, err)
+			}
+			got.result = res
+			got.events = rec.Reasons
+			got.status = updated.Status
+			got.status.ConditionedStatus = xpv1.ConditionedStatus{}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kafkabenchsweep runs a KafkaBench for every combination of values
+// of the axes of a KafkaBenchSweep.
+package kafkabenchsweep
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/benches"
+)
+
+const (
+	// LabelKeySweep is set to the name of the KafkaBenchSweep that created a
+	// bench.
+	LabelKeySweep = "tarasque.crossplane.io/sweep"
+
+	timeout = 2 * time.Minute
+
+	errGetSweep     = "cannot get KafkaBenchSweep"
+	errCreateBench  = "cannot create bench"
+	errUpdateStatus = "cannot update KafkaBenchSweep status"
+	errApplyAxes    = "cannot apply axes"
+	errFmtNotConf   = "%s is not a client configuration"
+)
+
+// Event reasons.
+const (
+	reasonCreateBench  event.Reason = "CreateBench"
+	reasonBadAxis      event.Reason = "InvalidAxis"
+	reasonCannotCreate event.Reason = "CannotCreateBench"
+)
+
+// Setup adds a controller that runs the KafkaBenches of KafkaBenchSweeps.
+func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter) error {
+	name := "sweep/" + strings.ToLower(v1alpha1.KafkaBenchSweepGroupKind)
+
+	r := &Reconciler{
+		kube:   mgr.GetClient(),
+		log:    l.WithValues("controller", name),
+		record: event.NewAPIRecorder(mgr.GetEventRecorderFor(name)),
+	}
+
+	return benches.SetupOwner(mgr, name, &v1alpha1.KafkaBenchSweep{}, r, rl)
+}
+
+// A Reconciler creates a KafkaBench for every combination of values of the
+// axes of a KafkaBenchSweep, no more than its parallelism at once, and
+// summarizes their results.
+type Reconciler struct {
+	kube   client.Client
+	log    logging.Logger
+	record event.Recorder
+}
+
+// Reconcile a KafkaBenchSweep.
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	log := r.log.WithValues("request", req)
+	log.Debug("Reconciling")
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	s := &v1alpha1.KafkaBenchSweep{}
+	if ok, err := benches.Get(ctx, r.kube, req.NamespacedName, s); !ok {
+		return reconcile.Result{}, errors.Wrap(err, errGetSweep)
+	}
+
+	combos := combinations(s.Spec.Axes)
+	specs := make([]v1alpha1.KafkaBenchSpec, len(combos))
+	for i, values := range combos {
+		spec, err := apply(s.Spec.BenchTemplate.Spec, s.Spec.Axes, values)
+		if err != nil {
+			log.Debug("Cannot apply axes", "error", err)
+			r.record.Event(s, event.Warning(reasonBadAxis, err))
+			// There's no need to requeue until the axes are fixed.
+			return reconcile.Result{}, errors.Wrap(benches.Failed(ctx, r.kube, s, err), errUpdateStatus)
+		}
+		specs[i] = spec
+	}
+
+	labels := map[string]string{LabelKeySweep: s.GetName()}
+	owned, err := benches.Owned(ctx, r.kube, s, labels)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(benches.Failed(ctx, r.kube, s, err), errUpdateStatus)
+	}
+	byName := make(map[string]*v1alpha1.KafkaBench, len(owned))
+	for i := range owned {
+		byName[owned[i].GetName()] = &owned[i]
+	}
+
+	parallelism := int(s.Spec.Parallelism)
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	results := make([]v1alpha1.SweepResult, len(combos))
+	completed, running := 0, 0
+	pending := []int{}
+	for i, values := range combos {
+		results[i] = v1alpha1.SweepResult{Bench: fmt.Sprintf("%s-%d", s.GetName(), i), Parameters: parameters(s.Spec.Axes, values)}
+		kb, ok := byName[results[i].Bench]
+		if !ok {
+			pending = append(pending, i)
+			continue
+		}
+		res, done := benches.Result(kb)
+		if !done {
+			running++
+			continue
+		}
+		completed++
+		results[i].Result = res
+		results[i].MessagesPerSec, results[i].P99LatencyMs = benches.Throughput(kb)
+	}
+
+	for _, i := range pending {
+		if running >= parallelism {
+			break
+		}
+		kb := benches.New(s, v1alpha1.KafkaBenchSweepGroupVersionKind, results[i].Bench, v1alpha1.KafkaBenchTemplate{Metadata: s.Spec.BenchTemplate.Metadata, Spec: specs[i]}, labels)
+		if err := r.kube.Create(ctx, kb); resource.Ignore(kerrors.IsAlreadyExists, err) != nil {
+			err = errors.Wrap(err, errCreateBench)
+			r.record.Event(s, event.Warning(reasonCannotCreate, err))
+			return reconcile.Result{}, errors.Wrap(benches.Failed(ctx, r.kube, s, err), errUpdateStatus)
+		}
+		r.record.Event(s, event.Normal(reasonCreateBench, fmt.Sprintf("Created bench %s", kb.GetName())))
+		running++
+	}
+
+	s.Status.Combinations = int32(len(combos))
+	s.Status.Completed = int32(completed)
+	s.Status.Results = results
+	s.Status.SetConditions(xpv1.ReconcileSuccess(), xpv1.Creating())
+	if completed == len(combos) {
+		s.Status.SetConditions(xpv1.Available())
+	}
+	return reconcile.Result{}, errors.Wrap(r.kube.Status().Update(ctx, s), errUpdateStatus)
+}
+
+// combinations returns every combination of values of the supplied axes, the
+// values of the last axis varying first.
+func combinations(axes []v1alpha1.SweepAxis) [][]string {
+	combos := [][]string{{}}
+	for _, a := range axes {
+		next := make([][]string, 0, len(combos)*len(a.Values))
+		for _, c := range combos {
+			for _, v := range a.Values {
+				next = append(next, append(append([]string{}, c...), v))
+			}
+		}
+		combos = next
+	}
+	return combos
+}
+
+// parameters returns the supplied values by the name of their axis.
+func parameters(axes []v1alpha1.SweepAxis, values []string) map[string]string {
+	p := make(map[string]string, len(axes))
+	for i, a := range axes {
+		name := a.Field
+		if a.Key != "" {
+			name += "." + a.Key
+		}
+		p[name] = values[i]
+	}
+	return p
+}
+
+// apply returns a copy of the supplied spec with the supplied values of the
+// supplied axes. Values of spec fields are read as JSON, falling back to
+// plain strings, while client configurations are always strings.
+func apply(spec v1alpha1.KafkaBenchSpec, axes []v1alpha1.SweepAxis, values []string) (v1alpha1.KafkaBenchSpec, error) {
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return v1alpha1.KafkaBenchSpec{}, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return v1alpha1.KafkaBenchSpec{}, err
+	}
+	for i, a := range axes {
+		if a.Key == "" {
+			var v interface{}
+			if err := json.Unmarshal([]byte(values[i]), &v); err != nil {
+				v = values[i]
+			}
+			fields[a.Field] = v
+			continue
+		}
+		conf, ok := fields[a.Field].(map[string]interface{})
+		if !ok && fields[a.Field] != nil {
+			return v1alpha1.KafkaBenchSpec{}, errors.Wrap(errors.Errorf(errFmtNotConf, a.Field), errApplyAxes)
+		}
+		if conf == nil {
+			conf = map[string]interface{}{}
+		}
+		conf[a.Key] = values[i]
+		fields[a.Field] = conf
+	}
+	if b, err = json.Marshal(fields); err != nil {
+		return v1alpha1.KafkaBenchSpec{}, err
+	}
+	// Unknown fields are rejected so that misspelt axes are reported.
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	out := v1alpha1.KafkaBenchSpec{}
+	return out, errors.Wrap(d.Decode(&out), errApplyAxes)
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkabenchsweep
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/benches/benchestest"
+)
+
+var axes = []v1alpha1.SweepAxis{
+	{Field: "producerConf", Key: "batch.size", Values: []string{"16384", "65536"}},
+	{Field: "producerConf", Key: "compression.type", Values: []string{"none", "lz4", "zstd"}},
+}
+
+func TestCombinations(t *testing.T) {
+	want := [][]string{
+		{"16384", "none"}, {"16384", "lz4"}, {"16384", "zstd"},
+		{"65536", "none"}, {"65536", "lz4"}, {"65536", "zstd"},
+	}
+	if diff := cmp.Diff(want, combinations(axes)); diff != "" {
+		t.Errorf("combinations(...): -want, +got:\n%s\n", diff)
+	}
+}
+
+func TestApply(t *testing.T) {
+	base := v1alpha1.KafkaBenchSpec{KafkaBenchParameters: v1alpha1.KafkaBenchParameters{
+		Class:        v1alpha1.ProduceBenchClass,
+		DurationMs:   60000,
+		ProducerConf: map[string]string{"acks": "all"},
+	}}
+	type want struct {
+		spec v1alpha1.KafkaBenchSpec
+		err  bool
+	}
+	cases := map[string]struct {
+		reason string
+		axes   []v1alpha1.SweepAxis
+		values []string
+		want   want
+	}{
+		"ClientConf": {
+			reason: "Client configuration keys should be added to those of the template.",
+			axes:   axes,
+			values: []string{"65536", "lz4"},
+			want: want{spec: v1alpha1.KafkaBenchSpec{KafkaBenchParameters: v1alpha1.KafkaBenchParameters{
+				Class:        v1alpha1.ProduceBenchClass,
+				DurationMs:   60000,
+				ProducerConf: map[string]string{"acks": "all", "batch.size": "65536", "compression.type": "lz4"},
+			}}},
+		},
+		"Field": {
+			reason: "Values of spec fields should be read as JSON.",
+			axes:   []v1alpha1.SweepAxis{{Field: "targetMessagesPerSec"}, {Field: "bootstrapServers"}},
+			values: []string{"5000", "kafka:9092"},
+			want: want{spec: v1alpha1.KafkaBenchSpec{KafkaBenchParameters: v1alpha1.KafkaBenchParameters{
+				Class:                v1alpha1.ProduceBenchClass,
+				DurationMs:           60000,
+				BootstrapServers:     "kafka:9092",
+				TargetMessagesPerSec: 5000,
+				ProducerConf:         map[string]string{"acks": "all"},
+			}}},
+		},
+		"UnknownField": {
+			reason: "Misspelt spec fields should be reported.",
+			axes:   []v1alpha1.SweepAxis{{Field: "targetMessagePerSec"}},
+			values: []string{"5000"},
+			want:   want{err: true},
+		},
+		"NotConf": {
+			reason: "Keys of fields that are not client configurations should be reported.",
+			axes:   []v1alpha1.SweepAxis{{Field: "durationMs", Key: "batch.size"}},
+			values: []string{"5000"},
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			spec, err := apply(base, tc.axes, tc.values)
+			if diff := cmp.Diff(tc.want.err, err != nil); diff != "" {
+				t.Fatalf("\n%s\napply(...): -want error, +got error: %v\n", tc.reason, err)
+			}
+			if tc.want.err {
+				return
+			}
+			if diff := cmp.Diff(tc.want.spec, spec); diff != "" {
+				t.Errorf("\n%s\napply(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	start := time.Date(2022, 6, 1, 2, 0, 0, 0, time.UTC)
+	sweep := &v1alpha1.KafkaBenchSweep{
+		ObjectMeta: metav1.ObjectMeta{Name: "batching", UID: "batching-uid"},
+		Spec: v1alpha1.KafkaBenchSweepSpec{
+			BenchTemplate: v1alpha1.KafkaBenchTemplate{Spec: v1alpha1.KafkaBenchSpec{KafkaBenchParameters: v1alpha1.KafkaBenchParameters{
+				Class: v1alpha1.ProduceBenchClass,
+			}}},
+			Axes:        axes[:1],
+			Parallelism: 1,
+		},
+	}
+	newBench := func(name, status string, sent int64) v1alpha1.KafkaBench {
+		kb := v1alpha1.KafkaBench{ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{LabelKeySweep: "batching"},
+		}}
+		kb.Spec.Class = v1alpha1.ProduceBenchClass
+		meta.AddOwnerReference(&kb, meta.AsController(meta.TypedReferenceTo(sweep, v1alpha1.KafkaBenchSweepGroupVersionKind)))
+		kb.Status.AtProvider.TaskStatus = status
+		kb.Status.AtProvider.StartTime = &metav1.Time{Time: start}
+		if status == "DONE" {
+			kb.Status.AtProvider.CompletionTime = &metav1.Time{Time: start.Add(10 * time.Second)}
+		}
+		kb.Status.AtProvider.ProducerStats = v1alpha1.ProducerBenchResultStats{TotalSent: sent, P99LatencyMs: 42}
+		return kb
+	}
+
+	type want struct {
+		created []string
+		status  v1alpha1.KafkaBenchSweepStatus
+		ready   xpv1.ConditionReason
+		events  []event.Reason
+	}
+	cases := map[string]struct {
+		reason  string
+		benches []v1alpha1.KafkaBench
+		want    want
+	}{
+		"First": {
+			reason: "The bench of the first combination should be created.",
+			want: want{
+				created: []string{"batching-0"},
+				status: v1alpha1.KafkaBenchSweepStatus{Combinations: 2, Results: []v1alpha1.SweepResult{
+					{Bench: "batching-0", Parameters: map[string]string{"producerConf.batch.size": "16384"}},
+					{Bench: "batching-1", Parameters: map[string]string{"producerConf.batch.size": "65536"}},
+				}},
+				ready:  xpv1.ReasonCreating,
+				events: []event.Reason{reasonCreateBench},
+			},
+		},
+		"Running": {
+			reason:  "No bench should be created beyond the parallelism of the sweep.",
+			benches: []v1alpha1.KafkaBench{newBench("batching-0", "RUNNING", 0)},
+			want: want{
+				status: v1alpha1.KafkaBenchSweepStatus{Combinations: 2, Results: []v1alpha1.SweepResult{
+					{Bench: "batching-0", Parameters: map[string]string{"producerConf.batch.size": "16384"}},
+					{Bench: "batching-1", Parameters: map[string]string{"producerConf.batch.size": "65536"}},
+				}},
+				ready: xpv1.ReasonCreating,
+			},
+		},
+		"Next": {
+			reason:  "The bench of the next combination should be created once the previous one is done.",
+			benches: []v1alpha1.KafkaBench{newBench("batching-0", "DONE", 100000)},
+			want: want{
+				created: []string{"batching-1"},
+				status: v1alpha1.KafkaBenchSweepStatus{Combinations: 2, Completed: 1, Results: []v1alpha1.SweepResult{
+					{Bench: "batching-0", Parameters: map[string]string{"producerConf.batch.size": "16384"}, Result: v1alpha1.BenchSucceeded, MessagesPerSec: 10000, P99LatencyMs: 42},
+					{Bench: "batching-1", Parameters: map[string]string{"producerConf.batch.size": "65536"}},
+				}},
+				ready:  xpv1.ReasonCreating,
+				events: []event.Reason{reasonCreateBench},
+			},
+		},
+		"Completed": {
+			reason:  "The sweep should be available once every bench finished.",
+			benches: []v1alpha1.KafkaBench{newBench("batching-0", "DONE", 100000), newBench("batching-1", "TIMED_OUT", 5000)},
+			want: want{
+				status: v1alpha1.KafkaBenchSweepStatus{Combinations: 2, Completed: 2, Results: []v1alpha1.SweepResult{
+					{Bench: "batching-0", Parameters: map[string]string{"producerConf.batch.size": "16384"}, Result: v1alpha1.BenchSucceeded, MessagesPerSec: 10000, P99LatencyMs: 42},
+					{Bench: "batching-1", Parameters: map[string]string{"producerConf.batch.size": "65536"}, Result: v1alpha1.BenchFailed, P99LatencyMs: 42},
+				}},
+				ready: xpv1.ReasonAvailable,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got want
+			var updated *v1alpha1.KafkaBenchSweep
+			kube := &test.MockClient{
+				MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					sweep.DeepCopyInto(obj.(*v1alpha1.KafkaBenchSweep))
+					return nil
+				}),
+				MockList: test.NewMockListFn(nil, func(obj client.ObjectList) error {
+					obj.(*v1alpha1.KafkaBenchList).Items = tc.benches
+					return nil
+				}),
+				MockCreate: func(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+					got.created = append(got.created, obj.GetName())
+					return nil
+				},
+				MockStatusUpdate: func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+					updated = obj.(*v1alpha1.KafkaBenchSweep)
+					return nil
+				},
+			}
+			rec := &benchestest.Recorder{}
+			r := &Reconciler{kube: kube, log: logging.NewNopLogger(), record: rec}
+
+			if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: client.ObjectKey{Name: "batching"}}); err != nil {
+				t.Fatalf("\n%s\nr.Reconcile(...): unexpected error: %v", tc.reason, err)
+			}
+			got.events = rec.Reasons
+			got.ready = updated.Status.GetCondition(xpv1.TypeReady).Reason
+			got.status = updated.Status
+			got.status.ConditionedStatus = xpv1.ConditionedStatus{}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
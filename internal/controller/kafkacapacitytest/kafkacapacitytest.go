@@ -0,0 +1,262 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kafkacapacitytest searches for the maximum sustainable throughput
+// of a cluster by running produce benches at increasing targets.
+package kafkacapacitytest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/benches"
+)
+
+const (
+	// LabelKeyCapacityTest is set to the name of the KafkaCapacityTest that
+	// created a bench.
+	LabelKeyCapacityTest = "tarasque.crossplane.io/capacity-test"
+
+	timeout = 2 * time.Minute
+
+	errGetTest      = "cannot get KafkaCapacityTest"
+	errCreateBench  = "cannot create bench"
+	errUpdateStatus = "cannot update KafkaCapacityTest status"
+	errNotProduce   = "the benchTemplate of a capacity test must be a " + v1alpha1.ProduceBenchClass
+)
+
+// Event reasons.
+const (
+	reasonCreateBench  event.Reason = "CreateBench"
+	reasonSaturated    event.Reason = "Saturated"
+	reasonCannotCreate event.Reason = "CannotCreateBench"
+)
+
+// Setup adds a controller that runs the probes of KafkaCapacityTests.
+func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter) error {
+	name := "capacity/" + strings.ToLower(v1alpha1.KafkaCapacityTestGroupKind)
+
+	r := &Reconciler{
+		kube:   mgr.GetClient(),
+		log:    l.WithValues("controller", name),
+		record: event.NewAPIRecorder(mgr.GetEventRecorderFor(name)),
+	}
+
+	return benches.SetupOwner(mgr, name, &v1alpha1.KafkaCapacityTest{}, r, rl)
+}
+
+// A Reconciler runs the probes of a KafkaCapacityTest one after the other,
+// each at a target chosen from the results of the previous ones.
+type Reconciler struct {
+	kube   client.Client
+	log    logging.Logger
+	record event.Recorder
+}
+
+// Reconcile a KafkaCapacityTest.
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	log := r.log.WithValues("request", req)
+	log.Debug("Reconciling")
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ct := &v1alpha1.KafkaCapacityTest{}
+	if ok, err := benches.Get(ctx, r.kube, req.NamespacedName, ct); !ok {
+		return reconcile.Result{}, errors.Wrap(err, errGetTest)
+	}
+	if ct.Spec.BenchTemplate.Spec.Class != v1alpha1.ProduceBenchClass {
+		// There's no need to requeue until the template is fixed.
+		return reconcile.Result{}, errors.Wrap(benches.Failed(ctx, r.kube, ct, errors.New(errNotProduce)), errUpdateStatus)
+	}
+	spec := withDefaults(ct.Spec)
+
+	labels := map[string]string{LabelKeyCapacityTest: ct.GetName()}
+	owned, err := benches.Owned(ctx, r.kube, ct, labels)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(benches.Failed(ctx, r.kube, ct, err), errUpdateStatus)
+	}
+	byName := make(map[string]*v1alpha1.KafkaBench, len(owned))
+	for i := range owned {
+		byName[owned[i].GetName()] = &owned[i]
+	}
+
+	probes := []v1alpha1.CapacityProbe{}
+	running := false
+	for i := 0; ; i++ {
+		kb, ok := byName[probeName(ct, i)]
+		if !ok {
+			break
+		}
+		p := v1alpha1.CapacityProbe{Bench: kb.GetName(), TargetMessagesPerSec: kb.Spec.TargetMessagesPerSec}
+		res, done := benches.Result(kb)
+		if !done {
+			running = true
+			probes = append(probes, p)
+			break
+		}
+		p.Result = res
+		p.MessagesPerSec, p.P99LatencyMs = benches.Throughput(kb)
+		p.Sustained = res == v1alpha1.BenchSucceeded &&
+			p.MessagesPerSec*100 >= int64(p.TargetMessagesPerSec)*int64(spec.MinAchievedPercent) &&
+			p.P99LatencyMs <= spec.P99LatencyMs
+		probes = append(probes, p)
+	}
+
+	available := resource.IsConditionTrue(ct.Status.GetCondition(xpv1.TypeReady))
+	ct.Status.SetConditions(xpv1.Creating())
+	if !running {
+		target, done := next(spec, probes)
+		if done {
+			if !available {
+				r.record.Event(ct, event.Normal(reasonSaturated, fmt.Sprintf("Saturated at %d messages/s", saturation(probes))))
+			}
+			ct.Status.SetConditions(xpv1.Available())
+		} else {
+			kb := benches.New(ct, v1alpha1.KafkaCapacityTestGroupVersionKind, probeName(ct, len(probes)), probeTemplate(ct.Spec.BenchTemplate, target), labels)
+			if err := r.kube.Create(ctx, kb); resource.Ignore(kerrors.IsAlreadyExists, err) != nil {
+				err = errors.Wrap(err, errCreateBench)
+				r.record.Event(ct, event.Warning(reasonCannotCreate, err))
+				return reconcile.Result{}, errors.Wrap(benches.Failed(ctx, r.kube, ct, err), errUpdateStatus)
+			}
+			r.record.Event(ct, event.Normal(reasonCreateBench, fmt.Sprintf("Probing %d messages/s with bench %s", target, kb.GetName())))
+			probes = append(probes, v1alpha1.CapacityProbe{Bench: kb.GetName(), TargetMessagesPerSec: target})
+		}
+	}
+
+	ct.Status.SaturationMessagesPerSec = saturation(probes)
+	ct.Status.Probes = probes
+	ct.Status.Curve = curve(probes)
+	ct.Status.SetConditions(xpv1.ReconcileSuccess())
+	return reconcile.Result{}, errors.Wrap(r.kube.Status().Update(ctx, ct), errUpdateStatus)
+}
+
+func probeName(ct *v1alpha1.KafkaCapacityTest, i int) string {
+	return fmt.Sprintf("%s-%d", ct.GetName(), i)
+}
+
+// withDefaults returns the supplied spec with the defaults of its unset
+// fields.
+func withDefaults(spec v1alpha1.KafkaCapacityTestSpec) v1alpha1.KafkaCapacityTestSpec {
+	if spec.StartMessagesPerSec == 0 {
+		spec.StartMessagesPerSec = 1000
+	}
+	if spec.MaxMessagesPerSec == 0 {
+		spec.MaxMessagesPerSec = 1000000
+	}
+	if spec.MinAchievedPercent == 0 {
+		spec.MinAchievedPercent = 95
+	}
+	if spec.ResolutionPercent == 0 {
+		spec.ResolutionPercent = 5
+	}
+	if spec.MaxProbes == 0 {
+		spec.MaxProbes = 20
+	}
+	return spec
+}
+
+// probeTemplate returns the supplied template producing at the supplied
+// target for its whole durationMs.
+func probeTemplate(t v1alpha1.KafkaBenchTemplate, target int32) v1alpha1.KafkaBenchTemplate {
+	t.Spec = *t.Spec.DeepCopy()
+	t.Spec.TargetMessagesPerSec = target
+	if t.Spec.DurationMs > 0 {
+		t.Spec.MaxMessages = int64(target) * t.Spec.DurationMs / 1000
+	}
+	return t
+}
+
+// bounds returns the highest sustained target below the lowest target that
+// was not sustained, and that lowest target, or zero for either if there is
+// none.
+func bounds(probes []v1alpha1.CapacityProbe) (lo, hi int32) {
+	for _, p := range probes {
+		if !p.Sustained && (hi == 0 || p.TargetMessagesPerSec < hi) {
+			hi = p.TargetMessagesPerSec
+		}
+	}
+	for _, p := range probes {
+		if p.Sustained && p.TargetMessagesPerSec > lo && (hi == 0 || p.TargetMessagesPerSec < hi) {
+			lo = p.TargetMessagesPerSec
+		}
+	}
+	return lo, hi
+}
+
+// saturation returns the highest sustained target of the supplied probes.
+func saturation(probes []v1alpha1.CapacityProbe) int32 {
+	lo, _ := bounds(probes)
+	return lo
+}
+
+// next returns the target of the probe that follows the supplied finished
+// probes, or true once the search is over. Targets double from the start of
+// the test until one is not sustained, then the search bisects between the
+// highest sustained and the lowest unsustained targets.
+func next(spec v1alpha1.KafkaCapacityTestSpec, probes []v1alpha1.CapacityProbe) (int32, bool) {
+	if len(probes) == 0 {
+		return spec.StartMessagesPerSec, false
+	}
+	if len(probes) >= int(spec.MaxProbes) {
+		return 0, true
+	}
+	lo, hi := bounds(probes)
+	if hi == 0 {
+		if lo >= spec.MaxMessagesPerSec {
+			return 0, true
+		}
+		t := int64(lo) * 2
+		if t > int64(spec.MaxMessagesPerSec) {
+			t = int64(spec.MaxMessagesPerSec)
+		}
+		return int32(t), false
+	}
+	gap := hi - lo
+	if gap <= 1 || int64(gap)*100 <= int64(hi)*int64(spec.ResolutionPercent) {
+		return 0, true
+	}
+	return lo + gap/2, false
+}
+
+// curve returns the latency of the supplied finished probes by achieved
+// throughput.
+func curve(probes []v1alpha1.CapacityProbe) []v1alpha1.LatencyPoint {
+	c := []v1alpha1.LatencyPoint{}
+	for _, p := range probes {
+		if p.Result != "" {
+			c = append(c, v1alpha1.LatencyPoint{MessagesPerSec: p.MessagesPerSec, P99LatencyMs: p.P99LatencyMs})
+		}
+	}
+	sort.SliceStable(c, func(i, j int) bool { return c[i].MessagesPerSec < c[j].MessagesPerSec })
+	return c
+}
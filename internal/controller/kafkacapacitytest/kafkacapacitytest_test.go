@@ -0,0 +1,266 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkacapacitytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/benches/benchestest"
+)
+
+func TestNext(t *testing.T) {
+	spec := withDefaults(v1alpha1.KafkaCapacityTestSpec{MaxMessagesPerSec: 100000, MaxProbes: 5})
+	probe := func(target int32, sustained bool) v1alpha1.CapacityProbe {
+		return v1alpha1.CapacityProbe{TargetMessagesPerSec: target, Result: v1alpha1.BenchSucceeded, Sustained: sustained}
+	}
+	type want struct {
+		target int32
+		done   bool
+	}
+	cases := map[string]struct {
+		reason string
+		probes []v1alpha1.CapacityProbe
+		want   want
+	}{
+		"First": {
+			reason: "The first probe should run at the start of the test.",
+			want:   want{target: 1000},
+		},
+		"Ramp": {
+			reason: "Targets should double while they are sustained.",
+			probes: []v1alpha1.CapacityProbe{probe(1000, true), probe(2000, true)},
+			want:   want{target: 4000},
+		},
+		"RampCapped": {
+			reason: "Targets should not exceed the maximum of the test.",
+			probes: []v1alpha1.CapacityProbe{probe(64000, true)},
+			want:   want{target: 100000},
+		},
+		"Maximum": {
+			reason: "The search should be over once the maximum is sustained.",
+			probes: []v1alpha1.CapacityProbe{probe(100000, true)},
+			want:   want{done: true},
+		},
+		"Bisect": {
+			reason: "Targets should bisect the highest sustained and lowest unsustained targets.",
+			probes: []v1alpha1.CapacityProbe{probe(1000, true), probe(2000, true), probe(4000, false)},
+			want:   want{target: 3000},
+		},
+		"FirstNotSustained": {
+			reason: "Targets should bisect down to zero when the first probe is not sustained.",
+			probes: []v1alpha1.CapacityProbe{probe(1000, false)},
+			want:   want{target: 500},
+		},
+		"Resolution": {
+			reason: "The search should be over once the bounds are within its resolution.",
+			probes: []v1alpha1.CapacityProbe{probe(2000, true), probe(4000, false), probe(3900, true)},
+			want:   want{done: true},
+		},
+		"MaxProbes": {
+			reason: "The search should be over after its maximum number of probes.",
+			probes: []v1alpha1.CapacityProbe{probe(1000, true), probe(2000, true), probe(4000, false), probe(3000, true), probe(3500, false)},
+			want:   want{done: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got want
+			got.target, got.done = next(spec, tc.probes)
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nnext(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	start := time.Date(2022, 6, 1, 2, 0, 0, 0, time.UTC)
+	ct := &v1alpha1.KafkaCapacityTest{
+		ObjectMeta: metav1.ObjectMeta{Name: "capacity", UID: "capacity-uid"},
+		Spec: v1alpha1.KafkaCapacityTestSpec{
+			BenchTemplate: v1alpha1.KafkaBenchTemplate{Spec: v1alpha1.KafkaBenchSpec{KafkaBenchParameters: v1alpha1.KafkaBenchParameters{
+				Class:      v1alpha1.ProduceBenchClass,
+				DurationMs: 10000,
+			}}},
+			P99LatencyMs: 50,
+		},
+	}
+	// newBench returns a probe that ran for 10 seconds.
+	newBench := func(name string, target int32, status string, sent, p99 int64) v1alpha1.KafkaBench {
+		kb := v1alpha1.KafkaBench{ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{LabelKeyCapacityTest: "capacity"},
+		}}
+		kb.Spec.Class = v1alpha1.ProduceBenchClass
+		kb.Spec.TargetMessagesPerSec = target
+		meta.AddOwnerReference(&kb, meta.AsController(meta.TypedReferenceTo(ct, v1alpha1.KafkaCapacityTestGroupVersionKind)))
+		kb.Status.AtProvider.TaskStatus = status
+		kb.Status.AtProvider.StartTime = &metav1.Time{Time: start}
+		kb.Status.AtProvider.CompletionTime = &metav1.Time{Time: start.Add(10 * time.Second)}
+		kb.Status.AtProvider.ProducerStats = v1alpha1.ProducerBenchResultStats{TotalSent: sent, P99LatencyMs: p99}
+		return kb
+	}
+
+	type want struct {
+		created    *v1alpha1.KafkaBenchSpec
+		saturation int32
+		probes     []v1alpha1.CapacityProbe
+		curve      []v1alpha1.LatencyPoint
+		ready      xpv1.ConditionReason
+		events     []event.Reason
+	}
+	cases := map[string]struct {
+		reason  string
+		benches []v1alpha1.KafkaBench
+		want    want
+	}{
+		"First": {
+			reason: "The first probe should produce at the start of the test for its whole durationMs.",
+			want: want{
+				created: &v1alpha1.KafkaBenchSpec{KafkaBenchParameters: v1alpha1.KafkaBenchParameters{
+					Class:                v1alpha1.ProduceBenchClass,
+					DurationMs:           10000,
+					TargetMessagesPerSec: 1000,
+					MaxMessages:          10000,
+				}},
+				probes: []v1alpha1.CapacityProbe{{Bench: "capacity-0", TargetMessagesPerSec: 1000}},
+				curve:  []v1alpha1.LatencyPoint{},
+				ready:  xpv1.ReasonCreating,
+				events: []event.Reason{reasonCreateBench},
+			},
+		},
+		"Running": {
+			reason:  "No probe should be created while one runs.",
+			benches: []v1alpha1.KafkaBench{newBench("capacity-0", 1000, "RUNNING", 0, 0)},
+			want: want{
+				probes: []v1alpha1.CapacityProbe{{Bench: "capacity-0", TargetMessagesPerSec: 1000}},
+				curve:  []v1alpha1.LatencyPoint{},
+				ready:  xpv1.ReasonCreating,
+			},
+		},
+		"LatencyExceeded": {
+			reason: "Probes exceeding the latency objective should not be sustained.",
+			benches: []v1alpha1.KafkaBench{
+				newBench("capacity-0", 1000, "DONE", 10000, 10),
+				newBench("capacity-1", 2000, "DONE", 20000, 80),
+			},
+			want: want{
+				created: &v1alpha1.KafkaBenchSpec{KafkaBenchParameters: v1alpha1.KafkaBenchParameters{
+					Class:                v1alpha1.ProduceBenchClass,
+					DurationMs:           10000,
+					TargetMessagesPerSec: 1500,
+					MaxMessages:          15000,
+				}},
+				saturation: 1000,
+				probes: []v1alpha1.CapacityProbe{
+					{Bench: "capacity-0", TargetMessagesPerSec: 1000, Result: v1alpha1.BenchSucceeded, MessagesPerSec: 1000, P99LatencyMs: 10, Sustained: true},
+					{Bench: "capacity-1", TargetMessagesPerSec: 2000, Result: v1alpha1.BenchSucceeded, MessagesPerSec: 2000, P99LatencyMs: 80},
+					{Bench: "capacity-2", TargetMessagesPerSec: 1500},
+				},
+				curve:  []v1alpha1.LatencyPoint{{MessagesPerSec: 1000, P99LatencyMs: 10}, {MessagesPerSec: 2000, P99LatencyMs: 80}},
+				ready:  xpv1.ReasonCreating,
+				events: []event.Reason{reasonCreateBench},
+			},
+		},
+		"Saturated": {
+			reason: "The test should be available once the bounds are within its resolution.",
+			benches: []v1alpha1.KafkaBench{
+				newBench("capacity-0", 1000, "DONE", 10000, 10),
+				newBench("capacity-1", 2000, "DONE", 15000, 20),
+				newBench("capacity-2", 1500, "DONE", 15000, 20),
+				newBench("capacity-3", 1750, "DONE", 15000, 20),
+				newBench("capacity-4", 1625, "DONE", 15000, 20),
+				newBench("capacity-5", 1562, "DONE", 14000, 20),
+			},
+			want: want{
+				saturation: 1500,
+				probes: []v1alpha1.CapacityProbe{
+					{Bench: "capacity-0", TargetMessagesPerSec: 1000, Result: v1alpha1.BenchSucceeded, MessagesPerSec: 1000, P99LatencyMs: 10, Sustained: true},
+					{Bench: "capacity-1", TargetMessagesPerSec: 2000, Result: v1alpha1.BenchSucceeded, MessagesPerSec: 1500, P99LatencyMs: 20},
+					{Bench: "capacity-2", TargetMessagesPerSec: 1500, Result: v1alpha1.BenchSucceeded, MessagesPerSec: 1500, P99LatencyMs: 20, Sustained: true},
+					{Bench: "capacity-3", TargetMessagesPerSec: 1750, Result: v1alpha1.BenchSucceeded, MessagesPerSec: 1500, P99LatencyMs: 20},
+					{Bench: "capacity-4", TargetMessagesPerSec: 1625, Result: v1alpha1.BenchSucceeded, MessagesPerSec: 1500, P99LatencyMs: 20},
+					{Bench: "capacity-5", TargetMessagesPerSec: 1562, Result: v1alpha1.BenchSucceeded, MessagesPerSec: 1400, P99LatencyMs: 20},
+				},
+				curve: []v1alpha1.LatencyPoint{
+					{MessagesPerSec: 1000, P99LatencyMs: 10},
+					{MessagesPerSec: 1400, P99LatencyMs: 20},
+					{MessagesPerSec: 1500, P99LatencyMs: 20},
+					{MessagesPerSec: 1500, P99LatencyMs: 20},
+					{MessagesPerSec: 1500, P99LatencyMs: 20},
+					{MessagesPerSec: 1500, P99LatencyMs: 20},
+				},
+				ready:  xpv1.ReasonAvailable,
+				events: []event.Reason{reasonSaturated},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got want
+			var updated *v1alpha1.KafkaCapacityTest
+			kube := &test.MockClient{
+				MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					ct.DeepCopyInto(obj.(*v1alpha1.KafkaCapacityTest))
+					return nil
+				}),
+				MockList: test.NewMockListFn(nil, func(obj client.ObjectList) error {
+					obj.(*v1alpha1.KafkaBenchList).Items = tc.benches
+					return nil
+				}),
+				MockCreate: func(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+					got.created = &obj.(*v1alpha1.KafkaBench).Spec
+					return nil
+				},
+				MockStatusUpdate: func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+					updated = obj.(*v1alpha1.KafkaCapacityTest)
+					return nil
+				},
+			}
+			rec := &benchestest.Recorder{}
+			r := &Reconciler{kube: kube, log: logging.NewNopLogger(), record: rec}
+
+			if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: client.ObjectKey{Name: "capacity"}}); err != nil {
+				t.Fatalf("\n%s\nr.Reconcile(...): unexpected error: %v", tc.reason, err)
+			}
+			got.events = rec.Reasons
+			got.ready = updated.Status.GetCondition(xpv1.TypeReady).Reason
+			got.saturation = updated.Status.SaturationMessagesPerSec
+			got.probes = updated.Status.Probes
+			got.curve = updated.Status.Curve
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkafault
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+const (
+	agentServiceName      = "tarasque-agent"
+	agentServiceNamespace = "tarasque"
+	agentPortName         = "agent-port"
+
+	errGetEndpoints = "cannot get Trogdor agent endpoints"
+	errNoAgent      = "no Trogdor agent found for node %q"
+)
+
+// faultNodes returns the names of the nodes a fault has to be sent to.
+func faultNodes(p v1alpha1.KafkaFaultParameters) []string {
+	set := map[string]bool{}
+	for _, partition := range p.Partitions {
+		for _, n := range partition {
+			set[n] = true
+		}
+	}
+	for _, n := range p.NodeNames {
+		set[n] = true
+	}
+	for n := range p.NodeSpecs {
+		set[n] = true
+	}
+	nodes := make([]string, 0, len(set))
+	for n := range set {
+		nodes = append(nodes, n)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// agentsForNodes resolves the addresses of the agents running on the given
+// nodes through the endpoints of the agent service. A node matches an agent
+// when it names either the agent pod, its hostname or the Kubernetes node it
+// is scheduled on.
+func agentsForNodes(ctx context.Context, kube client.Client, nodes []string) ([]string, error) {
+	ep := &corev1.Endpoints{}
+	if err := kube.Get(ctx, types.NamespacedName{Namespace: agentServiceNamespace, Name: agentServiceName}, ep); err != nil {
+		return nil, errors.Wrap(err, errGetEndpoints)
+	}
+
+	seen := map[string]bool{}
+	addrs := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		found := false
+		for _, ss := range ep.Subsets {
+			port := agentPort(ss)
+			for _, a := range ss.Addresses {
+				if !matchesNode(a, n) {
+					continue
+				}
+				found = true
+				addr := net.JoinHostPort(a.IP, port)
+				if !seen[addr] {
+					seen[addr] = true
+					addrs = append(addrs, addr)
+				}
+			}
+		}
+		if !found {
+			return nil, errors.Errorf(errNoAgent, n)
+		}
+	}
+	return addrs, nil
+}
+
+func matchesNode(a corev1.EndpointAddress, node string) bool {
+	if a.Hostname == node || (a.NodeName != nil && *a.NodeName == node) {
+		return true
+	}
+	return a.TargetRef != nil && a.TargetRef.Name == node
+}
+
+func agentPort(ss corev1.EndpointSubset) string {
+	for _, p := range ss.Ports {
+		if p.Name == agentPortName {
+			return strconv.Itoa(int(p.Port))
+		}
+	}
+	if len(ss.Ports) > 0 {
+		return strconv.Itoa(int(ss.Ports[0].Port))
+	}
+	return "8888"
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkafault
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	apisv1alpha1 "github.com/nachomdo/tarasque/apis/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/clients/trogdor"
+)
+
+const (
+	stateDone = "DONE"
+
+	errNotKafkaFault = "managed resource is not a KafkaFault custom resource"
+	errTrackPCUsage  = "cannot track ProviderConfig usage"
+	errNoNodes       = "fault does not target any node"
+	errCreateFault   = "cannot create fault on Trogdor agents"
+	errCollectFault  = "cannot collect fault status from Trogdor agents"
+	errDeleteFault   = "cannot delete fault from Trogdor agents"
+	errFmtAgentError = "agent %s: %s"
+)
+
+// Setup adds a controller that reconciles KafkaFault managed resources.
+func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter) error {
+	name := managed.ControllerName(v1alpha1.KafkaFaultGroupKind)
+
+	o := controller.Options{
+		RateLimiter: ratelimiter.NewDefaultManagedRateLimiter(rl),
+	}
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.KafkaFaultGroupVersionKind),
+		managed.WithExternalConnecter(&connector{
+			kube:  mgr.GetClient(),
+			usage: resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{})}),
+		managed.WithLogger(l.WithValues("controller", name)),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o).
+		For(&v1alpha1.KafkaFault{}).
+		Complete(r)
+}
+
+// faultTaskSpec is the fault specification sent to the agents.
+type faultTaskSpec struct {
+	v1alpha1.KafkaFaultParameters
+	StartMs int64 `json:"startMs"`
+}
+
+// faultTask represents the parameters necessary to create a fault worker in
+// Trogdor agents.
+type faultTask struct {
+	TaskID   string        `json:"taskId"`
+	WorkerID int64         `json:"workerId"`
+	Spec     faultTaskSpec `json:"spec"`
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube  client.Client
+	usage resource.Tracker
+}
+
+// Connect tracks that the KafkaFault is using a ProviderConfig and returns a
+// client for the Trogdor agents.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.KafkaFault); !ok {
+		return nil, errors.New(errNotKafkaFault)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	return &external{
+		client: trogdor.NewClient(resty.New()),
+		locate: func(ctx context.Context, nodes []string) ([]string, error) {
+			return agentsForNodes(ctx, c.kube, nodes)
+		},
+	}, nil
+}
+
+// An external injects faults through the Trogdor agents.
+type external struct {
+	client *trogdor.Client
+	locate func(ctx context.Context, nodes []string) ([]string, error)
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.KafkaFault)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotKafkaFault)
+	}
+
+	return managed.ExternalObservation{
+		ResourceExists:    cr.Status.AtProvider.TaskID != "",
+		ResourceUpToDate:  cr.Status.AtProvider.FaultState == stateDone,
+		ConnectionDetails: managed.ConnectionDetails{},
+	}, nil
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.KafkaFault)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotKafkaFault)
+	}
+	cr.SetConditions(xpv1.Creating())
+
+	nodes := faultNodes(cr.Spec.KafkaFaultParameters)
+	if len(nodes) == 0 {
+		return managed.ExternalCreation{}, errors.New(errNoNodes)
+	}
+	addrs, err := e.locate(ctx, nodes)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	//nolint
+	task := faultTask{
+		TaskID:   uuid.New().String(),
+		WorkerID: rand.Int63(),
+		Spec:     faultTaskSpec{cr.Spec.KafkaFaultParameters, startMs(cr, time.Now())},
+	}
+	g, _ := errgroup.WithContext(ctx)
+	for _, addr := range addrs {
+		endpoint := addr
+		g.Go(func() error {
+			return e.client.CreateWorker(endpoint, task)
+		})
+	}
+	// Record the task even if some agents failed, so that it gets removed
+	// from the agents that accepted it when the KafkaFault is deleted.
+	cr.Status.AtProvider.TaskID = task.TaskID
+	cr.Status.AtProvider.WorkerID = task.WorkerID
+	cr.Status.AtProvider.Agents = addrs
+	cr.Status.AtProvider.FaultState = "CREATED"
+	if err := g.Wait(); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateFault)
+	}
+
+	return managed.ExternalCreation{
+		ConnectionDetails: managed.ConnectionDetails{
+			"taskId": []byte(task.TaskID),
+			"name":   []byte(cr.Name),
+		},
+	}, nil
+}
+
+// startMs returns when the supplied fault starts, in milliseconds since the
+// epoch: its startAt, or the supplied time, delayed by its startDelayMs.
+func startMs(cr *v1alpha1.KafkaFault, now time.Time) int64 {
+	start := now
+	if cr.Spec.StartAt != nil {
+		start = cr.Spec.StartAt.Time
+	}
+	return start.Add(time.Duration(cr.Spec.StartDelayMs) * time.Millisecond).UnixMilli()
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.KafkaFault)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotKafkaFault)
+	}
+
+	workerID := strconv.FormatInt(cr.Status.AtProvider.WorkerID, 10)
+	obs := &cr.Status.AtProvider
+	obs.Error = ""
+	reported := false
+	for _, addr := range obs.Agents {
+		status, err := e.client.Status(addr)
+		if err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errCollectFault)
+		}
+		w, ok := status.Workers[workerID]
+		if !ok {
+			continue
+		}
+		// The fault is reported as soon as one of its agents is not yet
+		// done, so that the KafkaFault stays active until it is cleared
+		// everywhere.
+		if !reported || obs.FaultState == stateDone {
+			obs.FaultState = w.State
+			obs.StartedMs = w.StartedMs
+			obs.DoneMs = w.DoneMs
+			reported = true
+		}
+		// An error on any agent is reported, even when another agent is
+		// not yet done.
+		if w.Error != "" && obs.Error == "" {
+			obs.Error = fmt.Sprintf(errFmtAgentError, addr, w.Error)
+		}
+	}
+
+	if obs.Error != "" {
+		cr.SetConditions(xpv1.Unavailable().WithMessage(obs.Error))
+		return managed.ExternalUpdate{ConnectionDetails: managed.ConnectionDetails{}}, nil
+	}
+	cr.SetConditions(xpv1.Available())
+	return managed.ExternalUpdate{ConnectionDetails: managed.ConnectionDetails{}}, nil
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.KafkaFault)
+	if !ok {
+		return errors.New(errNotKafkaFault)
+	}
+	cr.SetConditions(xpv1.Deleting())
+
+	workerID := strconv.FormatInt(cr.Status.AtProvider.WorkerID, 10)
+	for _, addr := range cr.Status.AtProvider.Agents {
+		if err := e.client.DeleteWorker(addr, workerID); err != nil {
+			return errors.Wrap(err, errDeleteFault)
+		}
+	}
+
+	cr.Status.AtProvider.TaskID = ""
+	return nil
+}
@@ -0,0 +1,295 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkafault
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jarcoal/httpmock"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/clients/trogdor"
+)
+
+func newDegradedNetworkFault() *v1alpha1.KafkaFault {
+	return &v1alpha1.KafkaFault{
+		Spec: v1alpha1.KafkaFaultSpec{
+			KafkaFaultParameters: v1alpha1.KafkaFaultParameters{
+				Class:      "org.apache.kafka.trogdor.fault.DegradedNetworkFaultSpec",
+				DurationMs: 60000,
+				NodeSpecs: map[string]v1alpha1.DegradedNetworkNodeSpec{
+					"node-a": {NetworkDevice: "eth0", LatencyMs: 50},
+					"node-b": {NetworkDevice: "eth0", RateLimitKbit: 1000},
+				},
+			},
+		},
+	}
+}
+
+func TestFaultNodes(t *testing.T) {
+	cases := map[string]struct {
+		params v1alpha1.KafkaFaultParameters
+		want   []string
+	}{
+		"NetworkPartition": {
+			params: v1alpha1.KafkaFaultParameters{Partitions: [][]string{{"node-a", "node-b"}, {"node-c"}}},
+			want:   []string{"node-a", "node-b", "node-c"},
+		},
+		"ProcessStop": {
+			params: v1alpha1.KafkaFaultParameters{NodeNames: []string{"node-b", "node-a"}},
+			want:   []string{"node-a", "node-b"},
+		},
+		"DegradedNetwork": {
+			params: newDegradedNetworkFault().Spec.KafkaFaultParameters,
+			want:   []string{"node-a", "node-b"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, faultNodes(tc.params)); diff != "" {
+				t.Errorf("faultNodes(...): -want, +got:\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestAgentsForNodes(t *testing.T) {
+	node := "worker-1"
+	ep := corev1.Endpoints{
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{
+				{IP: "10.0.0.1", TargetRef: &corev1.ObjectReference{Name: "tarasque-agent-abcde"}},
+				{IP: "10.0.0.2", NodeName: &node},
+			},
+			Ports: []corev1.EndpointPort{{Name: agentPortName, Port: 8888}},
+		}},
+	}
+	kube := &test.MockClient{
+		MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			ep.DeepCopyInto(obj.(*corev1.Endpoints))
+			return nil
+		},
+	}
+
+	cases := map[string]struct {
+		nodes []string
+		want  []string
+		err   error
+	}{
+		"PodName": {
+			nodes: []string{"tarasque-agent-abcde"},
+			want:  []string{"10.0.0.1:8888"},
+		},
+		"NodeName": {
+			nodes: []string{"worker-1", "tarasque-agent-abcde"},
+			want:  []string{"10.0.0.2:8888", "10.0.0.1:8888"},
+		},
+		"UnknownNode": {
+			nodes: []string{"worker-2"},
+			err:   errors.Errorf(errNoAgent, "worker-2"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := agentsForNodes(context.TODO(), kube, tc.nodes)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("agentsForNodes(...): -want error, +got error:\n%s\n", diff)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("agentsForNodes(...): -want, +got:\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	agents := []string{"10.0.0.1:8888", "10.0.0.2:8888"}
+	httpClient := resty.New()
+	httpmock.ActivateNonDefault(httpClient.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	var mu sync.Mutex
+	created := map[string]faultTask{}
+	deleted := map[string]string{}
+	for _, a := range agents {
+		addr := a
+		httpmock.RegisterResponder("POST", "http://"+addr+"/agent/worker/create",
+			func(req *http.Request) (*http.Response, error) {
+				body, _ := io.ReadAll(req.Body)
+				ft := faultTask{}
+				if err := json.Unmarshal(body, &ft); err != nil {
+					return nil, err
+				}
+				mu.Lock()
+				created[addr] = ft
+				mu.Unlock()
+				return httpmock.NewStringResponse(200, "{}"), nil
+			})
+		httpmock.RegisterResponder("GET", "http://"+addr+"/agent/status",
+			func(req *http.Request) (*http.Response, error) {
+				workers := map[string]trogdor.AgentStatusWorkers{}
+				for _, ft := range created {
+					workers[strconv.FormatInt(ft.WorkerID, 10)] = trogdor.AgentStatusWorkers{State: "RUNNING", TaskID: ft.TaskID, StartedMs: 1000}
+				}
+				return httpmock.NewJsonResponse(200, trogdor.AgentStatusResponse{Workers: workers})
+			})
+		httpmock.RegisterResponder("DELETE", "http://"+addr+"/agent/worker",
+			func(req *http.Request) (*http.Response, error) {
+				deleted[addr] = req.URL.Query().Get("workerId")
+				return httpmock.NewStringResponse(200, "{}"), nil
+			})
+	}
+
+	e := &external{
+		client: trogdor.NewClient(httpClient),
+		locate: func(_ context.Context, nodes []string) ([]string, error) {
+			if diff := cmp.Diff([]string{"node-a", "node-b"}, nodes); diff != "" {
+				t.Errorf("locate(...): -want nodes, +got nodes:\n%s\n", diff)
+			}
+			return agents, nil
+		},
+	}
+	cr := newDegradedNetworkFault()
+
+	if _, err := e.Create(context.TODO(), cr); err != nil {
+		t.Fatalf("e.Create(...): unexpected error: %v", err)
+	}
+	for _, a := range agents {
+		ft, ok := created[a]
+		if !ok {
+			t.Errorf("e.Create(...): fault was not sent to agent %s", a)
+			continue
+		}
+		if diff := cmp.Diff(cr.Spec.KafkaFaultParameters, ft.Spec.KafkaFaultParameters); diff != "" {
+			t.Errorf("e.Create(...): -want spec, +got spec:\n%s\n", diff)
+		}
+		if ft.Spec.StartMs == 0 {
+			t.Errorf("e.Create(...): fault sent to agent %s without startMs", a)
+		}
+	}
+
+	if _, err := e.Update(context.TODO(), cr); err != nil {
+		t.Fatalf("e.Update(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("RUNNING", cr.Status.AtProvider.FaultState); diff != "" {
+		t.Errorf("e.Update(...): -want state, +got state:\n%s\n", diff)
+	}
+	o, err := e.Observe(context.TODO(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): unexpected error: %v", err)
+	}
+	if !o.ResourceExists || o.ResourceUpToDate {
+		t.Errorf("e.Observe(...): a running fault should exist and not be up to date, got %+v", o)
+	}
+
+	if err := e.Delete(context.TODO(), cr); err != nil {
+		t.Fatalf("e.Delete(...): unexpected error: %v", err)
+	}
+	for _, a := range agents {
+		if diff := cmp.Diff(strconv.FormatInt(created[a].WorkerID, 10), deleted[a]); diff != "" {
+			t.Errorf("e.Delete(...): -want workerId, +got workerId on agent %s:\n%s\n", a, diff)
+		}
+	}
+}
+
+func TestUpdateAgentError(t *testing.T) {
+	agents := []string{"10.0.0.1:8888", "10.0.0.2:8888"}
+	httpClient := resty.New()
+	httpmock.ActivateNonDefault(httpClient.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	cr := newDegradedNetworkFault()
+	cr.Status.AtProvider.TaskID = "fault"
+	cr.Status.AtProvider.WorkerID = 42
+	cr.Status.AtProvider.Agents = agents
+	// The first agent still runs the fault while the second one failed it.
+	workers := map[string]trogdor.AgentStatusWorkers{
+		agents[0]: {State: "RUNNING", StartedMs: 1000},
+		agents[1]: {State: "DONE", StartedMs: 1000, DoneMs: 1200, Error: "tc: command not found"},
+	}
+	for _, a := range agents {
+		w := workers[a]
+		httpmock.RegisterResponder("GET", "http://"+a+"/agent/status",
+			func(req *http.Request) (*http.Response, error) {
+				return httpmock.NewJsonResponse(200, trogdor.AgentStatusResponse{Workers: map[string]trogdor.AgentStatusWorkers{"42": w}})
+			})
+	}
+
+	e := &external{client: trogdor.NewClient(httpClient)}
+	if _, err := e.Update(context.TODO(), cr); err != nil {
+		t.Fatalf("e.Update(...): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("RUNNING", cr.Status.AtProvider.FaultState); diff != "" {
+		t.Errorf("e.Update(...): -want state, +got state:\n%s\n", diff)
+	}
+	want := xpv1.Unavailable().WithMessage("agent 10.0.0.2:8888: tc: command not found")
+	if diff := cmp.Diff(want, cr.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+		t.Errorf("e.Update(...): a fault that failed on an agent should be unavailable: -want, +got:\n%s\n", diff)
+	}
+}
+
+func TestStartMs(t *testing.T) {
+	now := time.Date(2022, 7, 1, 2, 0, 0, 0, time.UTC)
+	at := metav1.NewTime(now.Add(time.Hour))
+	cases := map[string]struct {
+		reason string
+		spec   v1alpha1.KafkaFaultSpec
+		want   time.Time
+	}{
+		"Now": {
+			reason: "Faults without a start should start once created.",
+			want:   now,
+		},
+		"Delayed": {
+			reason: "Faults with a start delay should start that long after they were created.",
+			spec:   v1alpha1.KafkaFaultSpec{StartDelayMs: 30000},
+			want:   now.Add(30 * time.Second),
+		},
+		"StartAt": {
+			reason: "Faults should start at their startAt, delayed by their start delay.",
+			spec:   v1alpha1.KafkaFaultSpec{StartAt: &at, StartDelayMs: 30000},
+			want:   now.Add(time.Hour + 30*time.Second),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := startMs(&v1alpha1.KafkaFault{Spec: tc.spec}, now)
+			if diff := cmp.Diff(tc.want.UnixMilli(), got); diff != "" {
+				t.Errorf("\n%s\nstartMs(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkatarget
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+const (
+	errNotTargetReferencer = "managed resource cannot reference a KafkaTarget"
+	errApplyUsage          = "cannot apply KafkaTargetUsage"
+	errDeleteUsage         = "cannot delete KafkaTargetUsage"
+)
+
+// A TargetReferencer may reference a KafkaTarget.
+type TargetReferencer interface {
+	GetTargetReference() *xpv1.Reference
+}
+
+// A UsageTracker tracks usages of a KafkaTarget by creating or updating the
+// appropriate KafkaTargetUsage.
+type UsageTracker struct {
+	c resource.Applicator
+	d client.Writer
+}
+
+// NewUsageTracker creates a UsageTracker.
+func NewUsageTracker(c client.Client) *UsageTracker {
+	return &UsageTracker{c: resource.NewAPIUpdatingApplicator(c), d: c}
+}
+
+// Track that the supplied managed resource is using the KafkaTarget it
+// references. Like a ProviderConfigUsage, the KafkaTargetUsage is named after
+// the managed resource and controlled by it, so it goes away with the managed
+// resource. The usage of a managed resource that no longer references a
+// KafkaTarget is deleted.
+func (u *UsageTracker) Track(ctx context.Context, mg resource.Managed) error {
+	tr, ok := mg.(TargetReferencer)
+	if !ok {
+		return errors.New(errNotTargetReferencer)
+	}
+
+	tu := &v1alpha1.KafkaTargetUsage{}
+	tu.SetName(string(mg.GetUID()))
+	ref := tr.GetTargetReference()
+	if ref == nil {
+		return errors.Wrap(resource.IgnoreNotFound(u.d.Delete(ctx, tu)), errDeleteUsage)
+	}
+
+	gvk := mg.GetObjectKind().GroupVersionKind()
+	tu.SetLabels(map[string]string{xpv1.LabelKeyProviderName: ref.Name})
+	tu.SetOwnerReferences([]metav1.OwnerReference{meta.AsController(meta.TypedReferenceTo(mg, gvk))})
+	tu.SetProviderConfigReference(xpv1.Reference{Name: ref.Name})
+	tu.SetResourceReference(xpv1.TypedReference{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+		Name:       mg.GetName(),
+	})
+
+	err := u.c.Apply(ctx, tu,
+		resource.MustBeControllableBy(mg.GetUID()),
+		resource.AllowUpdateIf(func(current, _ runtime.Object) bool {
+			return current.(*v1alpha1.KafkaTargetUsage).GetProviderConfigReference() != tu.GetProviderConfigReference()
+		}),
+	)
+	return errors.Wrap(resource.Ignore(resource.IsNotAllowed, err), errApplyUsage)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkatarget
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+func TestTrack(t *testing.T) {
+	controller := true
+	type want struct {
+		created *v1alpha1.KafkaTargetUsage
+		deleted string
+	}
+	cases := map[string]struct {
+		reason string
+		ref    *xpv1.Reference
+		want   want
+	}{
+		"Target": {
+			reason: "A usage labelled with the KafkaTarget should be created for benches referencing one.",
+			ref:    &xpv1.Reference{Name: "shared"},
+			want: want{created: &v1alpha1.KafkaTargetUsage{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "bench-uid",
+					Labels: map[string]string{xpv1.LabelKeyProviderName: "shared"},
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: v1alpha1.SchemeGroupVersion.String(),
+						Kind:       v1alpha1.KafkaBenchKind,
+						Name:       "bench",
+						UID:        "bench-uid",
+						Controller: &controller,
+					}},
+				},
+				ProviderConfigUsage: xpv1.ProviderConfigUsage{
+					ProviderConfigReference: xpv1.Reference{Name: "shared"},
+					ResourceReference:       xpv1.TypedReference{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: v1alpha1.KafkaBenchKind, Name: "bench"},
+				},
+			}},
+		},
+		"NoTarget": {
+			reason: "The usage of benches that no longer reference a KafkaTarget should be deleted.",
+			want:   want{deleted: "bench-uid"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got want
+			kube := &test.MockClient{
+				MockGet: func(_ context.Context, key client.ObjectKey, _ client.Object) error {
+					return kerrors.NewNotFound(schema.GroupResource{}, key.Name)
+				},
+				MockCreate: func(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+					got.created = obj.(*v1alpha1.KafkaTargetUsage)
+					return nil
+				},
+				MockDelete: func(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+					got.deleted = obj.GetName()
+					return kerrors.NewNotFound(schema.GroupResource{}, obj.GetName())
+				},
+			}
+			cr := &v1alpha1.KafkaBench{
+				TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: v1alpha1.KafkaBenchKind},
+				ObjectMeta: metav1.ObjectMeta{Name: "bench", UID: "bench-uid"},
+				Spec:       v1alpha1.KafkaBenchSpec{TargetRef: tc.ref},
+			}
+			if err := NewUsageTracker(kube).Track(context.Background(), cr); err != nil {
+				t.Fatalf("\n%s\nTrack(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nTrack(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
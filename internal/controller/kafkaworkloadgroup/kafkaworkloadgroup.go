@@ -0,0 +1,259 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kafkaworkloadgroup starts the KafkaBenches of the members of
+// KafkaWorkloadGroups together.
+package kafkaworkloadgroup
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/benches"
+)
+
+const (
+	// LabelKeyGroup is set to the name of the KafkaWorkloadGroup that created
+	// a bench.
+	LabelKeyGroup = "tarasque.crossplane.io/workload-group"
+
+	timeout = 2 * time.Minute
+
+	errGetGroup     = "cannot get KafkaWorkloadGroup"
+	errCreateBench  = "cannot create bench"
+	errStartBench   = "cannot set the start of bench"
+	errUpdateStatus = "cannot update KafkaWorkloadGroup status"
+	errNoMembers    = "a group needs at least one member"
+	errFmtDuplicate = "member %q is declared more than once"
+)
+
+// Event reasons.
+const (
+	reasonCreateBench  event.Reason = "CreateBench"
+	reasonStart        event.Reason = "Start"
+	reasonBadMembers   event.Reason = "InvalidMembers"
+	reasonCannotCreate event.Reason = "CannotCreateBench"
+	reasonCannotStart  event.Reason = "CannotStartBench"
+)
+
+// Setup adds a controller that starts the KafkaBenches of KafkaWorkloadGroups.
+func Setup(mgr ctrl.Manager, l logging.Logger, rl workqueue.RateLimiter) error {
+	name := "group/" + strings.ToLower(v1alpha1.KafkaWorkloadGroupGroupKind)
+
+	r := &Reconciler{
+		kube:   mgr.GetClient(),
+		log:    l.WithValues("controller", name),
+		record: event.NewAPIRecorder(mgr.GetEventRecorderFor(name)),
+		now:    time.Now,
+	}
+
+	return benches.SetupOwner(mgr, name, &v1alpha1.KafkaWorkloadGroup{}, r, rl)
+}
+
+// A Reconciler creates the KafkaBenches of the members of a
+// KafkaWorkloadGroup, which are validated and placed in the agent pool, then
+// sets the same start on every one of them and combines their results.
+type Reconciler struct {
+	kube   client.Client
+	log    logging.Logger
+	record event.Recorder
+	now    func() time.Time
+}
+
+// Reconcile a KafkaWorkloadGroup.
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	log := r.log.WithValues("request", req)
+	log.Debug("Reconciling")
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	g := &v1alpha1.KafkaWorkloadGroup{}
+	if ok, err := benches.Get(ctx, r.kube, req.NamespacedName, g); !ok {
+		return reconcile.Result{}, errors.Wrap(err, errGetGroup)
+	}
+	if err := validate(g.Spec.Members); err != nil {
+		log.Debug("Invalid members", "error", err)
+		r.record.Event(g, event.Warning(reasonBadMembers, err))
+		// There's no need to requeue until the members are fixed.
+		return reconcile.Result{}, errors.Wrap(benches.Failed(ctx, r.kube, g, err), errUpdateStatus)
+	}
+
+	labels := map[string]string{LabelKeyGroup: g.GetName()}
+	owned, err := benches.Owned(ctx, r.kube, g, labels)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(benches.Failed(ctx, r.kube, g, err), errUpdateStatus)
+	}
+	byName := make(map[string]*v1alpha1.KafkaBench, len(owned))
+	for i := range owned {
+		byName[owned[i].GetName()] = &owned[i]
+	}
+
+	members := make([]v1alpha1.GroupMemberStatus, len(g.Spec.Members))
+	combined := &v1alpha1.GroupResults{}
+	waiting := 0
+	for i, m := range g.Spec.Members {
+		members[i] = v1alpha1.GroupMemberStatus{Name: m.Name, Bench: benchName(g, m), Phase: v1alpha1.StepPending}
+		kb, ok := byName[members[i].Bench]
+		if !ok {
+			kb = member(g, m, labels)
+			if err := r.kube.Create(ctx, kb); resource.Ignore(kerrors.IsAlreadyExists, err) != nil {
+				err = errors.Wrap(err, errCreateBench)
+				r.record.Event(g, event.Warning(reasonCannotCreate, err))
+				return reconcile.Result{}, errors.Wrap(benches.Failed(ctx, r.kube, g, err), errUpdateStatus)
+			}
+			r.record.Event(g, event.Normal(reasonCreateBench, fmt.Sprintf("Created bench %s of member %s", kb.GetName(), m.Name)))
+			continue
+		}
+		switch res, done := benches.Result(kb); {
+		case benches.Refused(kb):
+			members[i].Phase = v1alpha1.MemberRefused
+		case done:
+			members[i].Phase = res
+			members[i].MessagesPerSec, members[i].P99LatencyMs = benches.Throughput(kb)
+			add(combined, kb, members[i])
+		case benches.Waiting(kb):
+			members[i].Phase = v1alpha1.MemberWaiting
+			waiting++
+		case kb.Status.AtProvider.TaskID != "":
+			members[i].Phase = v1alpha1.StepRunning
+		}
+	}
+
+	if g.Status.StartTime == nil && waiting == len(members) {
+		// Every member was validated and placed in the agent pool.
+		g.Status.StartTime = &metav1.Time{Time: r.now().Add(time.Duration(g.Spec.StartDelayMs) * time.Millisecond)}
+		r.record.Event(g, event.Normal(reasonStart, fmt.Sprintf("Starting %d benches at %s", len(members), g.Status.StartTime.UTC().Format(time.RFC3339))))
+	}
+	if g.Status.StartTime != nil {
+		start := strconv.FormatInt(g.Status.StartTime.UnixMilli(), 10)
+		for i := range owned {
+			kb := &owned[i]
+			if _, ok := kb.GetAnnotations()[v1alpha1.AnnotationKeyStartMs]; ok {
+				continue
+			}
+			// Only the start is sent, so that it does not conflict with
+			// the provider updating the bench.
+			p := client.MergeFrom(kb.DeepCopy())
+			meta.AddAnnotations(kb, map[string]string{v1alpha1.AnnotationKeyStartMs: start})
+			if err := r.kube.Patch(ctx, kb, p); err != nil {
+				err = errors.Wrap(err, errStartBench)
+				r.record.Event(g, event.Warning(reasonCannotStart, err))
+				return reconcile.Result{}, errors.Wrap(benches.Failed(ctx, r.kube, g, err), errUpdateStatus)
+			}
+		}
+	}
+
+	g.Status.Members = members
+	g.Status.Result, g.Status.Combined = result(members), nil
+	g.Status.SetConditions(xpv1.ReconcileSuccess(), xpv1.Creating())
+	if g.Status.Result != "" {
+		g.Status.Combined = combined
+		g.Status.SetConditions(xpv1.Available())
+	}
+	return reconcile.Result{}, errors.Wrap(r.kube.Status().Update(ctx, g), errUpdateStatus)
+}
+
+func benchName(g *v1alpha1.KafkaWorkloadGroup, m v1alpha1.GroupMember) string {
+	return fmt.Sprintf("%s-%s", g.GetName(), m.Name)
+}
+
+// member returns the bench of the supplied member, which waits for its group
+// to agree on the start of its members.
+func member(g *v1alpha1.KafkaWorkloadGroup, m v1alpha1.GroupMember, labels map[string]string) *v1alpha1.KafkaBench {
+	kb := benches.New(g, v1alpha1.KafkaWorkloadGroupGroupVersionKind, benchName(g, m), m.BenchTemplate, labels)
+	meta.AddAnnotations(kb, map[string]string{
+		v1alpha1.AnnotationKeyGroup:     g.GetName(),
+		v1alpha1.AnnotationKeyGroupSize: strconv.Itoa(len(g.Spec.Members)),
+	})
+	// A start set in the template would not be agreed on by the group.
+	delete(kb.Annotations, v1alpha1.AnnotationKeyStartMs)
+	return kb
+}
+
+// validate returns an error if there are no members, or if a member is
+// declared more than once.
+func validate(members []v1alpha1.GroupMember) error {
+	if len(members) == 0 {
+		return errors.New(errNoMembers)
+	}
+	seen := make(map[string]bool, len(members))
+	for _, m := range members {
+		if seen[m.Name] {
+			return errors.Errorf(errFmtDuplicate, m.Name)
+		}
+		seen[m.Name] = true
+	}
+	return nil
+}
+
+// result returns whether the supplied members Succeeded or Failed, once
+// every one of them finished. Members that were refused fail the group at
+// once, as the others will never start.
+func result(members []v1alpha1.GroupMemberStatus) string {
+	res := v1alpha1.BenchSucceeded
+	for _, m := range members {
+		switch m.Phase {
+		case v1alpha1.BenchSucceeded:
+		case v1alpha1.BenchFailed:
+			if res != "" {
+				res = v1alpha1.BenchFailed
+			}
+		case v1alpha1.MemberRefused:
+			return v1alpha1.BenchFailed
+		default:
+			res = ""
+		}
+	}
+	return res
+}
+
+// add adds the results of the supplied finished member to the supplied
+// combined results. The throughput of produce and consume benches adds up
+// separately, and the worst latency is kept.
+func add(r *v1alpha1.GroupResults, kb *v1alpha1.KafkaBench, m v1alpha1.GroupMemberStatus) {
+	class := kb.Spec.Class
+	if kb.Status.AtProvider.EffectiveSpec != nil {
+		class = kb.Status.AtProvider.EffectiveSpec.Class
+	}
+	switch class {
+	case v1alpha1.ProduceBenchClass:
+		r.ProducedMessagesPerSec += m.MessagesPerSec
+	case v1alpha1.ConsumeBenchClass:
+		r.ConsumedMessagesPerSec += m.MessagesPerSec
+	}
+	if m.P99LatencyMs > r.P99LatencyMs {
+		r.P99LatencyMs = m.P99LatencyMs
+	}
+}
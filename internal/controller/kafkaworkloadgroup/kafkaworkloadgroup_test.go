@@ -0,0 +1,239 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafkaworkloadgroup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/benches/benchestest"
+)
+
+func TestReconcile(t *testing.T) {
+	now := time.Date(2022, 6, 1, 2, 0, 0, 0, time.UTC)
+	start := &metav1.Time{Time: now.Add(10 * time.Second)}
+	template := func(class string) v1alpha1.KafkaBenchTemplate {
+		return v1alpha1.KafkaBenchTemplate{Spec: v1alpha1.KafkaBenchSpec{KafkaBenchParameters: v1alpha1.KafkaBenchParameters{Class: class}}}
+	}
+	group := &v1alpha1.KafkaWorkloadGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "mixed", UID: "mixed-uid"},
+		Spec: v1alpha1.KafkaWorkloadGroupSpec{
+			Members: []v1alpha1.GroupMember{
+				{Name: "producer", BenchTemplate: template(v1alpha1.ProduceBenchClass)},
+				{Name: "consumer", BenchTemplate: template(v1alpha1.ConsumeBenchClass)},
+			},
+			StartDelayMs: 10000,
+		},
+	}
+	started := group.DeepCopy()
+	started.Status.StartTime = start
+	newBench := func(name, class, status string, annotations map[string]string) v1alpha1.KafkaBench {
+		kb := v1alpha1.KafkaBench{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      map[string]string{LabelKeyGroup: "mixed"},
+			Annotations: annotations,
+		}}
+		kb.Spec.Class = class
+		meta.AddOwnerReference(&kb, meta.AsController(meta.TypedReferenceTo(group, v1alpha1.KafkaWorkloadGroupGroupVersionKind)))
+		kb.Status.AtProvider.TaskStatus = status
+		if status != "WAITING" && status != "QUEUED" && status != "REFUSED" {
+			kb.Status.AtProvider.TaskID = "task"
+		}
+		if status == "DONE" {
+			kb.Status.AtProvider.StartTime = start
+			kb.Status.AtProvider.CompletionTime = &metav1.Time{Time: start.Add(10 * time.Second)}
+			kb.Status.AtProvider.ProducerStats = v1alpha1.ProducerBenchResultStats{TotalSent: 100000, P99LatencyMs: 42}
+			kb.Status.AtProvider.ConsumerStats = map[string]v1alpha1.ConsumerBenchResultStats{"consumer": {TotalMessagesReceived: 90000, P99LatencyMs: 50}}
+		}
+		return kb
+	}
+	agreed := map[string]string{v1alpha1.AnnotationKeyGroup: "mixed", v1alpha1.AnnotationKeyStartMs: "1654048810000"}
+
+	type want struct {
+		created []string
+		started map[string]string
+		status  v1alpha1.KafkaWorkloadGroupStatus
+		ready   xpv1.ConditionReason
+		events  []event.Reason
+	}
+	cases := map[string]struct {
+		reason  string
+		group   *v1alpha1.KafkaWorkloadGroup
+		benches []v1alpha1.KafkaBench
+		want    want
+	}{
+		"Create": {
+			reason: "The benches of every member should be created at once.",
+			group:  group,
+			want: want{
+				created: []string{"mixed-producer", "mixed-consumer"},
+				status: v1alpha1.KafkaWorkloadGroupStatus{Members: []v1alpha1.GroupMemberStatus{
+					{Name: "producer", Bench: "mixed-producer", Phase: v1alpha1.StepPending},
+					{Name: "consumer", Bench: "mixed-consumer", Phase: v1alpha1.StepPending},
+				}},
+				ready:  xpv1.ReasonCreating,
+				events: []event.Reason{reasonCreateBench, reasonCreateBench},
+			},
+		},
+		"Placing": {
+			reason: "Members should not start until every one of them was placed in the agent pool.",
+			group:  group,
+			benches: []v1alpha1.KafkaBench{
+				newBench("mixed-producer", v1alpha1.ProduceBenchClass, "WAITING", nil),
+				newBench("mixed-consumer", v1alpha1.ConsumeBenchClass, "QUEUED", nil),
+			},
+			want: want{
+				status: v1alpha1.KafkaWorkloadGroupStatus{Members: []v1alpha1.GroupMemberStatus{
+					{Name: "producer", Bench: "mixed-producer", Phase: v1alpha1.MemberWaiting},
+					{Name: "consumer", Bench: "mixed-consumer", Phase: v1alpha1.StepPending},
+				}},
+				ready: xpv1.ReasonCreating,
+			},
+		},
+		"Start": {
+			reason: "Every member should be given the same start once all of them wait for the group.",
+			group:  group,
+			benches: []v1alpha1.KafkaBench{
+				newBench("mixed-producer", v1alpha1.ProduceBenchClass, "WAITING", nil),
+				newBench("mixed-consumer", v1alpha1.ConsumeBenchClass, "WAITING", nil),
+			},
+			want: want{
+				started: map[string]string{"mixed-producer": "1654048810000", "mixed-consumer": "1654048810000"},
+				status: v1alpha1.KafkaWorkloadGroupStatus{StartTime: start, Members: []v1alpha1.GroupMemberStatus{
+					{Name: "producer", Bench: "mixed-producer", Phase: v1alpha1.MemberWaiting},
+					{Name: "consumer", Bench: "mixed-consumer", Phase: v1alpha1.MemberWaiting},
+				}},
+				ready:  xpv1.ReasonCreating,
+				events: []event.Reason{reasonStart},
+			},
+		},
+		"Empty": {
+			reason: "Groups without members should never start.",
+			group:  &v1alpha1.KafkaWorkloadGroup{ObjectMeta: metav1.ObjectMeta{Name: "mixed", UID: "mixed-uid"}},
+			want: want{
+				events: []event.Reason{reasonBadMembers},
+			},
+		},
+		"Refused": {
+			reason: "Groups should fail as soon as one of their members is refused, as the others will never start.",
+			group:  group,
+			benches: []v1alpha1.KafkaBench{
+				newBench("mixed-producer", v1alpha1.ProduceBenchClass, "WAITING", nil),
+				newBench("mixed-consumer", v1alpha1.ConsumeBenchClass, "REFUSED", nil),
+			},
+			want: want{
+				status: v1alpha1.KafkaWorkloadGroupStatus{
+					Result: v1alpha1.BenchFailed,
+					Members: []v1alpha1.GroupMemberStatus{
+						{Name: "producer", Bench: "mixed-producer", Phase: v1alpha1.MemberWaiting},
+						{Name: "consumer", Bench: "mixed-consumer", Phase: v1alpha1.MemberRefused},
+					},
+					Combined: &v1alpha1.GroupResults{},
+				},
+				ready: xpv1.ReasonAvailable,
+			},
+		},
+		"Completed": {
+			reason: "The results of the members should be combined once every one of them finished.",
+			group:  started,
+			benches: []v1alpha1.KafkaBench{
+				newBench("mixed-producer", v1alpha1.ProduceBenchClass, "DONE", agreed),
+				newBench("mixed-consumer", v1alpha1.ConsumeBenchClass, "DONE", agreed),
+			},
+			want: want{
+				status: v1alpha1.KafkaWorkloadGroupStatus{
+					StartTime: start,
+					Result:    v1alpha1.BenchSucceeded,
+					Members: []v1alpha1.GroupMemberStatus{
+						{Name: "producer", Bench: "mixed-producer", Phase: v1alpha1.BenchSucceeded, MessagesPerSec: 10000, P99LatencyMs: 42},
+						{Name: "consumer", Bench: "mixed-consumer", Phase: v1alpha1.BenchSucceeded, MessagesPerSec: 9000, P99LatencyMs: 50},
+					},
+					Combined: &v1alpha1.GroupResults{ProducedMessagesPerSec: 10000, ConsumedMessagesPerSec: 9000, P99LatencyMs: 50},
+				},
+				ready: xpv1.ReasonAvailable,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got want
+			var updated *v1alpha1.KafkaWorkloadGroup
+			kube := &test.MockClient{
+				MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					tc.group.DeepCopyInto(obj.(*v1alpha1.KafkaWorkloadGroup))
+					return nil
+				}),
+				MockList: test.NewMockListFn(nil, func(obj client.ObjectList) error {
+					obj.(*v1alpha1.KafkaBenchList).Items = tc.benches
+					return nil
+				}),
+				MockCreate: func(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+					if diff := cmp.Diff("mixed", obj.GetAnnotations()[v1alpha1.AnnotationKeyGroup]); diff != "" {
+						t.Errorf("kube.Create(...): members should wait for their group: -want, +got:\n%s\n", diff)
+					}
+					if diff := cmp.Diff("2", obj.GetAnnotations()[v1alpha1.AnnotationKeyGroupSize]); diff != "" {
+						t.Errorf("kube.Create(...): members should know the size of their group: -want, +got:\n%s\n", diff)
+					}
+					got.created = append(got.created, obj.GetName())
+					return nil
+				},
+				MockPatch: func(_ context.Context, obj client.Object, p client.Patch, _ ...client.PatchOption) error {
+					if diff := cmp.Diff(types.MergePatchType, p.Type()); diff != "" {
+						t.Errorf("kube.Patch(...): only the start of a bench should be sent: -want, +got:\n%s\n", diff)
+					}
+					if got.started == nil {
+						got.started = map[string]string{}
+					}
+					got.started[obj.GetName()] = obj.GetAnnotations()[v1alpha1.AnnotationKeyStartMs]
+					return nil
+				},
+				MockStatusUpdate: func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+					updated = obj.(*v1alpha1.KafkaWorkloadGroup)
+					return nil
+				},
+			}
+			rec := &benchestest.Recorder{}
+			r := &Reconciler{kube: kube, log: logging.NewNopLogger(), record: rec, now: func() time.Time { return now }}
+
+			if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: client.ObjectKey{Name: "mixed"}}); err != nil {
+				t.Fatalf("\n%s\nr.Reconcile(...): unexpected error: %v", tc.reason, err)
+			}
+			got.events = rec.Reasons
+			got.ready = updated.Status.GetCondition(xpv1.TypeReady).Reason
+			got.status = updated.Status
+			got.status.ConditionedStatus = xpv1.ConditionedStatus{}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -24,6 +24,13 @@ import (
 
 	"github.com/nachomdo/tarasque/internal/controller/config"
 	"github.com/nachomdo/tarasque/internal/controller/kafkabench"
+	"github.com/nachomdo/tarasque/internal/controller/kafkabenchscenario"
+	"github.com/nachomdo/tarasque/internal/controller/kafkabenchschedule"
+	"github.com/nachomdo/tarasque/internal/controller/kafkabenchsweep"
+	"github.com/nachomdo/tarasque/internal/controller/kafkacapacitytest"
+	"github.com/nachomdo/tarasque/internal/controller/kafkafault"
+	"github.com/nachomdo/tarasque/internal/controller/kafkatarget"
+	"github.com/nachomdo/tarasque/internal/controller/kafkaworkloadgroup"
 )
 
 // Setup creates all Template controllers with the supplied logger and adds them to
@@ -32,6 +39,14 @@ func Setup(mgr ctrl.Manager, l logging.Logger, wl workqueue.RateLimiter) error {
 	for _, setup := range []func(ctrl.Manager, logging.Logger, workqueue.RateLimiter) error{
 		config.Setup,
 		kafkabench.Setup,
+		kafkabench.SetupNamespaced,
+		kafkabenchscenario.Setup,
+		kafkabenchschedule.Setup,
+		kafkabenchsweep.Setup,
+		kafkacapacitytest.Setup,
+		kafkafault.Setup,
+		kafkatarget.Setup,
+		kafkaworkloadgroup.Setup,
 	} {
 		if err := setup(mgr, l, wl); err != nil {
 			return err
@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package defaults applies the bench defaults of a ProviderConfig or a
+// KafkaTarget.
+package defaults
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/validation"
+)
+
+const errApplyDefaults = "cannot apply bench defaults"
+
+// mergedFields are merged key by key rather than replaced by the bench.
+var mergedFields = map[string]bool{
+	"producerConf":     true,
+	"consumerConf":     true,
+	"commonClientConf": true,
+	"adminClientConf":  true,
+}
+
+// ApplyKafkaBench returns the parameters of a bench once the supplied
+// defaults have been applied. Fields set by the bench always win, and client
+// configurations are merged key by key. Defaults the task class does not
+// understand are skipped, so a single set of defaults can serve producer and
+// consumer benches alike. Benches defined through a rawSpec are left as is.
+func ApplyKafkaBench(d *v1alpha1.KafkaBenchParameters, p v1alpha1.KafkaBenchParameters) (v1alpha1.KafkaBenchParameters, error) {
+	if d == nil || p.RawSpec != nil {
+		return p, nil
+	}
+
+	dm, err := toMap(d)
+	if err != nil {
+		return p, errors.Wrap(err, errApplyDefaults)
+	}
+	pm, err := toMap(&p)
+	if err != nil {
+		return p, errors.Wrap(err, errApplyDefaults)
+	}
+
+	class := p.Class
+	if class == "" {
+		class = d.Class
+	}
+	supported := validation.SupportedFields(class)
+	for k, dv := range dm {
+		if supported != nil && !supported[k] {
+			continue
+		}
+		pv, ok := pm[k]
+		if !ok {
+			pm[k] = dv
+			continue
+		}
+		if mergedFields[k] {
+			pm[k] = mergeMaps(dv, pv)
+		}
+	}
+
+	b, err := json.Marshal(pm)
+	if err != nil {
+		return p, errors.Wrap(err, errApplyDefaults)
+	}
+	out := v1alpha1.KafkaBenchParameters{}
+	return out, errors.Wrap(json.Unmarshal(b, &out), errApplyDefaults)
+}
+
+// KafkaTarget returns the connection settings of a KafkaTarget as bench
+// defaults, to be applied before those of the ProviderConfig.
+func KafkaTarget(t *v1alpha1.KafkaTarget) *v1alpha1.KafkaBenchParameters {
+	if t == nil {
+		return nil
+	}
+	return &v1alpha1.KafkaBenchParameters{
+		BootstrapServers: t.Spec.BootstrapServers,
+		CommonClientConf: t.Spec.CommonClientConf,
+		AdminClientConf:  t.Spec.AdminClientConf,
+	}
+}
+
+func toMap(p *v1alpha1.KafkaBenchParameters) (map[string]interface{}, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	return m, json.Unmarshal(b, &m)
+}
+
+// mergeMaps returns the keys of base overridden by those of override.
+func mergeMaps(base, override interface{}) interface{} {
+	bm, ok := base.(map[string]interface{})
+	if !ok {
+		return override
+	}
+	om, ok := override.(map[string]interface{})
+	if !ok {
+		return override
+	}
+	out := make(map[string]interface{}, len(bm)+len(om))
+	for k, v := range bm {
+		out[k] = v
+	}
+	for k, v := range om {
+		out[k] = v
+	}
+	return out
+}
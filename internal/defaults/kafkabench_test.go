@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+func TestApplyKafkaBench(t *testing.T) {
+	defaults := &v1alpha1.KafkaBenchParameters{
+		DurationMs:       60000,
+		ProducerNode:     "node0",
+		ConsumerNode:     "node0",
+		BootstrapServers: "kafka:9092",
+		CommonClientConf: map[string]string{"security.protocol": "SASL_SSL", "sasl.mechanism": "PLAIN"},
+	}
+
+	cases := map[string]struct {
+		reason   string
+		defaults *v1alpha1.KafkaBenchParameters
+		params   v1alpha1.KafkaBenchParameters
+		want     v1alpha1.KafkaBenchParameters
+	}{
+		"NoDefaults": {
+			reason: "Benches should be left as is without defaults.",
+			params: v1alpha1.KafkaBenchParameters{Class: v1alpha1.ProduceBenchClass},
+			want:   v1alpha1.KafkaBenchParameters{Class: v1alpha1.ProduceBenchClass},
+		},
+		"BenchWins": {
+			reason:   "Fields set by the bench should win, while client configurations are merged key by key.",
+			defaults: defaults,
+			params: v1alpha1.KafkaBenchParameters{
+				Class:            v1alpha1.ProduceBenchClass,
+				DurationMs:       1000,
+				CommonClientConf: map[string]string{"sasl.mechanism": "SCRAM-SHA-512"},
+			},
+			want: v1alpha1.KafkaBenchParameters{
+				Class:            v1alpha1.ProduceBenchClass,
+				DurationMs:       1000,
+				ProducerNode:     "node0",
+				BootstrapServers: "kafka:9092",
+				CommonClientConf: map[string]string{"security.protocol": "SASL_SSL", "sasl.mechanism": "SCRAM-SHA-512"},
+			},
+		},
+		"SkipUnsupported": {
+			reason:   "Defaults the class does not understand should be skipped.",
+			defaults: defaults,
+			params:   v1alpha1.KafkaBenchParameters{Class: v1alpha1.ConsumeBenchClass},
+			want: v1alpha1.KafkaBenchParameters{
+				Class:            v1alpha1.ConsumeBenchClass,
+				DurationMs:       60000,
+				ConsumerNode:     "node0",
+				BootstrapServers: "kafka:9092",
+				CommonClientConf: map[string]string{"security.protocol": "SASL_SSL", "sasl.mechanism": "PLAIN"},
+			},
+		},
+		"RawSpec": {
+			reason:   "Benches defined through a rawSpec should be left as is.",
+			defaults: defaults,
+			params:   v1alpha1.KafkaBenchParameters{RawSpec: &runtime.RawExtension{Raw: []byte(`{"class":"org.example.Spec"}`)}},
+			want:     v1alpha1.KafkaBenchParameters{RawSpec: &runtime.RawExtension{Raw: []byte(`{"class":"org.example.Spec"}`)}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ApplyKafkaBench(tc.defaults, tc.params)
+			if err != nil {
+				t.Fatalf("\n%s\nApplyKafkaBench(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nApplyKafkaBench(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package loadprofile splits the load profile of a produce bench into
+// segments that Trogdor runs at a constant rate.
+package loadprofile
+
+import (
+	"bufio"
+	"bytes"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+// DefaultSegmentDurationMs is the duration of the segments of ramps and sine
+// waves that do not set one.
+const DefaultSegmentDurationMs = 60000
+
+const (
+	errFmtLine  = "line %d: expected an offset in milliseconds and a rate in messages per second"
+	errNoPoints = "no points to replay"
+)
+
+// A Segment of a bench runs at a constant rate.
+type Segment struct {
+	OffsetMs       int64
+	DurationMs     int64
+	MessagesPerSec int32
+}
+
+// ParseCSV returns the points of the supplied CSV lines of an offset in
+// milliseconds and a rate in messages per second. Blank lines, lines starting
+// with # and a header line are ignored.
+func ParseCSV(data []byte) ([]v1alpha1.LoadPoint, error) {
+	points := []v1alpha1.LoadPoint{}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		f := strings.Split(line, ",")
+		if len(f) != 2 {
+			return nil, errors.Errorf(errFmtLine, n)
+		}
+		offset, errOffset := strconv.ParseInt(strings.TrimSpace(f[0]), 10, 64)
+		rate, errRate := strconv.ParseFloat(strings.TrimSpace(f[1]), 64)
+		switch {
+		case (errOffset != nil || errRate != nil) && len(points) == 0 && n == 1:
+			// A header line.
+			continue
+		case errOffset != nil || errRate != nil || offset < 0 || rate < 0:
+			return nil, errors.Errorf(errFmtLine, n)
+		}
+		points = append(points, v1alpha1.LoadPoint{OffsetMs: offset, MessagesPerSec: int32(math.Round(rate))})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(points) == 0 {
+		return nil, errors.New(errNoPoints)
+	}
+	return points, nil
+}
+
+// Segments returns the segments the supplied profile runs as over the supplied
+// duration. The supplied points are those of a replay profile. Rates are at
+// least one message per second.
+func Segments(p *v1alpha1.LoadProfile, durationMs int64, replay []v1alpha1.LoadPoint) []Segment {
+	segDuration := p.SegmentDurationMs
+	if segDuration <= 0 {
+		segDuration = DefaultSegmentDurationMs
+	}
+	switch {
+	case p.Ramp != nil:
+		n := (durationMs + segDuration - 1) / segDuration
+		return split(durationMs, segDuration, func(i int64, _ int64) float64 {
+			if n <= 1 {
+				return float64(p.Ramp.FromMessagesPerSec)
+			}
+			return float64(p.Ramp.FromMessagesPerSec) + float64(p.Ramp.ToMessagesPerSec-p.Ramp.FromMessagesPerSec)*float64(i)/float64(n-1)
+		})
+	case p.Sine != nil:
+		return split(durationMs, segDuration, func(_ int64, midMs int64) float64 {
+			return float64(p.Sine.MeanMessagesPerSec) + float64(p.Sine.AmplitudeMessagesPerSec)*math.Sin(2*math.Pi*float64(midMs)/float64(p.Sine.PeriodMs))
+		})
+	case len(p.Steps) > 0:
+		return points(p.Steps, durationMs)
+	default:
+		return points(replay, durationMs)
+	}
+}
+
+// split returns the segments of the supplied duration, at the rate of the
+// supplied function of their index and middle.
+func split(durationMs, segDuration int64, rate func(i int64, midMs int64) float64) []Segment {
+	segs := []Segment{}
+	for i, offset := int64(0), int64(0); offset < durationMs; i, offset = i+1, offset+segDuration {
+		d := segDuration
+		if offset+d > durationMs {
+			d = durationMs - offset
+		}
+		segs = append(segs, Segment{OffsetMs: offset, DurationMs: d, MessagesPerSec: atLeastOne(rate(i, offset+d/2))})
+	}
+	return segs
+}
+
+// points returns the segments of the supplied points, each lasting until the
+// next one. Offsets are relative to the first point.
+func points(p []v1alpha1.LoadPoint, durationMs int64) []Segment {
+	sorted := append([]v1alpha1.LoadPoint{}, p...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].OffsetMs < sorted[j].OffsetMs })
+	segs := []Segment{}
+	for i, pt := range sorted {
+		offset := pt.OffsetMs - sorted[0].OffsetMs
+		end := durationMs
+		if i+1 < len(sorted) && sorted[i+1].OffsetMs-sorted[0].OffsetMs < end {
+			end = sorted[i+1].OffsetMs - sorted[0].OffsetMs
+		}
+		if end <= offset {
+			continue
+		}
+		segs = append(segs, Segment{OffsetMs: offset, DurationMs: end - offset, MessagesPerSec: atLeastOne(float64(pt.MessagesPerSec))})
+	}
+	return segs
+}
+
+func atLeastOne(rate float64) int32 {
+	if rate < 1 {
+		return 1
+	}
+	return int32(math.Round(rate))
+}
+
+// Parameters returns the supplied parameters of a bench with those of the
+// supplied segment.
+func Parameters(params v1alpha1.KafkaBenchParameters, s Segment) v1alpha1.KafkaBenchParameters {
+	params.TargetMessagesPerSec = s.MessagesPerSec
+	params.DurationMs = s.DurationMs
+	params.MaxMessages = messages(s)
+	return params
+}
+
+// Envelope returns the supplied parameters of a bench with the peak rate and
+// the total number of messages of the supplied segments, so that the bench
+// can be checked against its guardrails.
+func Envelope(params v1alpha1.KafkaBenchParameters, segs []Segment) v1alpha1.KafkaBenchParameters {
+	params.TargetMessagesPerSec, params.MaxMessages = 0, 0
+	for _, s := range segs {
+		if s.MessagesPerSec > params.TargetMessagesPerSec {
+			params.TargetMessagesPerSec = s.MessagesPerSec
+		}
+		params.MaxMessages += messages(s)
+	}
+	return params
+}
+
+// messages returns how many messages the supplied segment produces.
+func messages(s Segment) int64 {
+	m := int64(s.MessagesPerSec) * s.DurationMs / 1000
+	if m < 1 {
+		return 1
+	}
+	return m
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadprofile
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+func TestParseCSV(t *testing.T) {
+	type want struct {
+		points []v1alpha1.LoadPoint
+		err    bool
+	}
+	cases := map[string]struct {
+		reason string
+		data   string
+		want   want
+	}{
+		"Points": {
+			reason: "Lines of an offset and a rate should be parsed, ignoring the header, comments and blank lines.",
+			data:   "offsetMs,messagesPerSec\n# morning peak\n0, 1000\n\n60000,2500.4\n",
+			want: want{points: []v1alpha1.LoadPoint{
+				{OffsetMs: 0, MessagesPerSec: 1000},
+				{OffsetMs: 60000, MessagesPerSec: 2500},
+			}},
+		},
+		"Malformed": {
+			reason: "A line that is not an offset and a rate should be reported.",
+			data:   "0,1000\n60000\n",
+			want:   want{err: true},
+		},
+		"Negative": {
+			reason: "Negative rates should be reported.",
+			data:   "0,-1\n",
+			want:   want{err: true},
+		},
+		"Empty": {
+			reason: "A replay without points should be reported.",
+			data:   "offsetMs,messagesPerSec\n",
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			points, err := ParseCSV([]byte(tc.data))
+			if diff := cmp.Diff(tc.want.err, err != nil); diff != "" {
+				t.Fatalf("\n%s\nParseCSV(...): -want error, +got error: %v\n", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.points, points); diff != "" {
+				t.Errorf("\n%s\nParseCSV(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSegments(t *testing.T) {
+	cases := map[string]struct {
+		reason     string
+		profile    *v1alpha1.LoadProfile
+		durationMs int64
+		replay     []v1alpha1.LoadPoint
+		want       []Segment
+	}{
+		"Ramp": {
+			reason:     "A ramp should increase linearly from its first to its last segment.",
+			profile:    &v1alpha1.LoadProfile{Ramp: &v1alpha1.RampProfile{FromMessagesPerSec: 1000, ToMessagesPerSec: 3000}},
+			durationMs: 180000,
+			want: []Segment{
+				{OffsetMs: 0, DurationMs: 60000, MessagesPerSec: 1000},
+				{OffsetMs: 60000, DurationMs: 60000, MessagesPerSec: 2000},
+				{OffsetMs: 120000, DurationMs: 60000, MessagesPerSec: 3000},
+			},
+		},
+		"RampShortLastSegment": {
+			reason:     "The last segment should end with the bench.",
+			profile:    &v1alpha1.LoadProfile{Ramp: &v1alpha1.RampProfile{FromMessagesPerSec: 1000, ToMessagesPerSec: 3000}, SegmentDurationMs: 60000},
+			durationMs: 90000,
+			want: []Segment{
+				{OffsetMs: 0, DurationMs: 60000, MessagesPerSec: 1000},
+				{OffsetMs: 60000, DurationMs: 30000, MessagesPerSec: 3000},
+			},
+		},
+		"Sine": {
+			reason:     "A sine wave should be sampled in the middle of each segment.",
+			profile:    &v1alpha1.LoadProfile{Sine: &v1alpha1.SineProfile{MeanMessagesPerSec: 1000, AmplitudeMessagesPerSec: 500, PeriodMs: 240000}},
+			durationMs: 240000,
+			want: []Segment{
+				{OffsetMs: 0, DurationMs: 60000, MessagesPerSec: 1354},
+				{OffsetMs: 60000, DurationMs: 60000, MessagesPerSec: 1354},
+				{OffsetMs: 120000, DurationMs: 60000, MessagesPerSec: 646},
+				{OffsetMs: 180000, DurationMs: 60000, MessagesPerSec: 646},
+			},
+		},
+		"Steps": {
+			reason:     "Each step should last until the next one or the end of the bench.",
+			profile:    &v1alpha1.LoadProfile{Steps: []v1alpha1.LoadPoint{{OffsetMs: 0, MessagesPerSec: 100}, {OffsetMs: 30000, MessagesPerSec: 500}, {OffsetMs: 90000, MessagesPerSec: 900}}},
+			durationMs: 60000,
+			want: []Segment{
+				{OffsetMs: 0, DurationMs: 30000, MessagesPerSec: 100},
+				{OffsetMs: 30000, DurationMs: 30000, MessagesPerSec: 500},
+			},
+		},
+		"Replay": {
+			reason:     "Replayed offsets should be relative to the first point, and rates at least one message per second.",
+			profile:    &v1alpha1.LoadProfile{Replay: &v1alpha1.ReplayProfile{}},
+			durationMs: 5000,
+			replay:     []v1alpha1.LoadPoint{{OffsetMs: 3000, MessagesPerSec: 0}, {OffsetMs: 1000, MessagesPerSec: 10}},
+			want: []Segment{
+				{OffsetMs: 0, DurationMs: 2000, MessagesPerSec: 10},
+				{OffsetMs: 2000, DurationMs: 3000, MessagesPerSec: 1},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Segments(tc.profile, tc.durationMs, tc.replay)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nSegments(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	params := v1alpha1.KafkaBenchParameters{Class: v1alpha1.ProduceBenchClass, DurationMs: 60000}
+	segs := []Segment{
+		{OffsetMs: 0, DurationMs: 30000, MessagesPerSec: 100},
+		{OffsetMs: 30000, DurationMs: 30000, MessagesPerSec: 500},
+	}
+	want := v1alpha1.KafkaBenchParameters{Class: v1alpha1.ProduceBenchClass, DurationMs: 60000, TargetMessagesPerSec: 500, MaxMessages: 18000}
+	if diff := cmp.Diff(want, Envelope(params, segs)); diff != "" {
+		t.Errorf("Envelope(...): -want, +got:\n%s\n", diff)
+	}
+}
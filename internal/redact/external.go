@@ -0,0 +1,56 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redact
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// An externalClient masks the secrets of the errors returned by the
+// ExternalClient it wraps. The managed reconciler writes these errors to the
+// conditions of the resource and emits them as events.
+type externalClient struct {
+	managed.ExternalClient
+}
+
+// ExternalClient wraps the supplied client so that the errors it returns are
+// safe to write to a status or emit as an event.
+func ExternalClient(c managed.ExternalClient) managed.ExternalClient {
+	return &externalClient{ExternalClient: c}
+}
+
+func (e *externalClient) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	o, err := e.ExternalClient.Observe(ctx, mg)
+	return o, Error(err)
+}
+
+func (e *externalClient) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	c, err := e.ExternalClient.Create(ctx, mg)
+	return c, Error(err)
+}
+
+func (e *externalClient) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	u, err := e.ExternalClient.Update(ctx, mg)
+	return u, Error(err)
+}
+
+func (e *externalClient) Delete(ctx context.Context, mg resource.Managed) error {
+	return Error(e.ExternalClient.Delete(ctx, mg))
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package redact masks sensitive Kafka client configurations before they are
+// logged, emitted as events or written to a status.
+package redact
+
+import (
+	"encoding/json"
+	"regexp"
+	"sync"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+// Mask replaces sensitive values.
+const Mask = "**REDACTED**"
+
+const errPattern = "cannot compile redaction pattern"
+
+// sensitiveKeys are the Kafka client configurations that are always masked.
+var sensitiveKeys = map[string]bool{
+	"sasl.jaas.config":                     true,
+	"sasl.oauthbearer.client.secret":       true,
+	"ssl.key.password":                     true,
+	"ssl.keystore.password":                true,
+	"ssl.keystore.key":                     true,
+	"ssl.truststore.password":              true,
+	"basic.auth.user.info":                 true,
+	"schema.registry.basic.auth.user.info": true,
+	"password":                             true,
+}
+
+var (
+	// defaultPattern matches the keys of further sensitive configurations,
+	// ending with a secret-bearing segment such as .password or .secret, but
+	// not those merely mentioning one, such as
+	// sasl.oauthbearer.token.endpoint.url.
+	defaultPattern = regexp.MustCompile(`(?i)(^|[._-])(password|secret|credentials|token)$`)
+
+	// inlineSecrets matches secrets embedded in free text, such as the
+	// password of a JAAS configuration or a key=value pair.
+	inlineSecrets = regexp.MustCompile(`(?i)((?:password|secret|sasl\.jaas\.config|ssl\.keystore\.key)\s*[=:]\s*)("[^"]*"|'[^']*'|[^\s,;}]+)`)
+
+	mu      sync.RWMutex
+	pattern *regexp.Regexp
+)
+
+// SetPattern masks the configurations whose key matches the supplied regular
+// expression, on top of the built-in ones. An empty pattern only keeps the
+// built-in ones.
+func SetPattern(p string) error {
+	var re *regexp.Regexp
+	if p != "" {
+		var err error
+		if re, err = regexp.Compile(p); err != nil {
+			return errors.Wrap(err, errPattern)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	pattern = re
+	return nil
+}
+
+// IsSensitive returns true if the value of the supplied configuration key
+// must be masked.
+func IsSensitive(key string) bool {
+	if sensitiveKeys[key] || defaultPattern.MatchString(key) {
+		return true
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	return pattern != nil && pattern.MatchString(key)
+}
+
+// Conf returns a copy of the supplied client configuration with its
+// sensitive values masked.
+func Conf(conf map[string]string) map[string]string {
+	if conf == nil {
+		return nil
+	}
+	out := make(map[string]string, len(conf))
+	for k, v := range conf {
+		if IsSensitive(k) {
+			v = Mask
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// String masks the secrets embedded in free text.
+func String(s string) string {
+	return inlineSecrets.ReplaceAllString(s, "${1}"+Mask)
+}
+
+// Error returns an error whose message has its secrets masked, or nil.
+func Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := String(err.Error())
+	if msg == err.Error() {
+		return err
+	}
+	return errors.New(msg)
+}
+
+// Value masks the sensitive keys of the supplied JSON-like value, as well as
+// the secrets embedded in its strings.
+func Value(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			if _, ok := e.(string); ok && IsSensitive(k) {
+				out[k] = Mask
+				continue
+			}
+			out[k] = Value(e)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, e := range t {
+			out[i] = Value(e)
+		}
+		return out
+	case string:
+		return String(t)
+	default:
+		return v
+	}
+}
+
+// RawExtension returns a copy of the supplied raw JSON with its secrets
+// masked.
+func RawExtension(r *runtime.RawExtension) *runtime.RawExtension {
+	if r == nil || r.Raw == nil {
+		return r
+	}
+	var v interface{}
+	if err := json.Unmarshal(r.Raw, &v); err != nil {
+		return &runtime.RawExtension{Raw: []byte(String(string(r.Raw)))}
+	}
+	raw, err := json.Marshal(Value(v))
+	if err != nil {
+		return &runtime.RawExtension{Raw: []byte(Mask)}
+	}
+	return &runtime.RawExtension{Raw: raw}
+}
+
+// KafkaBenchParameters masks the client configurations and raw specs of the
+// supplied parameters in place.
+func KafkaBenchParameters(p *v1alpha1.KafkaBenchParameters) {
+	if p == nil {
+		return
+	}
+	p.ProducerConf = Conf(p.ProducerConf)
+	p.ConsumerConf = Conf(p.ConsumerConf)
+	p.CommonClientConf = Conf(p.CommonClientConf)
+	p.AdminClientConf = Conf(p.AdminClientConf)
+	p.Workload = RawExtension(p.Workload)
+	p.RawSpec = RawExtension(p.RawSpec)
+}
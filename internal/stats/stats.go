@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package stats sums up the results of the runs of a bench.
+package stats
+
+import (
+	"math"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+// tCritical are the two-tailed critical values of Student's t-distribution at
+// 95% confidence, indexed by degrees of freedom.
+var tCritical = []float64{
+	0, 12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262,
+	2.228, 2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093,
+	2.086, 2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045,
+	2.042,
+}
+
+// zCritical is the critical value of the normal distribution at 95%
+// confidence, which Student's t-distribution approaches past 30 degrees of
+// freedom.
+const zCritical = 1.960
+
+// Summarize returns the mean, sample standard deviation, extremes and 95%
+// confidence interval of the mean of the supplied values. The interval of a
+// single value is that value.
+func Summarize(values []float64) v1alpha1.Statistic {
+	if len(values) == 0 {
+		return v1alpha1.Statistic{}
+	}
+	s := v1alpha1.Statistic{Min: values[0], Max: values[0]}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+		s.Min = math.Min(s.Min, v)
+		s.Max = math.Max(s.Max, v)
+	}
+	n := len(values)
+	s.Mean = sum / float64(n)
+	s.ConfidenceLow, s.ConfidenceHigh = s.Mean, s.Mean
+	if n < 2 {
+		return s
+	}
+	squares := 0.0
+	for _, v := range values {
+		squares += (v - s.Mean) * (v - s.Mean)
+	}
+	s.StdDev = math.Sqrt(squares / float64(n-1))
+	margin := critical(n-1) * s.StdDev / math.Sqrt(float64(n))
+	s.ConfidenceLow, s.ConfidenceHigh = s.Mean-margin, s.Mean+margin
+	return s
+}
+
+// critical returns the critical value of Student's t-distribution at 95%
+// confidence for the supplied degrees of freedom.
+func critical(df int) float64 {
+	if df < len(tCritical) {
+		return tCritical[df]
+	}
+	return zCritical
+}
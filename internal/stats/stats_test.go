@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+func TestSummarize(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		values []float64
+		want   v1alpha1.Statistic
+	}{
+		"None": {
+			reason: "No values should be summed up as zeros.",
+			want:   v1alpha1.Statistic{},
+		},
+		"One": {
+			reason: "A single value should have no deviation, and an interval of that value.",
+			values: []float64{1000},
+			want:   v1alpha1.Statistic{Mean: 1000, Min: 1000, Max: 1000, ConfidenceLow: 1000, ConfidenceHigh: 1000},
+		},
+		"Several": {
+			reason: "The interval should be the mean plus or minus the t critical value times the standard error.",
+			values: []float64{9000, 10000, 11000},
+			// The standard error is 1000/sqrt(3) and t is 4.303 at two
+			// degrees of freedom.
+			want: v1alpha1.Statistic{Mean: 10000, StdDev: 1000, Min: 9000, Max: 11000, ConfidenceLow: 7515.662, ConfidenceHigh: 12484.338},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Summarize(tc.values)
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateApprox(0, 0.001)); diff != "" {
+				t.Errorf("\n%s\nSummarize(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
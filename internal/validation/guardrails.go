@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	apisv1alpha1 "github.com/nachomdo/tarasque/apis/v1alpha1"
+)
+
+// Trogdor generates 4 byte keys and 512 byte values unless a rawSpec sets
+// other generators.
+const (
+	defaultKeySize   = 4
+	defaultValueSize = 512
+)
+
+// producerClasses are the task classes that produce messages.
+var producerClasses = []string{v1alpha1.ProduceBenchClass, v1alpha1.RoundTripWorkloadClass}
+
+// load holds the fields of a bench its guardrails bound. It decodes both the
+// dedicated fields of a bench and its rawSpec.
+type load struct {
+	Class                string `json:"class"`
+	DurationMs           int64  `json:"durationMs"`
+	BootstrapServers     string `json:"bootstrapServers"`
+	TargetMessagesPerSec int64  `json:"targetMessagesPerSec"`
+	MaxMessages          int64  `json:"maxMessages"`
+	KeyGenerator         struct {
+		Size int64 `json:"size"`
+	} `json:"keyGenerator"`
+	ValueGenerator struct {
+		Size int64 `json:"size"`
+	} `json:"valueGenerator"`
+}
+
+// Guardrails returns the guardrails of the supplied ProviderConfig and
+// KafkaTarget, either of which may be nil, keyed by the object that sets them.
+func Guardrails(pc *apisv1alpha1.ProviderConfig, t *v1alpha1.KafkaTarget) map[string]*v1alpha1.Guardrails {
+	g := map[string]*v1alpha1.Guardrails{}
+	if pc != nil && pc.Spec.Guardrails != nil {
+		g[fmt.Sprintf("ProviderConfig %q", pc.GetName())] = pc.Spec.Guardrails
+	}
+	if t != nil && t.Spec.Guardrails != nil {
+		g[fmt.Sprintf("KafkaTarget %q", t.GetName())] = t.Spec.Guardrails
+	}
+	return g
+}
+
+// GuardrailsApproved returns whether the supplied bench was approved to
+// exceed its guardrails.
+func GuardrailsApproved(o metav1.Object) bool {
+	return o.GetAnnotations()[v1alpha1.AnnotationKeyGuardrailsApproved] == "true"
+}
+
+// ValidateGuardrails returns every guardrail the supplied parameters exceed.
+func ValidateGuardrails(guardrails map[string]*v1alpha1.Guardrails, spec *v1alpha1.KafkaBenchParameters, path *field.Path) field.ErrorList {
+	l := load{
+		Class:                spec.Class,
+		DurationMs:           spec.DurationMs,
+		BootstrapServers:     spec.BootstrapServers,
+		TargetMessagesPerSec: int64(spec.TargetMessagesPerSec),
+		MaxMessages:          spec.MaxMessages,
+	}
+	if spec.RawSpec != nil {
+		l = load{}
+		if err := json.Unmarshal(spec.RawSpec.Raw, &l); err != nil {
+			return field.ErrorList{field.Invalid(path.Child("rawSpec"), "", "must be a JSON object")}
+		}
+		path = path.Child("rawSpec")
+	}
+
+	allErrs := field.ErrorList{}
+	for _, owner := range sortedKeys(guardrails) {
+		allErrs = append(allErrs, validateGuardrails(owner, guardrails[owner], l, path)...)
+	}
+	return allErrs
+}
+
+func validateGuardrails(owner string, g *v1alpha1.Guardrails, l load, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if g.MaxMessagesPerSec > 0 && l.TargetMessagesPerSec > int64(g.MaxMessagesPerSec) {
+		allErrs = append(allErrs, field.Forbidden(path.Child("targetMessagesPerSec"), fmt.Sprintf("%d exceeds the maximum of %d set by %s", l.TargetMessagesPerSec, g.MaxMessagesPerSec, owner)))
+	}
+	if g.MaxDurationMs > 0 && l.DurationMs > g.MaxDurationMs {
+		allErrs = append(allErrs, field.Forbidden(path.Child("durationMs"), fmt.Sprintf("%d exceeds the maximum of %d set by %s", l.DurationMs, g.MaxDurationMs, owner)))
+	}
+	if g.MaxBytes != nil && contains(producerClasses, l.Class) {
+		if b := producedBytes(l); b > g.MaxBytes.AsApproximateFloat64() {
+			estimate := resource.NewQuantity(int64(b), resource.BinarySI)
+			allErrs = append(allErrs, field.Forbidden(path.Child("maxMessages"), fmt.Sprintf("producing about %s exceeds the maximum of %s set by %s", estimate, g.MaxBytes, owner)))
+		}
+	}
+	if len(g.AllowedBootstrapServers) > 0 {
+		for _, s := range strings.Split(l.BootstrapServers, ",") {
+			if s = strings.TrimSpace(s); s != "" && !matchAny(g.AllowedBootstrapServers, s) {
+				allErrs = append(allErrs, field.Forbidden(path.Child("bootstrapServers"), fmt.Sprintf("%s is not allowed by %s", s, owner)))
+			}
+		}
+	}
+	return allErrs
+}
+
+// producedBytes estimates the bytes a bench produces. Trogdor stops producing
+// after maxMessages, or once durationMs is over at targetMessagesPerSec.
+func producedBytes(l load) float64 {
+	messages := float64(l.MaxMessages)
+	if l.TargetMessagesPerSec > 0 && l.DurationMs > 0 {
+		if r := float64(l.TargetMessagesPerSec) * float64(l.DurationMs) / 1000; messages == 0 || r < messages {
+			messages = r
+		}
+	}
+	keySize, valueSize := l.KeyGenerator.Size, l.ValueGenerator.Size
+	if keySize == 0 {
+		keySize = defaultKeySize
+	}
+	if valueSize == 0 {
+		valueSize = defaultValueSize
+	}
+	return messages * float64(keySize+valueSize)
+}
+
+// matchAny returns whether server matches any of the supplied shell patterns.
+func matchAny(patterns []string, server string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, server); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+func TestValidateGuardrails(t *testing.T) {
+	mib := resource.MustParse("1Mi")
+	cases := map[string]struct {
+		reason     string
+		guardrails map[string]*v1alpha1.Guardrails
+		spec       func() v1alpha1.KafkaBenchParameters
+		want       []string
+	}{
+		"NoGuardrails": {
+			reason: "Benches without guardrails should be valid.",
+			spec:   produceBench,
+			want:   []string{},
+		},
+		"WithinGuardrails": {
+			reason: "Benches within their guardrails should be valid.",
+			guardrails: map[string]*v1alpha1.Guardrails{"pc": {
+				MaxMessagesPerSec:       1000,
+				MaxDurationMs:           60000,
+				MaxBytes:                &mib,
+				AllowedBootstrapServers: []string{"kafka:*"},
+			}},
+			spec: func() v1alpha1.KafkaBenchParameters {
+				s := produceBench()
+				s.MaxMessages = 2000
+				return s
+			},
+			want: []string{},
+		},
+		"ExceedingGuardrails": {
+			reason: "Each guardrail a bench exceeds should be reported.",
+			guardrails: map[string]*v1alpha1.Guardrails{"pc": {
+				MaxMessagesPerSec:       100,
+				MaxDurationMs:           1000,
+				MaxBytes:                &mib,
+				AllowedBootstrapServers: []string{"staging-*:9092"},
+			}},
+			spec: produceBench,
+			want: []string{
+				"FieldValueForbidden: spec.targetMessagesPerSec",
+				"FieldValueForbidden: spec.durationMs",
+				"FieldValueForbidden: spec.maxMessages",
+				"FieldValueForbidden: spec.bootstrapServers",
+			},
+		},
+		"BytesByRate": {
+			reason: "Bytes should be estimated from the rate and duration when they stop the bench before maxMessages.",
+			guardrails: map[string]*v1alpha1.Guardrails{"pc": {
+				MaxBytes: &mib,
+			}},
+			spec: func() v1alpha1.KafkaBenchParameters {
+				s := produceBench()
+				s.MaxMessages = 100000000
+				s.DurationMs = 1000
+				return s
+			},
+			want: []string{},
+		},
+		"ConsumerBytes": {
+			reason: "Bytes should only bound benches that produce.",
+			guardrails: map[string]*v1alpha1.Guardrails{"pc": {
+				MaxBytes: &mib,
+			}},
+			spec: func() v1alpha1.KafkaBenchParameters {
+				return v1alpha1.KafkaBenchParameters{Class: v1alpha1.ConsumeBenchClass, MaxMessages: 100000000}
+			},
+			want: []string{},
+		},
+		"EveryBootstrapServer": {
+			reason: "Every bootstrap server should be allowed.",
+			guardrails: map[string]*v1alpha1.Guardrails{"pc": {
+				AllowedBootstrapServers: []string{"kafka-0:9092"},
+			}},
+			spec: func() v1alpha1.KafkaBenchParameters {
+				s := produceBench()
+				s.BootstrapServers = "kafka-0:9092, kafka-1:9092"
+				return s
+			},
+			want: []string{"FieldValueForbidden: spec.bootstrapServers"},
+		},
+		"EveryOwner": {
+			reason: "Benches should stay within the guardrails of both their ProviderConfig and KafkaTarget.",
+			guardrails: map[string]*v1alpha1.Guardrails{
+				"pc":     {MaxDurationMs: 600000},
+				"target": {MaxDurationMs: 1000},
+			},
+			spec: produceBench,
+			want: []string{"FieldValueForbidden: spec.durationMs"},
+		},
+		"RawSpec": {
+			reason: "The guardrails should bound the raw spec of a bench, including its generated value sizes.",
+			guardrails: map[string]*v1alpha1.Guardrails{"pc": {
+				MaxMessagesPerSec: 1000,
+				MaxBytes:          &mib,
+			}},
+			spec: func() v1alpha1.KafkaBenchParameters {
+				return v1alpha1.KafkaBenchParameters{RawSpec: &runtime.RawExtension{Raw: []byte(`{
+					"class": "org.apache.kafka.trogdor.workload.ProduceBenchSpec",
+					"targetMessagesPerSec": 5000,
+					"maxMessages": 1000,
+					"valueGenerator": {"type": "constant", "size": 4096}
+				}`)}}
+			},
+			want: []string{
+				"FieldValueForbidden: spec.rawSpec.targetMessagesPerSec",
+				"FieldValueForbidden: spec.rawSpec.maxMessages",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			spec := tc.spec()
+			got := errs(ValidateGuardrails(tc.guardrails, &spec, field.NewPath("spec")))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nValidateGuardrails(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
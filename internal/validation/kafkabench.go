@@ -0,0 +1,562 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validation contains the rules KafkaBench specs must satisfy before
+// they are dispatched to the Trogdor agents.
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/assertion"
+	"github.com/nachomdo/tarasque/internal/clients/kafka"
+	"github.com/nachomdo/tarasque/internal/redact"
+)
+
+const (
+	maxTopicNameLength = 249
+	stateDone          = "DONE"
+	stateTimedOut      = "TIMED_OUT"
+	stateFailed        = "FAILED"
+)
+
+var (
+	topicNameRegexp  = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+	topicRangeRegexp = regexp.MustCompile(`^([^\[\]]*)\[(\d+)-(\d+)\]([^\[\]]*)$`)
+	partitionRegexp  = regexp.MustCompile(`^(\d+)$|^\[(\d+)-(\d+)\]$`)
+	confKeyRegexp    = regexp.MustCompile(`^[a-zA-Z0-9]+([._-][a-zA-Z0-9]+)*$`)
+
+	// reservedConfKeys are client configurations Tarasque or Trogdor already
+	// set, mapped to the field that should be used instead.
+	reservedConfKeys = map[string]string{
+		"bootstrap.servers":  "bootstrapServers",
+		"key.serializer":     "",
+		"value.serializer":   "",
+		"key.deserializer":   "",
+		"value.deserializer": "",
+	}
+
+	connectionStressActions = []string{"CONNECT", "FETCH_METADATA"}
+
+	clientConfFields = []string{"producerConf", "consumerConf", "commonClientConf", "adminClientConf"}
+)
+
+// classFields lists the fields each task class requires, and the optional
+// ones it understands on top of them.
+type classFields struct {
+	required []string
+	optional []string
+}
+
+var classes = map[string]classFields{
+	v1alpha1.ProduceBenchClass: {
+		required: []string{"producerNode", "bootstrapServers", "activeTopics", "targetMessagesPerSec", "maxMessages"},
+		optional: []string{"inactiveTopics", "producerConf", "commonClientConf", "adminClientConf"},
+	},
+	v1alpha1.ConsumeBenchClass: {
+		required: []string{"consumerNode", "bootstrapServers"},
+		optional: []string{"activeTopics", "consumerTopics", "consumerGroup", "threadsPerWorker", "maxMessages", "consumerConf", "commonClientConf", "adminClientConf"},
+	},
+	v1alpha1.RoundTripWorkloadClass: {
+		required: []string{"clientNode", "bootstrapServers", "activeTopics", "targetMessagesPerSec", "maxMessages"},
+		optional: []string{"producerConf", "consumerConf", "commonClientConf", "adminClientConf"},
+	},
+	v1alpha1.ConnectionStressClass: {
+		required: []string{"clientNode", "bootstrapServers", "targetConnectionsPerSec", "numThreads", "action"},
+		optional: []string{"commonClientConf"},
+	},
+	v1alpha1.ExternalCommandClass: {
+		required: []string{"commandNode", "command"},
+		optional: []string{"shutdownGracePeriodMs", "workload"},
+	},
+}
+
+// commonFields are understood by every task class.
+var commonFields = []string{"class", "durationMs"}
+
+// ValidateKafkaBenchParameters returns every rule the supplied parameters
+// violate.
+func ValidateKafkaBenchParameters(spec *v1alpha1.KafkaBenchParameters, path *field.Path) field.ErrorList {
+	fields, err := presentFields(spec)
+	if err != nil {
+		return field.ErrorList{field.InternalError(path, err)}
+	}
+
+	if spec.RawSpec != nil {
+		return validateRawSpec(spec, fields, path)
+	}
+
+	allErrs := field.ErrorList{}
+	allowed := SupportedFields(spec.Class)
+	switch {
+	case spec.Class == "":
+		return append(allErrs, field.Required(path.Child("class"), "set a Trogdor task class, or use rawSpec"))
+	case allowed == nil:
+		return append(allErrs, field.NotSupported(path.Child("class"), spec.Class, supportedClasses()))
+	}
+
+	switch {
+	case spec.DurationMs < 0:
+		allErrs = append(allErrs, field.Invalid(path.Child("durationMs"), spec.DurationMs, "must be positive"))
+	case spec.DurationMs == 0:
+		allErrs = append(allErrs, field.Required(path.Child("durationMs"), ""))
+	}
+
+	for _, f := range classes[spec.Class].required {
+		if !fields[f] {
+			allErrs = append(allErrs, field.Required(path.Child(f), fmt.Sprintf("required by %s", spec.Class)))
+		}
+	}
+	for _, f := range sortedKeys(fields) {
+		if !allowed[f] {
+			allErrs = append(allErrs, field.Forbidden(path.Child(f), fmt.Sprintf("not supported by %s", spec.Class)))
+		}
+	}
+
+	allErrs = append(allErrs, validateCounts(spec, path)...)
+	allErrs = append(allErrs, validateTopics(spec, path)...)
+	allErrs = append(allErrs, validateClientConfs(spec, path)...)
+	return allErrs
+}
+
+// ValidateLoadProfile returns every rule the supplied load profile of a bench
+// with the supplied parameters violates.
+func ValidateLoadProfile(p *v1alpha1.LoadProfile, spec *v1alpha1.KafkaBenchParameters, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.Class != v1alpha1.ProduceBenchClass || spec.RawSpec != nil {
+		allErrs = append(allErrs, field.Forbidden(path, fmt.Sprintf("only supported by %s", v1alpha1.ProduceBenchClass)))
+	}
+	set := 0
+	for _, ok := range []bool{p.Ramp != nil, len(p.Steps) > 0, p.Sine != nil, p.Replay != nil} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return append(allErrs, field.Invalid(path, set, "set exactly one of ramp, steps, sine and replay"))
+	}
+	if p.SegmentDurationMs < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("segmentDurationMs"), p.SegmentDurationMs, "must be positive"))
+	}
+	switch {
+	case p.Ramp != nil:
+		if p.Ramp.FromMessagesPerSec < 1 {
+			allErrs = append(allErrs, field.Invalid(path.Child("ramp", "fromMessagesPerSec"), p.Ramp.FromMessagesPerSec, "must be at least 1"))
+		}
+		if p.Ramp.ToMessagesPerSec < 1 {
+			allErrs = append(allErrs, field.Invalid(path.Child("ramp", "toMessagesPerSec"), p.Ramp.ToMessagesPerSec, "must be at least 1"))
+		}
+	case p.Sine != nil:
+		if p.Sine.MeanMessagesPerSec < 1 {
+			allErrs = append(allErrs, field.Invalid(path.Child("sine", "meanMessagesPerSec"), p.Sine.MeanMessagesPerSec, "must be at least 1"))
+		}
+		if p.Sine.AmplitudeMessagesPerSec < 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child("sine", "amplitudeMessagesPerSec"), p.Sine.AmplitudeMessagesPerSec, "must be positive"))
+		}
+		if p.Sine.PeriodMs < 1 {
+			allErrs = append(allErrs, field.Invalid(path.Child("sine", "periodMs"), p.Sine.PeriodMs, "must be at least 1"))
+		}
+	case p.Replay != nil:
+		if p.Replay.ConfigMapRef.Name == "" {
+			allErrs = append(allErrs, field.Required(path.Child("replay", "configMapRef", "name"), ""))
+		}
+		if p.Replay.ConfigMapRef.Key == "" {
+			allErrs = append(allErrs, field.Required(path.Child("replay", "configMapRef", "key"), ""))
+		}
+	default:
+		for i, pt := range p.Steps {
+			sp := path.Child("steps").Index(i)
+			switch {
+			case i == 0 && pt.OffsetMs != 0:
+				allErrs = append(allErrs, field.Invalid(sp.Child("offsetMs"), pt.OffsetMs, "the first step must start at 0"))
+			case i > 0 && pt.OffsetMs <= p.Steps[i-1].OffsetMs:
+				allErrs = append(allErrs, field.Invalid(sp.Child("offsetMs"), pt.OffsetMs, "must be greater than the offset of the previous step"))
+			}
+			if pt.MessagesPerSec < 1 {
+				allErrs = append(allErrs, field.Invalid(sp.Child("messagesPerSec"), pt.MessagesPerSec, "must be at least 1"))
+			}
+		}
+	}
+	return allErrs
+}
+
+// ValidateStartWindow returns every rule the supplied start window violates.
+func ValidateStartWindow(w *v1alpha1.StartWindow, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if w.Start == w.End {
+		allErrs = append(allErrs, field.Invalid(path.Child("end"), w.End, "must differ from the start of the window"))
+	}
+	return allErrs
+}
+
+// ValidateWarmup returns every rule the supplied warm-up of a bench with the
+// supplied parameters violates.
+func ValidateWarmup(w *v1alpha1.Warmup, spec *v1alpha1.KafkaBenchParameters, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.Class != v1alpha1.ProduceBenchClass || spec.RawSpec != nil {
+		allErrs = append(allErrs, field.Forbidden(path, fmt.Sprintf("only supported by %s", v1alpha1.ProduceBenchClass)))
+	}
+	if (w.DurationMs > 0) == (w.Messages > 0) {
+		allErrs = append(allErrs, field.Invalid(path, w, "set exactly one of durationMs and messages"))
+	}
+	return allErrs
+}
+
+// ValidateAssertions returns every rule the supplied assertions of a bench
+// violate.
+func ValidateAssertions(exprs []string, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, expr := range exprs {
+		if _, err := assertion.Parse(expr); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Index(i), expr, err.Error()))
+		}
+	}
+	return allErrs
+}
+
+// ValidateConnection returns every rule the supplied spec violates in how it
+// connects to Kafka. A bench connects either through a KafkaTarget or to a
+// Kafka cluster run by an operator, whose bootstrap servers would otherwise
+// replace the settings of the target. A bench with a secretRef cannot set the
+// client configurations its credentials derive, which would otherwise be
+// silently replaced by them.
+func ValidateConnection(spec *v1alpha1.KafkaBenchSpec, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.TargetRef != nil && spec.KafkaClusterRef != nil {
+		allErrs = append(allErrs, field.Forbidden(path.Child("targetRef"), "a bench cannot reference both a KafkaTarget and a Kafka cluster"))
+	}
+	if spec.SecretRef != nil {
+		for _, k := range []string{kafka.ConfSecurityProtocol, kafka.ConfSASLMechanism, kafka.ConfSASLJAASConfig} {
+			if _, ok := spec.CommonClientConf[k]; ok {
+				allErrs = append(allErrs, field.Forbidden(path.Child("commonClientConf").Key(k), "set by the credentials of the secretRef"))
+			}
+		}
+	}
+	return allErrs
+}
+
+// ValidateKafkaBenchUpdate returns the rules violated by updating a KafkaBench
+// from old to cur. The parameters of a bench can not change while its task
+// runs.
+func ValidateKafkaBenchUpdate(old, cur *v1alpha1.KafkaBench) field.ErrorList {
+	path := field.NewPath("spec")
+	allErrs := field.ErrorList{}
+
+	obs := old.Status.AtProvider
+	if obs.TaskID == "" || obs.TaskStatus == stateDone || obs.TaskStatus == stateTimedOut || obs.TaskStatus == stateFailed {
+		return allErrs
+	}
+	for _, f := range changedFields(&old.Spec.KafkaBenchParameters, &cur.Spec.KafkaBenchParameters) {
+		allErrs = append(allErrs, field.Forbidden(path.Child(f), "cannot be changed while the bench is running"))
+	}
+	return allErrs
+}
+
+// ValidateNamespacedKafkaBench validates that a NamespacedKafkaBench only
+// references Secrets, ConfigMaps, Kafka clusters and connection secrets of its
+// own namespace. KafkaTargets are cluster scoped and may read credentials and
+// certificates from any namespace, so namespaced benches cannot use them.
+func ValidateNamespacedKafkaBench(namespace string, spec *v1alpha1.KafkaBenchSpec, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.TargetRef != nil {
+		allErrs = append(allErrs, field.Forbidden(path.Child("targetRef"), "a namespaced bench cannot reference a cluster scoped KafkaTarget"))
+	}
+	check := func(p *field.Path, ns string) {
+		if ns != "" && ns != namespace {
+			allErrs = append(allErrs, field.Invalid(p, ns, "must be the namespace of the bench"))
+		}
+	}
+	if ref := spec.WriteConnectionSecretToReference; ref != nil {
+		check(path.Child("writeConnectionSecretToRef", "namespace"), ref.Namespace)
+	}
+	if ref := spec.SecretRef; ref != nil {
+		check(path.Child("secretRef", "namespace"), ref.Namespace)
+	}
+	if t := spec.TLS; t != nil {
+		check(path.Child("tls", "secretRef", "namespace"), t.SecretRef.Namespace)
+	}
+	if ref := spec.KafkaClusterRef; ref != nil {
+		check(path.Child("kafkaClusterRef", "namespace"), ref.Namespace)
+	}
+	if p := spec.LoadProfile; p != nil && p.Replay != nil {
+		check(path.Child("loadProfile", "replay", "configMapRef", "namespace"), p.Replay.ConfigMapRef.Namespace)
+	}
+	return allErrs
+}
+
+func validateRawSpec(spec *v1alpha1.KafkaBenchParameters, fields map[string]bool, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	raw := map[string]interface{}{}
+	if err := json.Unmarshal(spec.RawSpec.Raw, &raw); err != nil {
+		return append(allErrs, field.Invalid(path.Child("rawSpec"), redact.String(string(spec.RawSpec.Raw)), "must be a JSON object"))
+	}
+	if class, _ := raw["class"].(string); class == "" {
+		allErrs = append(allErrs, field.Required(path.Child("rawSpec", "class"), ""))
+	}
+	for _, f := range sortedKeys(fields) {
+		if f != "rawSpec" {
+			allErrs = append(allErrs, field.Forbidden(path.Child(f), "cannot be combined with rawSpec"))
+		}
+	}
+	return allErrs
+}
+
+func validateCounts(spec *v1alpha1.KafkaBenchParameters, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for name, v := range map[string]int64{
+		"targetMessagesPerSec":    int64(spec.TargetMessagesPerSec),
+		"maxMessages":             spec.MaxMessages,
+		"threadsPerWorker":        int64(spec.ThreadsPerWorker),
+		"targetConnectionsPerSec": int64(spec.TargetConnectionsPerSec),
+		"numThreads":              int64(spec.NumThreads),
+		"shutdownGracePeriodMs":   spec.ShutdownGracePeriodMs,
+	} {
+		if v < 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child(name), v, "must be positive"))
+		}
+	}
+	if spec.Class == v1alpha1.ConnectionStressClass && spec.Action != "" && !contains(connectionStressActions, spec.Action) {
+		allErrs = append(allErrs, field.NotSupported(path.Child("action"), spec.Action, connectionStressActions))
+	}
+	sort.Slice(allErrs, func(i, j int) bool { return allErrs[i].Field < allErrs[j].Field })
+	return allErrs
+}
+
+func validateTopics(spec *v1alpha1.KafkaBenchParameters, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(spec.ActiveTopics) > 0 && len(spec.ConsumerTopics) > 0 {
+		allErrs = append(allErrs, field.Forbidden(path.Child("consumerTopics"), "cannot be combined with activeTopics"))
+	}
+	if spec.Class == v1alpha1.ConsumeBenchClass && len(spec.ActiveTopics) == 0 && len(spec.ConsumerTopics) == 0 {
+		allErrs = append(allErrs, field.Required(path.Child("consumerTopics"), "set either activeTopics or consumerTopics"))
+	}
+	for _, name := range []string{"activeTopics", "inactiveTopics"} {
+		topics := spec.ActiveTopics
+		if name == "inactiveTopics" {
+			topics = spec.InactiveTopics
+		}
+		for _, topic := range sortedKeys(topics) {
+			p := path.Child(name).Key(topic)
+			allErrs = append(allErrs, validateTopicPattern(p, topic)...)
+			if t := topics[topic]; t.NumPartitions < 0 {
+				allErrs = append(allErrs, field.Invalid(p.Child("numPartitions"), t.NumPartitions, "must be positive"))
+			}
+			if t := topics[topic]; t.ReplicationFactor < 0 {
+				allErrs = append(allErrs, field.Invalid(p.Child("replicationFactor"), t.ReplicationFactor, "must be positive"))
+			}
+		}
+	}
+	for i, ct := range spec.ConsumerTopics {
+		p := path.Child("consumerTopics").Index(i)
+		topic, partitions := splitConsumerTopic(string(ct))
+		allErrs = append(allErrs, validateTopicPattern(p, topic)...)
+		if partitions != "" {
+			allErrs = append(allErrs, validatePartitionRange(p, partitions)...)
+		}
+	}
+	return allErrs
+}
+
+// validateTopicPattern checks a topic name that may contain one Trogdor range
+// expression, such as test[1-5].
+func validateTopicPattern(path *field.Path, pattern string) field.ErrorList {
+	name := pattern
+	if strings.ContainsAny(pattern, "[]") {
+		m := topicRangeRegexp.FindStringSubmatch(pattern)
+		if m == nil {
+			return field.ErrorList{field.Invalid(path, pattern, "topic names accept a single range such as test[1-5]")}
+		}
+		if !ascending(m[2], m[3]) {
+			return field.ErrorList{field.Invalid(path, pattern, "the start of the range cannot be greater than its end")}
+		}
+		name = m[1] + m[3] + m[4]
+	}
+	if !topicNameRegexp.MatchString(name) || len(name) > maxTopicNameLength {
+		return field.ErrorList{field.Invalid(path, pattern, fmt.Sprintf("topic names must be at most %d characters among [a-zA-Z0-9._-]", maxTopicNameLength))}
+	}
+	return nil
+}
+
+func validatePartitionRange(path *field.Path, partitions string) field.ErrorList {
+	m := partitionRegexp.FindStringSubmatch(partitions)
+	if m == nil {
+		return field.ErrorList{field.Invalid(path, partitions, "partitions must be a number or a range such as [0-3]")}
+	}
+	if m[2] != "" && !ascending(m[2], m[3]) {
+		return field.ErrorList{field.Invalid(path, partitions, "the start of the range cannot be greater than its end")}
+	}
+	return nil
+}
+
+func validateClientConfs(spec *v1alpha1.KafkaBenchParameters, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for _, name := range clientConfFields {
+		conf := clientConf(spec, name)
+		for _, k := range sortedKeys(conf) {
+			p := path.Child(name).Key(k)
+			if !confKeyRegexp.MatchString(k) {
+				allErrs = append(allErrs, field.Invalid(p, k, "not a valid Kafka client configuration key"))
+				continue
+			}
+			if use, ok := reservedConfKeys[k]; ok {
+				msg := "set by Trogdor"
+				if use != "" {
+					msg = fmt.Sprintf("use %s instead", use)
+				}
+				allErrs = append(allErrs, field.Forbidden(p, msg))
+			}
+			if name == "consumerConf" && k == "group.id" {
+				allErrs = append(allErrs, field.Forbidden(p, "use consumerGroup instead"))
+			}
+		}
+	}
+	return allErrs
+}
+
+func clientConf(spec *v1alpha1.KafkaBenchParameters, name string) map[string]string {
+	switch name {
+	case "producerConf":
+		return spec.ProducerConf
+	case "consumerConf":
+		return spec.ConsumerConf
+	case "adminClientConf":
+		return spec.AdminClientConf
+	default:
+		return spec.CommonClientConf
+	}
+}
+
+// presentFields returns the JSON names of the Trogdor fields set in spec.
+func presentFields(spec *v1alpha1.KafkaBenchParameters) (map[string]bool, error) {
+	m, err := trogdorFields(spec)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]bool, len(m))
+	for k := range m {
+		fields[k] = true
+	}
+	return fields, nil
+}
+
+// trogdorFields returns the JSON representation of spec.
+func trogdorFields(spec *v1alpha1.KafkaBenchParameters) (map[string]json.RawMessage, error) {
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]json.RawMessage{}
+	return m, json.Unmarshal(b, &m)
+}
+
+// changedFields returns the JSON names of the Trogdor fields that differ
+// between old and cur.
+func changedFields(old, cur *v1alpha1.KafkaBenchParameters) []string {
+	o, err := trogdorFields(old)
+	if err != nil {
+		return nil
+	}
+	c, err := trogdorFields(cur)
+	if err != nil {
+		return nil
+	}
+	changed := []string{}
+	for k, v := range o {
+		if !jsonEqual(v, c[k]) {
+			changed = append(changed, k)
+		}
+	}
+	for k := range c {
+		if _, ok := o[k]; !ok {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	var av, bv interface{}
+	if json.Unmarshal(a, &av) != nil || json.Unmarshal(b, &bv) != nil {
+		return string(a) == string(b)
+	}
+	ab, _ := json.Marshal(av)
+	bb, _ := json.Marshal(bv)
+	return string(ab) == string(bb)
+}
+
+func splitConsumerTopic(ct string) (string, string) {
+	if i := strings.LastIndex(ct, ":"); i >= 0 {
+		return ct[:i], ct[i+1:]
+	}
+	return ct, ""
+}
+
+func ascending(start, end string) bool {
+	s, err := strconv.Atoi(start)
+	if err != nil {
+		return false
+	}
+	e, err := strconv.Atoi(end)
+	return err == nil && s <= e
+}
+
+// SupportedFields returns the JSON names of the fields understood by the
+// supplied task class, or nil when the class has no dedicated fields.
+func SupportedFields(class string) map[string]bool {
+	cf, ok := classes[class]
+	if !ok {
+		return nil
+	}
+	fields := map[string]bool{}
+	for _, f := range commonFields {
+		fields[f] = true
+	}
+	for _, f := range append(cf.required, cf.optional...) {
+		fields[f] = true
+	}
+	return fields
+}
+
+func supportedClasses() []string {
+	return sortedKeys(classes)
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,550 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+)
+
+func produceBench() v1alpha1.KafkaBenchParameters {
+	return v1alpha1.KafkaBenchParameters{
+		Class:                v1alpha1.ProduceBenchClass,
+		DurationMs:           60000,
+		ProducerNode:         "node0",
+		BootstrapServers:     "kafka:9092",
+		TargetMessagesPerSec: 1000,
+		MaxMessages:          10000,
+		ActiveTopics:         map[string]v1alpha1.KafkaTopics{"test[1-5]": {NumPartitions: 3, ReplicationFactor: 1}},
+	}
+}
+
+// errs summarises an error list as "type: field" so that tests do not depend
+// on the exact wording of each message.
+func errs(list field.ErrorList) []string {
+	out := []string{}
+	for _, e := range list {
+		out = append(out, string(e.Type)+": "+e.Field)
+	}
+	return out
+}
+
+func TestValidateKafkaBenchParameters(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		spec   func() v1alpha1.KafkaBenchParameters
+		want   []string
+	}{
+		"ValidProduceBench": {
+			reason: "A complete produce bench should be valid.",
+			spec:   produceBench,
+			want:   []string{},
+		},
+		"UnknownClass": {
+			reason: "Classes without dedicated fields must go through rawSpec.",
+			spec: func() v1alpha1.KafkaBenchParameters {
+				s := produceBench()
+				s.Class = "org.apache.kafka.trogdor.workload.Unknown"
+				return s
+			},
+			want: []string{"FieldValueNotSupported: spec.class"},
+		},
+		"MissingRequired": {
+			reason: "Fields required by the class should be reported.",
+			spec: func() v1alpha1.KafkaBenchParameters {
+				s := produceBench()
+				s.BootstrapServers = ""
+				s.DurationMs = -1
+				return s
+			},
+			want: []string{"FieldValueInvalid: spec.durationMs", "FieldValueRequired: spec.bootstrapServers"},
+		},
+		"MissingClientNode": {
+			reason: "A round trip bench needs a client node rather than a producer node.",
+			spec: func() v1alpha1.KafkaBenchParameters {
+				s := produceBench()
+				s.Class = v1alpha1.RoundTripWorkloadClass
+				return s
+			},
+			want: []string{"FieldValueRequired: spec.clientNode", "FieldValueForbidden: spec.producerNode"},
+		},
+		"ForbiddenCombination": {
+			reason: "Fields the class does not understand should be rejected.",
+			spec: func() v1alpha1.KafkaBenchParameters {
+				s := produceBench()
+				s.ConsumerGroup = "group"
+				s.NumThreads = 4
+				return s
+			},
+			want: []string{"FieldValueForbidden: spec.consumerGroup", "FieldValueForbidden: spec.numThreads"},
+		},
+		"NegativeCounts": {
+			reason: "Negative rates and counts should be rejected.",
+			spec: func() v1alpha1.KafkaBenchParameters {
+				s := produceBench()
+				s.MaxMessages = -1
+				s.TargetMessagesPerSec = -10
+				return s
+			},
+			want: []string{"FieldValueInvalid: spec.maxMessages", "FieldValueInvalid: spec.targetMessagesPerSec"},
+		},
+		"TopicPatterns": {
+			reason: "Topic ranges and names should follow the Trogdor and Kafka syntax.",
+			spec: func() v1alpha1.KafkaBenchParameters {
+				s := produceBench()
+				s.ActiveTopics = map[string]v1alpha1.KafkaTopics{
+					"a[5-1]":      {},
+					"b[1-2][3-4]": {},
+					"c d":         {},
+					"ok[0-9]-x":   {},
+				}
+				return s
+			},
+			want: []string{
+				"FieldValueInvalid: spec.activeTopics[a[5-1]]",
+				"FieldValueInvalid: spec.activeTopics[b[1-2][3-4]]",
+				"FieldValueInvalid: spec.activeTopics[c d]",
+			},
+		},
+		"TopicBounds": {
+			reason: "Partitions and replication factors cannot be negative.",
+			spec: func() v1alpha1.KafkaBenchParameters {
+				s := produceBench()
+				s.ActiveTopics = map[string]v1alpha1.KafkaTopics{"test": {NumPartitions: -1, ReplicationFactor: -1}}
+				return s
+			},
+			want: []string{
+				"FieldValueInvalid: spec.activeTopics[test].numPartitions",
+				"FieldValueInvalid: spec.activeTopics[test].replicationFactor",
+			},
+		},
+		"ClientConfKeys": {
+			reason: "Malformed and reserved client configuration keys should be rejected.",
+			spec: func() v1alpha1.KafkaBenchParameters {
+				s := produceBench()
+				s.ProducerConf = map[string]string{"acks": "all", "bootstrap.servers": "other:9092", "bad key": "x"}
+				return s
+			},
+			want: []string{
+				"FieldValueInvalid: spec.producerConf[bad key]",
+				"FieldValueForbidden: spec.producerConf[bootstrap.servers]",
+			},
+		},
+		"ConsumerTopics": {
+			reason: "Consumer benches need topics, and partition ranges must be ascending.",
+			spec: func() v1alpha1.KafkaBenchParameters {
+				return v1alpha1.KafkaBenchParameters{
+					Class:            v1alpha1.ConsumeBenchClass,
+					DurationMs:       60000,
+					ConsumerNode:     "node0",
+					BootstrapServers: "kafka:9092",
+					ConsumerTopics:   []v1alpha1.ConsumerTopic{"test[1-5]:[0-3]", "test:[3-0]"},
+					ConsumerConf:     map[string]string{"group.id": "g"},
+				}
+			},
+			want: []string{
+				"FieldValueInvalid: spec.consumerTopics[1]",
+				"FieldValueForbidden: spec.consumerConf[group.id]",
+			},
+		},
+		"ConsumerWithoutTopics": {
+			reason: "Consumer benches need either activeTopics or consumerTopics.",
+			spec: func() v1alpha1.KafkaBenchParameters {
+				return v1alpha1.KafkaBenchParameters{
+					Class:            v1alpha1.ConsumeBenchClass,
+					DurationMs:       60000,
+					ConsumerNode:     "node0",
+					BootstrapServers: "kafka:9092",
+				}
+			},
+			want: []string{"FieldValueRequired: spec.consumerTopics"},
+		},
+		"ConnectionStressAction": {
+			reason: "Connection stress benches only support Trogdor's actions.",
+			spec: func() v1alpha1.KafkaBenchParameters {
+				return v1alpha1.KafkaBenchParameters{
+					Class:                   v1alpha1.ConnectionStressClass,
+					DurationMs:              60000,
+					ClientNode:              "node0",
+					BootstrapServers:        "kafka:9092",
+					TargetConnectionsPerSec: 100,
+					NumThreads:              2,
+					Action:                  "DISCONNECT",
+				}
+			},
+			want: []string{"FieldValueNotSupported: spec.action"},
+		},
+		"RawSpec": {
+			reason: "A rawSpec must set its class and cannot be combined with typed fields.",
+			spec: func() v1alpha1.KafkaBenchParameters {
+				return v1alpha1.KafkaBenchParameters{
+					BootstrapServers: "kafka:9092",
+					RawSpec:          &runtime.RawExtension{Raw: []byte(`{"durationMs":1000}`)},
+				}
+			},
+			want: []string{"FieldValueRequired: spec.rawSpec.class", "FieldValueForbidden: spec.bootstrapServers"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := tc.spec()
+			got := errs(ValidateKafkaBenchParameters(&s, field.NewPath("spec")))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nValidateKafkaBenchParameters(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidateLoadProfile(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		profile v1alpha1.LoadProfile
+		spec    func() v1alpha1.KafkaBenchParameters
+		want    []string
+	}{
+		"Ramp": {
+			reason:  "A ramp of a produce bench should be valid.",
+			profile: v1alpha1.LoadProfile{Ramp: &v1alpha1.RampProfile{FromMessagesPerSec: 100, ToMessagesPerSec: 1000}},
+			spec:    produceBench,
+			want:    []string{},
+		},
+		"NotProduceBench": {
+			reason:  "Load profiles only vary the rate of produce benches.",
+			profile: v1alpha1.LoadProfile{Ramp: &v1alpha1.RampProfile{FromMessagesPerSec: 100, ToMessagesPerSec: 1000}},
+			spec: func() v1alpha1.KafkaBenchParameters {
+				s := produceBench()
+				s.Class = v1alpha1.ConsumeBenchClass
+				return s
+			},
+			want: []string{"FieldValueForbidden: spec.loadProfile"},
+		},
+		"SeveralShapes": {
+			reason: "A profile should have a single shape.",
+			profile: v1alpha1.LoadProfile{
+				Ramp: &v1alpha1.RampProfile{FromMessagesPerSec: 100, ToMessagesPerSec: 1000},
+				Sine: &v1alpha1.SineProfile{MeanMessagesPerSec: 100, PeriodMs: 60000},
+			},
+			spec: produceBench,
+			want: []string{"FieldValueInvalid: spec.loadProfile"},
+		},
+		"InvalidSine": {
+			reason:  "A sine wave should have a mean and a period.",
+			profile: v1alpha1.LoadProfile{Sine: &v1alpha1.SineProfile{AmplitudeMessagesPerSec: 100}},
+			spec:    produceBench,
+			want:    []string{"FieldValueInvalid: spec.loadProfile.sine.meanMessagesPerSec", "FieldValueInvalid: spec.loadProfile.sine.periodMs"},
+		},
+		"UnorderedSteps": {
+			reason: "Steps should start at the beginning of the bench and follow one another.",
+			profile: v1alpha1.LoadProfile{Steps: []v1alpha1.LoadPoint{
+				{OffsetMs: 1000, MessagesPerSec: 100},
+				{OffsetMs: 1000, MessagesPerSec: 0},
+			}},
+			spec: produceBench,
+			want: []string{
+				"FieldValueInvalid: spec.loadProfile.steps[0].offsetMs",
+				"FieldValueInvalid: spec.loadProfile.steps[1].offsetMs",
+				"FieldValueInvalid: spec.loadProfile.steps[1].messagesPerSec",
+			},
+		},
+		"IncompleteReplay": {
+			reason:  "A replay should name its ConfigMap and key.",
+			profile: v1alpha1.LoadProfile{Replay: &v1alpha1.ReplayProfile{}},
+			spec:    produceBench,
+			want:    []string{"FieldValueRequired: spec.loadProfile.replay.configMapRef.name", "FieldValueRequired: spec.loadProfile.replay.configMapRef.key"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			spec := tc.spec()
+			got := errs(ValidateLoadProfile(&tc.profile, &spec, field.NewPath("spec", "loadProfile")))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nValidateLoadProfile(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidateStartWindow(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		window v1alpha1.StartWindow
+		want   []string
+	}{
+		"Valid": {
+			reason: "Windows spanning midnight should be valid.",
+			window: v1alpha1.StartWindow{Start: "23:00", End: "01:00"},
+			want:   []string{},
+		},
+		"Empty": {
+			reason: "Windows ending when they start should be invalid.",
+			window: v1alpha1.StartWindow{Start: "02:00", End: "02:00"},
+			want:   []string{"FieldValueInvalid: spec.startWindow.end"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := errs(ValidateStartWindow(&tc.window, field.NewPath("spec", "startWindow")))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nValidateStartWindow(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidateConnection(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		spec   v1alpha1.KafkaBenchSpec
+		want   []string
+	}{
+		"Target": {
+			reason: "Benches connecting through a KafkaTarget should be valid.",
+			spec:   v1alpha1.KafkaBenchSpec{TargetRef: &xpv1.Reference{Name: "target"}},
+			want:   []string{},
+		},
+		"TargetAndCluster": {
+			reason: "Benches referencing both a KafkaTarget and a Kafka cluster should be invalid.",
+			spec: v1alpha1.KafkaBenchSpec{
+				TargetRef:       &xpv1.Reference{Name: "target"},
+				KafkaClusterRef: &v1alpha1.KafkaClusterReference{Operator: v1alpha1.StrimziOperator, Name: "my-cluster", Listener: "tls"},
+			},
+			want: []string{"FieldValueForbidden: spec.targetRef"},
+		},
+		"CredentialsAndClientConf": {
+			reason: "Benches with a secretRef should not set the client configurations derived from their credentials.",
+			spec: v1alpha1.KafkaBenchSpec{
+				SecretRef: &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Namespace: "default", Name: "creds"}, Key: "credentials"},
+				KafkaBenchParameters: v1alpha1.KafkaBenchParameters{CommonClientConf: map[string]string{
+					"security.protocol": "SASL_PLAINTEXT",
+					"sasl.mechanism":    "PLAIN",
+					"client.id":         "bench",
+				}},
+			},
+			want: []string{
+				"FieldValueForbidden: spec.commonClientConf[security.protocol]",
+				"FieldValueForbidden: spec.commonClientConf[sasl.mechanism]",
+			},
+		},
+		"ClientConfWithoutCredentials": {
+			reason: "Benches without a secretRef should be free to set their security protocol.",
+			spec: v1alpha1.KafkaBenchSpec{
+				KafkaBenchParameters: v1alpha1.KafkaBenchParameters{CommonClientConf: map[string]string{"security.protocol": "SSL"}},
+			},
+			want: []string{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := errs(ValidateConnection(&tc.spec, field.NewPath("spec")))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nValidateConnection(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidateWarmup(t *testing.T) {
+	consumer := produceBench()
+	consumer.Class = v1alpha1.ConsumeBenchClass
+	cases := map[string]struct {
+		reason string
+		warmup v1alpha1.Warmup
+		spec   v1alpha1.KafkaBenchParameters
+		want   []string
+	}{
+		"Valid": {
+			reason: "Warm-ups lasting for a duration should be valid.",
+			warmup: v1alpha1.Warmup{DurationMs: 30000},
+			spec:   produceBench(),
+			want:   []string{},
+		},
+		"Both": {
+			reason: "Warm-ups lasting for both a duration and messages should be invalid.",
+			warmup: v1alpha1.Warmup{DurationMs: 30000, Messages: 1000},
+			spec:   produceBench(),
+			want:   []string{"FieldValueInvalid: spec.warmup"},
+		},
+		"NotProducer": {
+			reason: "Warm-ups of benches other than produce benches should be forbidden.",
+			warmup: v1alpha1.Warmup{Messages: 1000},
+			spec:   consumer,
+			want:   []string{"FieldValueForbidden: spec.warmup"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := errs(ValidateWarmup(&tc.warmup, &tc.spec, field.NewPath("spec", "warmup")))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nValidateWarmup(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidateAssertions(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		exprs  []string
+		want   []string
+	}{
+		"Valid": {
+			reason: "Metrics compared to numbers or other metrics should be valid.",
+			exprs:  []string{"producer.p99LatencyMs < 50", "consumer.totalMessagesReceived==producer.totalSent"},
+			want:   []string{},
+		},
+		"Invalid": {
+			reason: "Malformed assertions and unknown metrics should be invalid.",
+			exprs:  []string{"throughputMsgsPerSec >= 9000", "producer.p99LatencyMs << 50", "producer.p999LatencyMs < 50"},
+			want:   []string{"FieldValueInvalid: spec.assertions[1]", "FieldValueInvalid: spec.assertions[2]"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := errs(ValidateAssertions(tc.exprs, field.NewPath("spec", "assertions")))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nValidateAssertions(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidateKafkaBenchUpdate(t *testing.T) {
+	bench := func(status string, rate int32) *v1alpha1.KafkaBench {
+		b := &v1alpha1.KafkaBench{Spec: v1alpha1.KafkaBenchSpec{KafkaBenchParameters: produceBench()}}
+		b.Spec.TargetMessagesPerSec = rate
+		b.Status.AtProvider.TaskStatus = status
+		if status != "" {
+			b.Status.AtProvider.TaskID = "task"
+		}
+		return b
+	}
+
+	cases := map[string]struct {
+		reason string
+		old    *v1alpha1.KafkaBench
+		cur    *v1alpha1.KafkaBench
+		want   []string
+	}{
+		"NotStarted": {
+			reason: "Benches that have not been dispatched can be changed.",
+			old:    bench("", 1000),
+			cur:    bench("", 2000),
+			want:   []string{},
+		},
+		"Running": {
+			reason: "Running benches cannot be changed.",
+			old:    bench("RUNNING", 1000),
+			cur:    bench("RUNNING", 2000),
+			want:   []string{"FieldValueForbidden: spec.targetMessagesPerSec"},
+		},
+		"RunningResourceSpec": {
+			reason: "Fields common to every managed resource can change while a bench runs.",
+			old:    bench("RUNNING", 1000),
+			cur: func() *v1alpha1.KafkaBench {
+				b := bench("RUNNING", 1000)
+				b.Spec.DeletionPolicy = xpv1.DeletionOrphan
+				return b
+			}(),
+			want: []string{},
+		},
+		"Done": {
+			reason: "Finished benches can be changed.",
+			old:    bench("DONE", 1000),
+			cur:    bench("DONE", 2000),
+			want:   []string{},
+		},
+		"TimedOut": {
+			reason: "Benches stopped by their watchdog can be changed.",
+			old:    bench("TIMED_OUT", 1000),
+			cur:    bench("TIMED_OUT", 2000),
+			want:   []string{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := errs(ValidateKafkaBenchUpdate(tc.old, tc.cur))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nValidateKafkaBenchUpdate(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidateNamespacedKafkaBench(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		spec   v1alpha1.KafkaBenchSpec
+		want   []string
+	}{
+		"Local": {
+			reason: "References to the namespace of the bench are valid.",
+			spec: v1alpha1.KafkaBenchSpec{
+				ResourceSpec: xpv1.ResourceSpec{WriteConnectionSecretToReference: &xpv1.SecretReference{Namespace: "team-a", Name: "conn"}},
+				SecretRef:    &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Namespace: "team-a", Name: "creds"}, Key: "credentials"},
+			},
+			want: []string{},
+		},
+		"Foreign": {
+			reason: "References to other namespaces are invalid.",
+			spec: v1alpha1.KafkaBenchSpec{
+				ResourceSpec:    xpv1.ResourceSpec{WriteConnectionSecretToReference: &xpv1.SecretReference{Namespace: "team-b", Name: "conn"}},
+				SecretRef:       &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Namespace: "team-b", Name: "creds"}, Key: "credentials"},
+				TLS:             &v1alpha1.KafkaBenchTLS{SecretRef: xpv1.SecretReference{Namespace: "team-b", Name: "tls"}},
+				KafkaClusterRef: &v1alpha1.KafkaClusterReference{Operator: v1alpha1.StrimziOperator, Namespace: "kafka", Name: "my-cluster", Listener: "tls"},
+				LoadProfile: &v1alpha1.LoadProfile{Replay: &v1alpha1.ReplayProfile{
+					ConfigMapRef: v1alpha1.ConfigMapKeySelector{Namespace: "team-b", Name: "traffic", Key: "rates.csv"},
+				}},
+			},
+			want: []string{
+				"FieldValueInvalid: spec.writeConnectionSecretToRef.namespace",
+				"FieldValueInvalid: spec.secretRef.namespace",
+				"FieldValueInvalid: spec.tls.secretRef.namespace",
+				"FieldValueInvalid: spec.kafkaClusterRef.namespace",
+				"FieldValueInvalid: spec.loadProfile.replay.configMapRef.namespace",
+			},
+		},
+		"Target": {
+			reason: "Namespaced benches cannot reference a KafkaTarget, whose Secrets may live in any namespace.",
+			spec:   v1alpha1.KafkaBenchSpec{TargetRef: &xpv1.Reference{Name: "shared"}},
+			want:   []string{"FieldValueForbidden: spec.targetRef"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := errs(ValidateNamespacedKafkaBench("team-a", &tc.spec, field.NewPath("spec")))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nValidateNamespacedKafkaBench(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
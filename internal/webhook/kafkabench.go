@@ -0,0 +1,266 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhook serves the admission webhooks of the Tarasque provider.
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+	admissionv1 "k8s.io/api/admission/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	apisv1alpha1 "github.com/nachomdo/tarasque/apis/v1alpha1"
+	"github.com/nachomdo/tarasque/internal/defaults"
+	"github.com/nachomdo/tarasque/internal/loadprofile"
+	"github.com/nachomdo/tarasque/internal/validation"
+)
+
+const (
+	// KafkaBenchPath is the path the KafkaBench validating webhook is served
+	// on.
+	KafkaBenchPath = "/validate-tarasque-crossplane-io-v1alpha1-kafkabench"
+	// NamespacedKafkaBenchPath is the path the NamespacedKafkaBench
+	// validating webhook is served on.
+	NamespacedKafkaBenchPath = "/validate-tarasque-crossplane-io-v1alpha1-namespacedkafkabench"
+
+	// verbApprove is the verb users must be allowed on benches to approve
+	// them to exceed their guardrails.
+	verbApprove = "approve"
+
+	errNewDecoder = "cannot create admission decoder"
+	errGetPC      = "cannot get ProviderConfig"
+	errGetTarget  = "cannot get KafkaTarget"
+	errReviewUser = "cannot review whether the user may approve benches"
+)
+
+// Setup registers the Tarasque admission webhooks with the supplied manager.
+func Setup(mgr ctrl.Manager) error {
+	d, err := admission.NewDecoder(mgr.GetScheme())
+	if err != nil {
+		return errors.Wrap(err, errNewDecoder)
+	}
+	mgr.GetWebhookServer().Register(KafkaBenchPath, &webhook.Admission{Handler: &KafkaBenchValidator{kube: mgr.GetClient(), decoder: d}})
+	mgr.GetWebhookServer().Register(NamespacedKafkaBenchPath, &webhook.Admission{Handler: &KafkaBenchValidator{kube: mgr.GetClient(), decoder: d, namespaced: true}})
+	return nil
+}
+
+// A KafkaBenchValidator rejects KafkaBenches that Trogdor could not run.
+// Benches are validated once the settings of their KafkaTarget and the bench
+// defaults of their ProviderConfig have been applied, and must stay within
+// their guardrails unless a user allowed to approve benches approved them. A
+// namespaced validator validates NamespacedKafkaBenches, which may only
+// reference their own namespace.
+type KafkaBenchValidator struct {
+	kube       client.Client
+	decoder    *admission.Decoder
+	namespaced bool
+}
+
+// Handle validates KafkaBench creates and updates.
+func (v *KafkaBenchValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	cur, err := v.decode(req.Object)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	target, err := v.target(ctx, cur)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	pc, err := v.providerConfig(ctx, cur)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	var bd *v1alpha1.KafkaBenchParameters
+	if pc != nil {
+		bd = pc.Spec.BenchDefaults
+	}
+	params, err := defaults.ApplyKafkaBench(defaults.KafkaTarget(target), cur.Spec.KafkaBenchParameters)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	params, err = defaults.ApplyKafkaBench(bd, params)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	errs := field.ErrorList{}
+	if p := cur.Spec.LoadProfile; p != nil {
+		errs = append(errs, validation.ValidateLoadProfile(p, &params, field.NewPath("spec", "loadProfile"))...)
+		if p.Replay == nil && len(errs) == 0 {
+			// The rate and messages of a bench with a load profile are set
+			// by each segment, and it must stay within its guardrails over
+			// all of them.
+			params = loadprofile.Envelope(params, loadprofile.Segments(p, params.DurationMs, nil))
+		}
+		if p.Replay != nil && !v.namespaced && p.Replay.ConfigMapRef.Namespace == "" {
+			errs = append(errs, field.Required(field.NewPath("spec", "loadProfile", "replay", "configMapRef", "namespace"), ""))
+		}
+	}
+	if w := cur.Spec.StartWindow; w != nil {
+		errs = append(errs, validation.ValidateStartWindow(w, field.NewPath("spec", "startWindow"))...)
+	}
+	if w := cur.Spec.Warmup; w != nil {
+		errs = append(errs, validation.ValidateWarmup(w, &params, field.NewPath("spec", "warmup"))...)
+	}
+	errs = append(errs, validation.ValidateAssertions(cur.Spec.Assertions, field.NewPath("spec", "assertions"))...)
+	errs = append(errs, validation.ValidateKafkaBenchParameters(&params, field.NewPath("spec"))...)
+	if !validation.GuardrailsApproved(cur) {
+		errs = append(errs, validation.ValidateGuardrails(validation.Guardrails(pc, target), &params, field.NewPath("spec"))...)
+	}
+	if v.namespaced {
+		ns := cur.GetNamespace()
+		if ns == "" {
+			ns = req.Namespace
+		}
+		errs = append(errs, validation.ValidateNamespacedKafkaBench(ns, &cur.Spec, field.NewPath("spec"))...)
+	}
+	errs = append(errs, validation.ValidateConnection(&cur.Spec, field.NewPath("spec"))...)
+	if cur.Spec.KafkaClusterRef != nil {
+		// The bootstrap servers are resolved from the referenced cluster.
+		errs = errs.Filter(func(err error) bool {
+			e, ok := err.(*field.Error)
+			return ok && e.Type == field.ErrorTypeRequired && e.Field == "spec.bootstrapServers"
+		})
+	}
+	if p := cur.Spec.LoadProfile; p != nil && p.Replay != nil {
+		// The rates of a replay are read from its ConfigMap when the bench
+		// is dispatched.
+		errs = errs.Filter(func(err error) bool {
+			e, ok := err.(*field.Error)
+			return ok && e.Type == field.ErrorTypeRequired && (e.Field == "spec.targetMessagesPerSec" || e.Field == "spec.maxMessages")
+		})
+	}
+
+	old := &v1alpha1.KafkaBench{}
+	if req.Operation == admissionv1.Update {
+		old, err = v.decode(req.OldObject)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		errs = append(errs, validation.ValidateKafkaBenchUpdate(old, cur)...)
+	}
+	if validation.GuardrailsApproved(cur) && !validation.GuardrailsApproved(old) {
+		ok, err := v.mayApprove(ctx, req, cur)
+		if err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+		if !ok {
+			p := field.NewPath("metadata", "annotations").Key(v1alpha1.AnnotationKeyGuardrailsApproved)
+			errs = append(errs, field.Forbidden(p, fmt.Sprintf("%s may not approve benches to exceed their guardrails", req.UserInfo.Username)))
+		}
+	}
+
+	if len(errs) == 0 {
+		return admission.Allowed("")
+	}
+	gk := v1alpha1.KafkaBenchGroupVersionKind.GroupKind()
+	if v.namespaced {
+		gk = v1alpha1.NamespacedKafkaBenchGroupVersionKind.GroupKind()
+	}
+	invalid := kerrors.NewInvalid(gk, cur.GetName(), errs)
+	return admission.Response{AdmissionResponse: admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result:  &invalid.ErrStatus,
+	}}
+}
+
+// decode returns the supplied KafkaBench or NamespacedKafkaBench as a
+// KafkaBench, since both share the same spec and status.
+func (v *KafkaBenchValidator) decode(raw runtime.RawExtension) (*v1alpha1.KafkaBench, error) {
+	if !v.namespaced {
+		cr := &v1alpha1.KafkaBench{}
+		return cr, v.decoder.DecodeRaw(raw, cr)
+	}
+	ncr := &v1alpha1.NamespacedKafkaBench{}
+	if err := v.decoder.DecodeRaw(raw, ncr); err != nil {
+		return nil, err
+	}
+	return &v1alpha1.KafkaBench{ObjectMeta: ncr.ObjectMeta, Spec: ncr.Spec, Status: ncr.Status}, nil
+}
+
+// providerConfig returns the ProviderConfig of cr, or nil if it does not
+// exist yet.
+func (v *KafkaBenchValidator) providerConfig(ctx context.Context, cr *v1alpha1.KafkaBench) (*apisv1alpha1.ProviderConfig, error) {
+	ref := cr.GetProviderConfigReference()
+	if ref == nil {
+		return nil, nil
+	}
+	pc := &apisv1alpha1.ProviderConfig{}
+	if err := v.kube.Get(ctx, types.NamespacedName{Name: ref.Name}, pc); err != nil {
+		return nil, errors.Wrap(resource.Ignore(kerrors.IsNotFound, err), errGetPC)
+	}
+	return pc, nil
+}
+
+// mayApprove returns whether the user making the supplied request may approve
+// cr to exceed its guardrails, that is whether they are allowed to approve
+// the bench.
+func (v *KafkaBenchValidator) mayApprove(ctx context.Context, req admission.Request, cr *v1alpha1.KafkaBench) (bool, error) {
+	res := "kafkabenches"
+	if v.namespaced {
+		res = "namespacedkafkabenches"
+	}
+	extra := make(map[string]authorizationv1.ExtraValue, len(req.UserInfo.Extra))
+	for k, e := range req.UserInfo.Extra {
+		extra[k] = authorizationv1.ExtraValue(e)
+	}
+	sar := &authorizationv1.SubjectAccessReview{Spec: authorizationv1.SubjectAccessReviewSpec{
+		User:   req.UserInfo.Username,
+		UID:    req.UserInfo.UID,
+		Groups: req.UserInfo.Groups,
+		Extra:  extra,
+		ResourceAttributes: &authorizationv1.ResourceAttributes{
+			Namespace: req.Namespace,
+			Verb:      verbApprove,
+			Group:     v1alpha1.Group,
+			Resource:  res,
+			Name:      cr.GetName(),
+		},
+	}}
+	if err := v.kube.Create(ctx, sar); err != nil {
+		return false, errors.Wrap(err, errReviewUser)
+	}
+	return sar.Status.Allowed, nil
+}
+
+// target returns the KafkaTarget of cr. A KafkaTarget that does not exist yet
+// has no settings.
+func (v *KafkaBenchValidator) target(ctx context.Context, cr *v1alpha1.KafkaBench) (*v1alpha1.KafkaTarget, error) {
+	ref := cr.Spec.TargetRef
+	if ref == nil {
+		return nil, nil
+	}
+	t := &v1alpha1.KafkaTarget{}
+	if err := v.kube.Get(ctx, types.NamespacedName{Name: ref.Name}, t); err != nil {
+		return nil, errors.Wrap(resource.Ignore(kerrors.IsNotFound, err), errGetTarget)
+	}
+	return t, nil
+}
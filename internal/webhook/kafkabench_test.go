@@ -0,0 +1,272 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/nachomdo/tarasque/apis"
+	"github.com/nachomdo/tarasque/apis/tarasque/v1alpha1"
+	apisv1alpha1 "github.com/nachomdo/tarasque/apis/v1alpha1"
+)
+
+func raw(t *testing.T, kb client.Object) runtime.RawExtension {
+	t.Helper()
+	b, err := json.Marshal(kb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return runtime.RawExtension{Raw: b}
+}
+
+func TestKafkaBenchValidatorHandle(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := apis.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	d, err := admission.NewDecoder(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valid := &v1alpha1.KafkaBench{Spec: v1alpha1.KafkaBenchSpec{
+		KafkaBenchParameters: v1alpha1.KafkaBenchParameters{
+			Class:                v1alpha1.ProduceBenchClass,
+			DurationMs:           60000,
+			ProducerNode:         "node0",
+			BootstrapServers:     "kafka:9092",
+			TargetMessagesPerSec: 1000,
+			MaxMessages:          10000,
+			ActiveTopics:         map[string]v1alpha1.KafkaTopics{"test[1-5]": {}},
+		},
+	}}
+	invalid := valid.DeepCopy()
+	invalid.Spec.BootstrapServers = ""
+	running := valid.DeepCopy()
+	running.Status.AtProvider.TaskID = "task"
+	running.Status.AtProvider.TaskStatus = "RUNNING"
+	changed := valid.DeepCopy()
+	changed.Spec.MaxMessages = 20
+	defaulted := invalid.DeepCopy()
+	defaulted.Spec.ProviderConfigReference = &xpv1.Reference{Name: "defaults"}
+	resolved := invalid.DeepCopy()
+	resolved.Spec.KafkaClusterRef = &v1alpha1.KafkaClusterReference{Operator: v1alpha1.StrimziOperator, Namespace: "kafka", Name: "my-cluster", Listener: "tls"}
+	targeted := invalid.DeepCopy()
+	targeted.Spec.TargetRef = &xpv1.Reference{Name: "shared"}
+	ambiguous := resolved.DeepCopy()
+	ambiguous.Spec.TargetRef = &xpv1.Reference{Name: "shared"}
+	local := &v1alpha1.NamespacedKafkaBench{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"}, Spec: *valid.Spec.DeepCopy()}
+	local.Spec.SecretRef = &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Namespace: "team-a", Name: "creds"}, Key: "credentials"}
+	foreign := local.DeepCopy()
+	foreign.Spec.SecretRef.Namespace = "team-b"
+	guarded := valid.DeepCopy()
+	guarded.Spec.ProviderConfigReference = &xpv1.Reference{Name: "guarded"}
+	flooding := guarded.DeepCopy()
+	flooding.Spec.TargetMessagesPerSec = 10000000
+	approved := flooding.DeepCopy()
+	approved.SetAnnotations(map[string]string{v1alpha1.AnnotationKeyGuardrailsApproved: "true"})
+	ramped := guarded.DeepCopy()
+	ramped.Spec.TargetMessagesPerSec, ramped.Spec.MaxMessages = 0, 0
+	ramped.Spec.LoadProfile = &v1alpha1.LoadProfile{Ramp: &v1alpha1.RampProfile{FromMessagesPerSec: 1000, ToMessagesPerSec: 5000}, SegmentDurationMs: 20000}
+	overRamped := ramped.DeepCopy()
+	overRamped.Spec.LoadProfile.Ramp.ToMessagesPerSec = 50000
+	replayed := ramped.DeepCopy()
+	replayed.Spec.LoadProfile = &v1alpha1.LoadProfile{Replay: &v1alpha1.ReplayProfile{ConfigMapRef: v1alpha1.ConfigMapKeySelector{Namespace: "bench", Name: "traffic", Key: "rates.csv"}}}
+	unplaced := replayed.DeepCopy()
+	unplaced.Spec.LoadProfile.Replay.ConfigMapRef.Namespace = ""
+	windowless := valid.DeepCopy()
+	windowless.Spec.StartWindow = &v1alpha1.StartWindow{Start: "02:00", End: "02:00"}
+	unbounded := valid.DeepCopy()
+	unbounded.Spec.Warmup = &v1alpha1.Warmup{}
+	unknown := valid.DeepCopy()
+	unknown.Spec.Assertions = []string{"producer.p999LatencyMs < 50"}
+
+	kube := &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			switch o := obj.(type) {
+			case *apisv1alpha1.ProviderConfig:
+				switch key.Name {
+				case "defaults":
+					o.Spec.BenchDefaults = &v1alpha1.KafkaBenchParameters{BootstrapServers: "kafka:9092"}
+					return nil
+				case "guarded":
+					o.Spec.Guardrails = &v1alpha1.Guardrails{MaxMessagesPerSec: 10000, AllowedBootstrapServers: []string{"kafka:*"}}
+					return nil
+				}
+			case *v1alpha1.KafkaTarget:
+				if key.Name == "shared" {
+					o.Spec.BootstrapServers = "kafka:9092"
+					return nil
+				}
+			}
+			return kerrors.NewNotFound(schema.GroupResource{}, key.Name)
+		},
+		// Only admins may approve benches.
+		MockCreate: func(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+			sar := obj.(*authorizationv1.SubjectAccessReview)
+			a := sar.Spec.ResourceAttributes
+			sar.Status.Allowed = sar.Spec.User == "admin" && a.Verb == "approve" && a.Group == v1alpha1.Group && a.Resource == "kafkabenches"
+			return nil
+		},
+	}
+
+	cases := map[string]struct {
+		reason     string
+		namespaced bool
+		req        admissionv1.AdmissionRequest
+		want       bool
+	}{
+		"CreateValid": {
+			reason: "Valid benches should be admitted.",
+			req:    admissionv1.AdmissionRequest{Operation: admissionv1.Create, Object: raw(t, valid)},
+			want:   true,
+		},
+		"CreateInvalid": {
+			reason: "Invalid benches should be rejected.",
+			req:    admissionv1.AdmissionRequest{Operation: admissionv1.Create, Object: raw(t, invalid)},
+			want:   false,
+		},
+		"CreateDefaulted": {
+			reason: "Fields provided by the bench defaults of the ProviderConfig should not be required.",
+			req:    admissionv1.AdmissionRequest{Operation: admissionv1.Create, Object: raw(t, defaulted)},
+			want:   true,
+		},
+		"CreateClusterRef": {
+			reason: "Bootstrap servers should not be required when they are resolved from a Kafka cluster.",
+			req:    admissionv1.AdmissionRequest{Operation: admissionv1.Create, Object: raw(t, resolved)},
+			want:   true,
+		},
+		"CreateTargetRef": {
+			reason: "Fields provided by the KafkaTarget of the bench should not be required.",
+			req:    admissionv1.AdmissionRequest{Operation: admissionv1.Create, Object: raw(t, targeted)},
+			want:   true,
+		},
+		"CreateTargetAndClusterRef": {
+			reason: "Benches should not reference both a KafkaTarget and a Kafka cluster.",
+			req:    admissionv1.AdmissionRequest{Operation: admissionv1.Create, Object: raw(t, ambiguous)},
+			want:   false,
+		},
+		"NamespacedLocal": {
+			reason:     "Namespaced benches referencing their own namespace should be admitted.",
+			namespaced: true,
+			req:        admissionv1.AdmissionRequest{Operation: admissionv1.Create, Namespace: "team-a", Object: raw(t, local)},
+			want:       true,
+		},
+		"NamespacedForeign": {
+			reason:     "Namespaced benches referencing Secrets of another namespace should be rejected.",
+			namespaced: true,
+			req:        admissionv1.AdmissionRequest{Operation: admissionv1.Create, Namespace: "team-a", Object: raw(t, foreign)},
+			want:       false,
+		},
+		"CreateWithinGuardrails": {
+			reason: "Benches within the guardrails of their ProviderConfig should be admitted.",
+			req:    admissionv1.AdmissionRequest{Operation: admissionv1.Create, Object: raw(t, guarded)},
+			want:   true,
+		},
+		"CreateExceedingGuardrails": {
+			reason: "Benches exceeding the guardrails of their ProviderConfig should be rejected.",
+			req:    admissionv1.AdmissionRequest{Operation: admissionv1.Create, Object: raw(t, flooding)},
+			want:   false,
+		},
+		"CreateLoadProfile": {
+			reason: "The rate and messages of benches with a load profile should be set by its segments.",
+			req:    admissionv1.AdmissionRequest{Operation: admissionv1.Create, Object: raw(t, ramped)},
+			want:   true,
+		},
+		"CreateLoadProfileExceedingGuardrails": {
+			reason: "Benches whose load profile peaks above their guardrails should be rejected.",
+			req:    admissionv1.AdmissionRequest{Operation: admissionv1.Create, Object: raw(t, overRamped)},
+			want:   false,
+		},
+		"CreateReplay": {
+			reason: "The rates of a replay should not be required until the bench is dispatched.",
+			req:    admissionv1.AdmissionRequest{Operation: admissionv1.Create, Object: raw(t, replayed)},
+			want:   true,
+		},
+		"CreateReplayWithoutNamespace": {
+			reason: "Cluster scoped benches should name the namespace of their replay ConfigMap.",
+			req:    admissionv1.AdmissionRequest{Operation: admissionv1.Create, Object: raw(t, unplaced)},
+			want:   false,
+		},
+		"CreateEmptyStartWindow": {
+			reason: "Benches whose start window ends when it starts should be rejected.",
+			req:    admissionv1.AdmissionRequest{Operation: admissionv1.Create, Object: raw(t, windowless)},
+			want:   false,
+		},
+		"CreateUnboundedWarmup": {
+			reason: "Benches whose warm-up lasts for neither a duration nor messages should be rejected.",
+			req:    admissionv1.AdmissionRequest{Operation: admissionv1.Create, Object: raw(t, unbounded)},
+			want:   false,
+		},
+		"CreateUnknownAssertionMetric": {
+			reason: "Benches asserting on metrics that are not reported should be rejected.",
+			req:    admissionv1.AdmissionRequest{Operation: admissionv1.Create, Object: raw(t, unknown)},
+			want:   false,
+		},
+		"CreateApprovedByAdmin": {
+			reason: "Benches exceeding their guardrails should be admitted when approved by a user allowed to approve them.",
+			req:    admissionv1.AdmissionRequest{Operation: admissionv1.Create, Object: raw(t, approved), UserInfo: authenticationv1.UserInfo{Username: "admin"}},
+			want:   true,
+		},
+		"CreateApprovedByUser": {
+			reason: "Benches approved by users not allowed to approve them should be rejected.",
+			req:    admissionv1.AdmissionRequest{Operation: admissionv1.Create, Object: raw(t, approved), UserInfo: authenticationv1.UserInfo{Username: "mallory"}},
+			want:   false,
+		},
+		"UpdateApproved": {
+			reason: "Updates of benches approved earlier should not need a new approval.",
+			req:    admissionv1.AdmissionRequest{Operation: admissionv1.Update, Object: raw(t, approved), OldObject: raw(t, approved), UserInfo: authenticationv1.UserInfo{Username: "mallory"}},
+			want:   true,
+		},
+		"UpdateRunning": {
+			reason: "Changes to running benches should be rejected.",
+			req:    admissionv1.AdmissionRequest{Operation: admissionv1.Update, Object: raw(t, changed), OldObject: raw(t, running)},
+			want:   false,
+		},
+		"UpdateNotStarted": {
+			reason: "Changes to benches that have not been dispatched should be admitted.",
+			req:    admissionv1.AdmissionRequest{Operation: admissionv1.Update, Object: raw(t, changed), OldObject: raw(t, valid)},
+			want:   true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v := &KafkaBenchValidator{kube: kube, decoder: d, namespaced: tc.namespaced}
+			got := v.Handle(context.Background(), admission.Request{AdmissionRequest: tc.req})
+			if diff := cmp.Diff(tc.want, got.Allowed); diff != "" {
+				t.Errorf("\n%s\nHandle(...): -want, +got:\n%s\n%v", tc.reason, diff, got.Result)
+			}
+		})
+	}
+}